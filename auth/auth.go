@@ -21,10 +21,10 @@ type AuthHeader struct {
 
 type AuthProvider struct {
 	env  *core.Environment
-	user *repository.User
+	user repository.UserRepository
 }
 
-func NewAuthProvider(env *core.Environment, user *repository.User) AuthProvider {
+func NewAuthProvider(env *core.Environment, user repository.UserRepository) AuthProvider {
 	return AuthProvider{
 		env:  env,
 		user: user,
@@ -59,8 +59,7 @@ func (a *AuthProvider) AuthRequired(c *gin.Context) {
 			return
 		}
 
-		c.Set(sessionVarUser, user)
-		c.Set(sessionVarIsAdministrator, user.AccessLevel == model.USER_ACCESS_LEVEL_ADMIN)
+		SetSessionUser(c, user)
 		c.Next()
 		return
 	default:
@@ -94,6 +93,12 @@ func GetUserFromSession(c *gin.Context) (model.User, error) {
 	return user.(model.User), nil
 }
 
+// SetSessionUser stores the authenticated user in the request context.
+func SetSessionUser(c *gin.Context, user model.User) {
+	c.Set(sessionVarUser, user)
+	c.Set(sessionVarIsAdministrator, user.AccessLevel == model.USER_ACCESS_LEVEL_ADMIN)
+}
+
 func IsAdministrator(c *gin.Context) bool {
 	return c.GetBool(sessionVarIsAdministrator)
 }
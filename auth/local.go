@@ -38,8 +38,7 @@ func (a *AuthProvider) localAuthRequired(c *gin.Context, tokenString string) {
 		return
 	}
 
-	c.Set(sessionVarUser, user)
-	c.Set(sessionVarIsAdministrator, user.AccessLevel == model.USER_ACCESS_LEVEL_ADMIN)
+	SetSessionUser(c, user)
 	c.Next()
 }
 
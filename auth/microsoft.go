@@ -47,8 +47,7 @@ func (a *AuthProvider) microsoftAuthRequired(c *gin.Context, tokenString string)
 		}
 	}
 
-	c.Set(sessionVarUser, user)
-	c.Set(sessionVarIsAdministrator, user.AccessLevel == model.USER_ACCESS_LEVEL_ADMIN)
+	SetSessionUser(c, user)
 	c.Next()
 }
 
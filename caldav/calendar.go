@@ -0,0 +1,302 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/ical"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/google/uuid"
+)
+
+const (
+	CALDAV_USERNAME_PLACEHOLDER = "{username}"
+	CALDAV_REQUEST_TIMEOUT      = 30 * time.Second
+)
+
+func GetCaldavUrl() string {
+	return os.Getenv("CALDAV_URL")
+}
+
+func GetCaldavUsername() string {
+	return os.Getenv("CALDAV_USERNAME")
+}
+
+func GetCaldavPassword() string {
+	return os.Getenv("CALDAV_PASSWORD")
+}
+
+func GetCaldavTimeZone() string {
+	timeZone := os.Getenv("CALDAV_TIME_ZONE")
+	if timeZone == "" {
+		return model.CALENDAR_ENTRY_DEFAULT_TIME_ZONE
+	}
+
+	return timeZone
+}
+
+func IsCaldavConnected() bool {
+	return GetCaldavUrl() != ""
+}
+
+// CalendarProvider manages the calendar entries in CalDAV calendars, e.g. of a
+// Nextcloud. CALDAV_URL is the calendar collection, {username} is replaced by
+// the username of the user. The entries are stored as <id>.ics.
+type CalendarProvider struct {
+	client *http.Client
+}
+
+func NewCalendarProvider() *CalendarProvider {
+	return &CalendarProvider{
+		client: &http.Client{Timeout: CALDAV_REQUEST_TIMEOUT},
+	}
+}
+
+func (p *CalendarProvider) Name() model.ExternalEventProvider {
+	return model.EXTERNAL_EVENT_PROVIDER_CALDAV
+}
+
+func (p *CalendarProvider) IsConnected() bool {
+	return IsCaldavConnected()
+}
+
+func (p *CalendarProvider) getCalendarUrl(username string) string {
+	calendarUrl := strings.ReplaceAll(GetCaldavUrl(), CALDAV_USERNAME_PLACEHOLDER, url.PathEscape(username))
+	if !strings.HasSuffix(calendarUrl, "/") {
+		calendarUrl += "/"
+	}
+
+	return calendarUrl
+}
+
+func (p *CalendarProvider) getEventUrl(username string, externalEventId string) string {
+	return p.getCalendarUrl(username) + url.PathEscape(externalEventId) + ".ics"
+}
+
+func (p *CalendarProvider) do(method string, requestUrl string, body string, headers map[string]string) (*http.Response, error) {
+	request, err := http.NewRequest(method, requestUrl, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if GetCaldavUsername() != "" {
+		request.SetBasicAuth(GetCaldavUsername(), GetCaldavPassword())
+	}
+
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	return p.client.Do(request)
+}
+
+func (p *CalendarProvider) putEntry(username string, externalEventId string, entry model.CalendarEntry, onlyCreate bool) (int, error) {
+	location, err := time.LoadLocation(GetCaldavTimeZone())
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := ical.EncodeEvent(externalEventId, entry, location)
+	if err != nil {
+		return 0, err
+	}
+
+	headers := map[string]string{
+		"Content-Type": "text/calendar; charset=utf-8",
+	}
+	if onlyCreate {
+		headers["If-None-Match"] = "*"
+	}
+
+	response, err := p.do(http.MethodPut, p.getEventUrl(username, externalEventId), data, headers)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	return response.StatusCode, nil
+}
+
+// CreateEntry uses the identifier of the entry as id, so a repeated creation
+// overwrites the entry instead of duplicating it.
+func (p *CalendarProvider) CreateEntry(username string, entry model.CalendarEntry) (string, error) {
+	externalEventId := entry.Identifier
+	if externalEventId == "" || externalEventId == uuid.Nil.String() {
+		externalEventId = uuid.New().String()
+	}
+
+	status, err := p.putEntry(username, externalEventId, entry, true)
+	if err != nil {
+		return "", err
+	}
+
+	if status == http.StatusPreconditionFailed {
+		status, err = p.putEntry(username, externalEventId, entry, false)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if status < 200 || status >= 300 {
+		return "", fmt.Errorf("error creating event: status %d", status)
+	}
+
+	return externalEventId, nil
+}
+
+func (p *CalendarProvider) GetEntry(username string, externalEventId string) (model.CalendarEntry, error) {
+	location, err := time.LoadLocation(GetCaldavTimeZone())
+	if err != nil {
+		return model.CalendarEntry{}, err
+	}
+
+	response, err := p.do(http.MethodGet, p.getEventUrl(username, externalEventId), "", nil)
+	if err != nil {
+		return model.CalendarEntry{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return model.CalendarEntry{}, model.ErrCalendarEntryNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return model.CalendarEntry{}, fmt.Errorf("error getting event: status %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return model.CalendarEntry{}, err
+	}
+
+	entry, err := ical.DecodeEvent(string(body), location)
+	if err != nil {
+		return model.CalendarEntry{}, err
+	}
+
+	entry.ID = externalEventId
+	return entry, nil
+}
+
+func (p *CalendarProvider) UpdateEntry(username string, externalEventId string, entry model.CalendarEntry) error {
+	status, err := p.putEntry(username, externalEventId, entry, false)
+	if err != nil {
+		return err
+	}
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("error updating event: status %d", status)
+	}
+
+	return nil
+}
+
+func (p *CalendarProvider) DeleteEntry(username string, externalEventId string) error {
+	response, err := p.do(http.MethodDelete, p.getEventUrl(username, externalEventId), "", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("error deleting event: status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+type multistatus struct {
+	Responses []multistatusResponse `xml:"response"`
+}
+
+type multistatusResponse struct {
+	Href         string `xml:"href"`
+	CalendarData string `xml:"propstat>prop>calendar-data"`
+}
+
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <c:calendar-data/>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+// FindEntries returns all events created by BeeTimeClock in the calendar of
+// the user between from and till.
+func (p *CalendarProvider) FindEntries(username string, from time.Time, till time.Time) ([]model.CalendarEntry, error) {
+	entries := []model.CalendarEntry{}
+
+	location, err := time.LoadLocation(GetCaldavTimeZone())
+	if err != nil {
+		return entries, err
+	}
+
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format(ical.ICAL_UTC_DATE_TIME_LAYOUT), till.UTC().Format(ical.ICAL_UTC_DATE_TIME_LAYOUT))
+	response, err := p.do("REPORT", p.getCalendarUrl(username), body, map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return entries, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusMultiStatus {
+		return entries, fmt.Errorf("error getting events: status %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return entries, err
+	}
+
+	var result multistatus
+	err = xml.NewDecoder(bytes.NewReader(data)).Decode(&result)
+	if err != nil {
+		return entries, err
+	}
+
+	for _, item := range result.Responses {
+		if item.CalendarData == "" {
+			continue
+		}
+
+		entry, err := ical.DecodeEvent(item.CalendarData, location)
+		if err != nil {
+			return entries, err
+		}
+
+		if !entry.IsCreatedByBeeTimeClock() {
+			continue
+		}
+
+		name, err := url.PathUnescape(path.Base(item.Href))
+		if err != nil {
+			return entries, err
+		}
+
+		entry.ID = strings.TrimSuffix(name, ".ics")
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
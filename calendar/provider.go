@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/caldav"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+// Provider manages the calendar entries of the users in an external calendar.
+// The ids returned by CreateEntry are stored in the external events.
+type Provider interface {
+	Name() model.ExternalEventProvider
+	IsConnected() bool
+	CreateEntry(username string, entry model.CalendarEntry) (string, error)
+	// GetEntry returns model.ErrCalendarEntryNotFound if the entry was removed.
+	GetEntry(username string, externalEventId string) (model.CalendarEntry, error)
+	// UpdateEntry returns model.ErrCalendarEntryNotFound if the entry was
+	// removed and can't be recreated.
+	UpdateEntry(username string, externalEventId string, entry model.CalendarEntry) error
+	// DeleteEntry ignores entries which don't exist anymore.
+	DeleteEntry(username string, externalEventId string) error
+	// FindEntries returns the entries between from and till which are created
+	// by BeeTimeClock, see model.CalendarEntry.IsCreatedByBeeTimeClock.
+	FindEntries(username string, from time.Time, till time.Time) ([]model.CalendarEntry, error)
+}
+
+func getAllProviders() []Provider {
+	return []Provider{
+		microsoft.NewCalendarProvider(),
+		caldav.NewCalendarProvider(),
+	}
+}
+
+// GetProviders returns the active providers. CALENDAR_PROVIDERS limits them to
+// a comma separated list, otherwise all connected providers are active.
+func GetProviders() []Provider {
+	configured := os.Getenv("CALENDAR_PROVIDERS")
+
+	providers := []Provider{}
+	for _, provider := range getAllProviders() {
+		if !provider.IsConnected() {
+			continue
+		}
+
+		if configured != "" && !isProviderConfigured(configured, provider.Name()) {
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+func isProviderConfigured(configured string, name model.ExternalEventProvider) bool {
+	for _, item := range strings.Split(configured, ",") {
+		if model.ExternalEventProvider(strings.TrimSpace(item)) == name {
+			return true
+		}
+	}
+
+	return false
+}
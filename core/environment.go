@@ -7,8 +7,9 @@ import (
 )
 
 type EnvironmentNotification struct {
-	Enabled    bool
-	WebhookUrl string
+	Enabled         bool
+	WebhookUrl      string
+	EventWebhookUrl string
 }
 
 type EnvironmentStorage struct {
@@ -22,20 +23,27 @@ func (es *EnvironmentStorage) HasS3() bool {
 	return es.Endpoint != ""
 }
 
+type EnvironmentHoliday struct {
+	State  string
+	Source string
+}
+
 type Environment struct {
 	DatabaseManager *database.DatabaseManager
 	UploadPath      string
 	Secret          []byte
 	Notification    EnvironmentNotification
 	Storage         EnvironmentStorage
+	Holiday         EnvironmentHoliday
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
 		UploadPath: "upload",
 		Notification: EnvironmentNotification{
-			Enabled:    os.Getenv("NOTIFY_WEBHOOK_URL") != "",
-			WebhookUrl: os.Getenv("NOTIFY_WEBHOOK_URL"),
+			Enabled:         os.Getenv("NOTIFY_WEBHOOK_URL") != "",
+			WebhookUrl:      os.Getenv("NOTIFY_WEBHOOK_URL"),
+			EventWebhookUrl: os.Getenv("EVENT_WEBHOOK_URL"),
 		},
 		Storage: EnvironmentStorage{
 			Endpoint:        os.Getenv("BUCKET_ADDRESS"),
@@ -43,5 +51,18 @@ func NewEnvironment() *Environment {
 			SecretAccessKey: os.Getenv("BUCKET_PASSWORD"),
 			BucketName:      os.Getenv("BUCKET_NAME"),
 		},
+		Holiday: EnvironmentHoliday{
+			State:  getEnvOrDefault("HOLIDAY_STATE", "NI"),
+			Source: getEnvOrDefault("HOLIDAY_SOURCE", "builtin"),
+		},
 	}
 }
+
+func getEnvOrDefault(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
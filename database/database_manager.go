@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
@@ -19,55 +21,31 @@ func NewDatabaseManager(prefix string) *DatabaseManager {
 	}
 }
 
+const (
+	DB_TYPE_POSTGRES = "psql"
+	DB_TYPE_MYSQL    = "mysql"
+	DB_TYPE_SQLITE   = "sqlite"
+
+	DEFAULT_SQLITE_DATABASE = "beetimeclock.db"
+)
+
 func (d *DatabaseManager) newConnection() (*gorm.DB, error) {
 	config := &gorm.Config{}
 
 	dbType := os.Getenv("DB_TYPE")
 	if dbType == "" {
-		dbType = "psql"
+		dbType = DB_TYPE_POSTGRES
 	}
 
 	var dialect gorm.Dialector
 
-	hasMissing := false
-
-	host := os.Getenv("DB_HOST")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	database := os.Getenv("DATABASE")
-	port := os.Getenv("DB_PORT")
-	
-	if host == "" {
-		fmt.Println("Missing DB_HOST")
-		hasMissing = true
-	}
-	
-	if user == "" {
-		fmt.Println("Missing DB_USER")
-		hasMissing = true
-	}
-	
-	if password  == "" {
-		fmt.Println("Missing DB_PASSWORD")
-		hasMissing = true
-	}
-
-	if database == "" {
-		fmt.Println("Missing DB_DATABASE")
-		hasMissing = true
-	}
-
-	if port == "" {
-		fmt.Println("Missing DB_PORT")
-		hasMissing = true
-	}
-
-	if hasMissing {
-		return nil, fmt.Errorf("missing database env vars")
-	}
-
 	switch dbType {
-	case "psql":
+	case DB_TYPE_POSTGRES, "postgres":
+		err := checkServerEnv()
+		if err != nil {
+			return nil, err
+		}
+
 		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable application_name=%s",
 			os.Getenv("DB_HOST"),
 			os.Getenv("DB_USER"),
@@ -78,7 +56,29 @@ func (d *DatabaseManager) newConnection() (*gorm.DB, error) {
 		)
 
 		dialect = postgres.Open(dsn)
-		break
+	case DB_TYPE_MYSQL, "mariadb":
+		err := checkServerEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			os.Getenv("DB_USER"),
+			os.Getenv("DB_PASSWORD"),
+			os.Getenv("DB_HOST"),
+			os.Getenv("DB_PORT"),
+			os.Getenv("DATABASE"),
+		)
+
+		dialect = mysql.Open(dsn)
+	case DB_TYPE_SQLITE:
+		// DATABASE is the path of the database file
+		database := os.Getenv("DATABASE")
+		if database == "" {
+			database = DEFAULT_SQLITE_DATABASE
+		}
+
+		dialect = sqlite.Open(fmt.Sprintf("%s?_busy_timeout=5000&_journal_mode=WAL", database))
 	default:
 		return nil, fmt.Errorf("database type %s not supported", dbType)
 	}
@@ -92,6 +92,24 @@ func (d *DatabaseManager) newConnection() (*gorm.DB, error) {
 	return conn, err
 }
 
+// checkServerEnv reports the missing connection settings of a database server.
+func checkServerEnv() error {
+	hasMissing := false
+
+	for _, name := range []string{"DB_HOST", "DB_USER", "DB_PASSWORD", "DATABASE", "DB_PORT"} {
+		if os.Getenv(name) == "" {
+			fmt.Printf("Missing %s\n", name)
+			hasMissing = true
+		}
+	}
+
+	if hasMissing {
+		return fmt.Errorf("missing database env vars")
+	}
+
+	return nil
+}
+
 func (d *DatabaseManager) GetConnection() (*gorm.DB, error) {
 	conn, err := d.newConnection()
 	if err != nil {
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// YearOf returns the sql expression for the year of the date column.
+func YearOf(db *gorm.DB, column string) string {
+	return datePart(db, column, "year", "%Y")
+}
+
+// MonthOf returns the sql expression for the month of the date column.
+func MonthOf(db *gorm.DB, column string) string {
+	return datePart(db, column, "month", "%m")
+}
+
+// HoursBetween returns the sql expression for the hours between two timestamp
+// columns.
+func HoursBetween(db *gorm.DB, from string, till string) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return fmt.Sprintf("TIMESTAMPDIFF(SECOND, %s, %s) / 3600", from, till)
+	case "sqlite":
+		return fmt.Sprintf("(julianday(%s) - julianday(%s)) * 24", till, from)
+	}
+
+	return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s)) / 3600", till, from)
+}
+
+func datePart(db *gorm.DB, column string, part string, sqliteFormat string) string {
+	if db.Dialector.Name() == "sqlite" {
+		return fmt.Sprintf("CAST(strftime('%s', %s) AS INTEGER)", sqliteFormat, column)
+	}
+
+	return fmt.Sprintf("EXTRACT(%s FROM %s)", part, column)
+}
@@ -11,28 +11,39 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
-	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Absence struct {
-	env     *core.Environment
-	user    *repository.User
-	team    *repository.Team
-	absence *repository.Absence
-	holiday *repository.Holiday
+	env            *core.Environment
+	user           repository.UserRepository
+	team           repository.TeamRepository
+	absence        repository.AbsenceRepository
+	holiday        repository.HolidayRepository
+	workTimeModel  repository.WorkTimeModelRepository
+	monthClosing   repository.MonthClosingRepository
+	settings       repository.SettingsRepository
+	vacationWorker *worker.Vacation
+	outbox         *worker.Outbox
 }
 
-func NewAbsence(env *core.Environment, user *repository.User, absence *repository.Absence, team *repository.Team, holiday *repository.Holiday) *Absence {
+func NewAbsence(env *core.Environment, user repository.UserRepository, absence repository.AbsenceRepository, team repository.TeamRepository, holiday repository.HolidayRepository, workTimeModel repository.WorkTimeModelRepository, monthClosing repository.MonthClosingRepository, settings repository.SettingsRepository, vacationWorker *worker.Vacation, outbox *worker.Outbox) *Absence {
 	return &Absence{
-		env:     env,
-		user:    user,
-		absence: absence,
-		team:    team,
-		holiday: holiday,
+		env:            env,
+		user:           user,
+		absence:        absence,
+		team:           team,
+		holiday:        holiday,
+		workTimeModel:  workTimeModel,
+		monthClosing:   monthClosing,
+		settings:       settings,
+		vacationWorker: vacationWorker,
+		outbox:         outbox,
 	}
 }
 
@@ -45,11 +56,13 @@ func (h *Absence) AdministrationAbsenceReasonCreate(c *gin.Context) {
 	}
 
 	absenceReason := model.AbsenceReason{
-		Description:    absenceReasonCreateRequest.Description,
-		OvertimeImpact: absenceReasonCreateRequest.OvertimeImpact,
-		ImpactHours:    absenceReasonCreateRequest.ImpactDays,
-		ImpactDays:     absenceReasonCreateRequest.ImpactHours,
-		NeedsApproval:  absenceReasonCreateRequest.NeedsApproval,
+		Description:     absenceReasonCreateRequest.Description,
+		OvertimeImpact:  absenceReasonCreateRequest.OvertimeImpact,
+		ImpactHours:     absenceReasonCreateRequest.ImpactHours,
+		ImpactDays:      absenceReasonCreateRequest.ImpactDays,
+		NeedsApproval:   absenceReasonCreateRequest.NeedsApproval,
+		DeductsVacation: absenceReasonCreateRequest.DeductsVacation,
+		ApprovalSteps:   absenceReasonCreateRequest.GetApprovalSteps(),
 	}
 
 	err = h.absence.InsertAbsenceReason(&absenceReason)
@@ -110,8 +123,17 @@ func (h *Absence) AdministrationAbsenceReasonUpdate(c *gin.Context) {
 	absenceReason.ImpactDays = absenceReasonUpdateRequest.ImpactDays
 	absenceReason.ImpactHours = absenceReasonUpdateRequest.ImpactHours
 	absenceReason.OvertimeImpact = absenceReasonUpdateRequest.OvertimeImpact
+	absenceReason.DeductsVacation = absenceReasonUpdateRequest.DeductsVacation
 
-	err = h.absence.UpdateAbsenceReason(&absenceReason)
+	absenceReason.ApprovalSteps = absenceReasonUpdateRequest.GetApprovalSteps()
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		err := h.absence.UpdateAbsenceReason(tx, &absenceReason)
+		if err != nil {
+			return err
+		}
+
+		return h.absence.ReplaceAbsenceReasonApprovalSteps(tx, &absenceReason, absenceReason.ApprovalSteps)
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -190,54 +212,108 @@ func (h *Absence) absenceCreate(c *gin.Context, user *model.User, absenceCreateR
 		return nil, false
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, absenceFrom, absenceTill) {
+		return nil, false
+	}
+
 	absence := model.Absence{
 		UserID:        &user.ID,
 		AbsenceFrom:   absenceFrom,
 		AbsenceTill:   absenceTill,
 		AbsenceReason: absenceReason,
 		Identifier:    uuid.New(),
+		DayPart:       absenceCreateRequest.GetDayPart(),
 	}
 
-	if absenceReason.NeedsApproval == nil || *absenceReason.NeedsApproval == false {
-		absence.Sign(user, model.SIGNED_STATUS_ACCEPTED, nil)
-	} else {
-		if signingUser != nil {
-			absence.Sign(signingUser, model.SIGNED_STATUS_ACCEPTED, nil)
+	if !absence.IsFullDay() && !absenceFrom.Equal(absenceTill) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("partial day absences have to be on a single day")))
+		return nil, false
+	}
+
+	if !absence.IsFullDay() {
+		settings, err := h.settings.SettingsFind()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return nil, false
 		}
+
+		hoursFrom, hoursTill, err := absenceCreateRequest.HoursParsed(absenceFrom, settings)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+			return nil, false
+		}
+
+		absence.HoursFrom = &hoursFrom
+		absence.HoursTill = &hoursTill
 	}
 
-	holidays, err := h.holiday.HolidayFindByDateRange(absenceFrom, absenceTill)
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(user.ID, absenceFrom, absenceTill)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return nil, false
 	}
 
-	absence.CalculateNettoDays(holidays)
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return nil, false
+	}
+
+	absence.CalculateNettoDays(holidays, workTimeModels)
+
+	step := absence.GetNextApprovalStep()
+	if step == nil {
+		absence.Sign(user, model.SIGNED_STATUS_ACCEPTED, nil)
+	} else if signingUser != nil && step.ApproverType == model.ABSENCE_APPROVER_TYPE_TEAM_LEAD {
+		// the creating team lead approves the first stage, further stages still have to sign
+		absence.Approve(step, signingUser, model.SIGNED_STATUS_ACCEPTED, nil)
+	}
 
-	err = h.absence.Insert(&absence)
+	existingAbsences, err := h.absence.FindByUserID(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return nil, false
 	}
 
-	if microsoft.IsMicrosoftConnected() {
-		eventId, err := microsoft.CreateCalendarEntryFromAbsence(user.Username, &absence)
+	var remainingVacationDays *float64
+	if absence.DeductsVacation() {
+		remaining, err := h.vacationWorker.GetRemainingForAbsence(*user, absence)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 			return nil, false
 		}
+		remainingVacationDays = &remaining
+	}
+
+	violations := model.ValidateAbsence(&absence, existingAbsences, remainingVacationDays)
+
+	teamViolations, err := h.checkTeamRules(&absence)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return nil, false
+	}
+	violations = append(violations, teamViolations...)
 
-		absenceExternalEvent := model.AbsenceExternalEvent{
-			Absence:               absence,
-			ExternalEventID:       eventId,
-			ExternalEventProvider: model.EXTERNAL_EVENT_PROVIDER_MICROSOFT,
+	if len(violations) > 0 {
+		if signingUser == nil || !absenceCreateRequest.OverrideValidation {
+			c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(&model.AbsenceValidationError{Violations: violations}))
+			return nil, false
 		}
 
-		err = h.absence.AbsenceExternalEventInsert(&absenceExternalEvent)
+		absence.OverrideValidation(signingUser, violations)
+	}
+
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		err := h.absence.Insert(tx, &absence)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-			return nil, false
+			return err
 		}
+
+		return h.enqueueAbsenceChanged(tx, &absence, model.OUTBOX_WEBHOOK_EVENT_ABSENCE_CREATED)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return nil, false
 	}
 
 	return &absence, true
@@ -275,25 +351,36 @@ func (h *Absence) AbsenceDelete(c *gin.Context) {
 		}
 	}
 
-	if len(absence.ExternalEvents) > 0 {
+	if !checkPeriodIsOpen(c, h.monthClosing, *absence.UserID, absence.AbsenceFrom, absence.AbsenceTill) {
+		return
+	}
+
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
 		for _, externalEvent := range absence.ExternalEvents {
-			if externalEvent.ExternalEventProvider == model.EXTERNAL_EVENT_PROVIDER_MICROSOFT {
-				err = microsoft.DeleteCalendarEntry(absence.User.Username, externalEvent.ExternalEventID)
+			if externalEvent.ExternalEventID != "" {
+				err := h.outbox.Enqueue(tx, model.OUTBOX_JOB_TYPE_CALENDAR_DELETE, model.OutboxCalendarDeletePayload{
+					Provider:        externalEvent.ExternalEventProvider,
+					Username:        absence.User.Username,
+					ExternalEventID: externalEvent.ExternalEventID,
+				})
 				if err != nil {
-					c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-					return
+					return err
 				}
 			}
-			fmt.Println("delete")
-			err = h.absence.AbsenceExternalEventDelete(&externalEvent)
+
+			err := h.absence.AbsenceExternalEventDelete(tx, &externalEvent)
 			if err != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-				return
+				return err
 			}
 		}
-	}
 
-	err = h.absence.Delete(&absence)
+		err := h.absence.Delete(tx, &absence)
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.EnqueueWebhook(tx, model.OUTBOX_WEBHOOK_EVENT_ABSENCE_DELETED, model.NewOutboxAbsenceEvent(&absence))
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -338,7 +425,7 @@ func (h *Absence) AbsenceQueryUserYears(c *gin.Context) {
 }
 
 func (h *Absence) AbsenceQueryUsersSummary(c *gin.Context) {
-	absences, err := h.absence.FindByQuery(true, "absence_till >= ?", time.Now().Format("2006-01-02"))
+	absences, err := h.absence.FindSince(helper.GetDayDate(time.Now()))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -407,7 +494,7 @@ func (h *Absence) AbsenceQueryTeamUsersSummary(c *gin.Context) {
 		teamMemberIds = append(teamMemberIds, member.UserID)
 	}
 
-	absences, err := h.absence.FindByQuery(true, "user_id in (?) and absence_till >= ? is not null", teamMemberIds, time.Now().Format("2006-01-02"))
+	absences, err := h.absence.FindByUserIdsSince(teamMemberIds, helper.GetDayDate(time.Now()))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -459,7 +546,7 @@ func (h *Absence) AbsenceTeamOpen(c *gin.Context) {
 
 	teamAbsences := []model.Absence{}
 	if len(absenceReasonIds) > 0 {
-		teamAbsences, err = h.absence.FindByQuery(true, "user_id in ? and absence_reason_id in ? and signed_user_id is null", userIds, absenceReasonIds)
+		teamAbsences, err = h.absence.FindUnsignedByUserIdsAndReasonIds(userIds, absenceReasonIds)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 			return
@@ -469,6 +556,11 @@ func (h *Absence) AbsenceTeamOpen(c *gin.Context) {
 	groupedByUser := make(map[uint][]model.Absence)
 
 	for _, teamAbsence := range teamAbsences {
+		step := teamAbsence.GetNextApprovalStep()
+		if step == nil || step.ApproverType != model.ABSENCE_APPROVER_TYPE_TEAM_LEAD || teamAbsence.HasApproved(user.ID) {
+			continue
+		}
+
 		userId := *teamAbsence.UserID
 		if _, exists := groupedByUser[userId]; !exists {
 			groupedByUser[userId] = []model.Absence{}
@@ -480,15 +572,68 @@ func (h *Absence) AbsenceTeamOpen(c *gin.Context) {
 	result := []model.AbsenceReturn{}
 
 	for _, absences := range groupedByUser {
-		result = append(result, model.AbsenceReturns(absences, absences[0].User, true, true, false)...)
+		absenceReturns := model.AbsenceReturns(absences, absences[0].User, true, true, false)
+		for i := range absenceReturns {
+			absenceReturns[i].Approvals = absences[i].Approvals
+			absenceReturns[i].Conflicts, err = h.checkTeamRules(&absences[i])
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+				return
+			}
+		}
+
+		result = append(result, absenceReturns...)
 	}
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
 }
 
+// checkTeamRules checks the absence against the rules of all teams of the user.
+func (h *Absence) checkTeamRules(absence *model.Absence) ([]model.AbsenceValidationViolation, error) {
+	violations := []model.AbsenceValidationViolation{}
+	if !absence.IsSubjectToTeamRules() {
+		return violations, nil
+	}
+
+	teams, err := h.team.TeamsFindByUserId(*absence.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(*absence.UserID, absence.AbsenceFrom, absence.AbsenceTill)
+	if err != nil {
+		return nil, err
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(*absence.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if team.MinimumPresentMembers == 0 && len(team.BlackoutPeriods) == 0 {
+			continue
+		}
+
+		memberIds := []uint{}
+		for _, member := range team.Members {
+			memberIds = append(memberIds, member.UserID)
+		}
+
+		teamAbsences, err := h.absence.FindByUserIdsOverlapping(memberIds, absence.AbsenceFrom, absence.AbsenceTill)
+		if err != nil {
+			return nil, err
+		}
+
+		violations = append(violations, model.CheckTeamRules(&team, absence, teamAbsences, holidays, workTimeModels)...)
+	}
+
+	return violations, nil
+}
+
 func (h *Absence) AbsenceQueryUsersSummaryCurrentYear(c *gin.Context) {
-	absences, err := h.absence.FindByQuery(true, "absence_till >= ? and absence_till <= ?",
-		fmt.Sprintf("%d-01-01", time.Now().Year()), fmt.Sprintf("%d-12-31", time.Now().Year()))
+	absences, err := h.absence.FindByAbsenceTillBetween(time.Date(time.Now().Year(), time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(time.Now().Year(), time.December, 31, 0, 0, 0, 0, time.UTC))
 
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -506,8 +651,7 @@ func (h *Absence) AbsenceQueryUsersSummaryCurrentWeek(c *gin.Context) {
 	weekStart := helper.WeekStart(year, week)
 	weekEnd := weekStart.AddDate(0, 0, 5)
 
-	absences, err := h.absence.FindByQuery(true, "absence_till >= ? and absence_till <= ?",
-		weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	absences, err := h.absence.FindByAbsenceTillBetween(weekStart, weekEnd)
 
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -545,7 +689,11 @@ func (h *Absence) AbsenceQueryUserSummaryYear(c *gin.Context) {
 		return
 	}
 
-	summary := h.summaryAbsences(&user, absences)
+	summary, err := h.summaryAbsences(&user, absences)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(summary))
 }
@@ -563,17 +711,24 @@ func (h *Absence) AbsenceQueryCurrentUserSummary(c *gin.Context) {
 		return
 	}
 
-	summary := h.summaryAbsences(&user, absences)
+	summary, err := h.summaryAbsences(&user, absences)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(summary))
 }
 
-func (h *Absence) summaryAbsences(user *model.User, absences []model.Absence) model.AbsenceUserSummary {
+func (h *Absence) summaryAbsences(user *model.User, absences []model.Absence) (model.AbsenceUserSummary, error) {
 	result := model.AbsenceUserSummary{
 		ByYear:             make(map[int]model.AbsenceUserSummaryYear),
 		HolidayDaysPerYear: user.HolidayDaysPerYear,
 	}
 
+	currentYear := time.Now().Year()
+	tillYear := currentYear
+
 	for _, absence := range absences {
 		absenceYear := absence.AbsenceFrom.Year()
 		if _, exists := result.ByYear[absenceYear]; !exists {
@@ -596,9 +751,31 @@ func (h *Absence) summaryAbsences(user *model.User, absences []model.Absence) mo
 		}
 
 		result.ByYear[absenceYear].ByAbsenceReason[*absence.AbsenceReasonID] = yearReasonSummary
+
+		if absenceYear > tillYear {
+			tillYear = absenceYear
+		}
 	}
 
-	return result
+	ledger, err := h.vacationWorker.CalculateLedger(*user, tillYear)
+	if err != nil {
+		return result, err
+	}
+
+	for year, yearSummary := range result.ByYear {
+		ledgerYear := ledger.GetYear(year)
+		if ledgerYear != nil {
+			yearSummary.RemainingVacationDays = ledgerYear.Remaining
+			result.ByYear[year] = yearSummary
+		}
+	}
+
+	currentLedgerYear := ledger.GetYear(currentYear)
+	if currentLedgerYear != nil {
+		result.RemainingVacationDays = currentLedgerYear.Remaining
+	}
+
+	return result, nil
 }
 
 func (h *Absence) AbsenceRecalculate(c *gin.Context) {
@@ -608,28 +785,55 @@ func (h *Absence) AbsenceRecalculate(c *gin.Context) {
 		return
 	}
 
-	safedHoliays := make(map[int]model.Holidays)
-	getHolidays := func(year int) (model.Holidays, error) {
-		if value, exists := safedHoliays[year]; exists {
+	type holidayKey struct {
+		userId uint
+		year   int
+	}
+	safedHoliays := make(map[holidayKey]model.Holidays)
+	getHolidays := func(userId uint, year int) (model.Holidays, error) {
+		key := holidayKey{userId: userId, year: year}
+		if value, exists := safedHoliays[key]; exists {
 			return value, nil
 		}
 
-		holidays, err := h.holiday.HolidayFindByYear(year)
+		holidays, err := h.holiday.HolidayFindByUserIdAndYear(userId, year)
+		if err == nil {
+			safedHoliays[key] = holidays
+		}
 		return holidays, err
 	}
 
+	safedWorkTimeModels := make(map[uint]model.UserWorkTimeModels)
+	getWorkTimeModels := func(userId uint) (model.UserWorkTimeModels, error) {
+		if value, exists := safedWorkTimeModels[userId]; exists {
+			return value, nil
+		}
+
+		workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(userId)
+		if err == nil {
+			safedWorkTimeModels[userId] = workTimeModels
+		}
+		return workTimeModels, err
+	}
+
 	for _, absence := range absences {
 		currentNetto := absence.NettoDays
 
-		holidays, err := getHolidays(absence.AbsenceFrom.Year())
+		holidays, err := getHolidays(*absence.UserID, absence.AbsenceFrom.Year())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		workTimeModels, err := getWorkTimeModels(*absence.UserID)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 			return
 		}
-		absence.CalculateNettoDays(holidays)
+		absence.CalculateNettoDays(holidays, workTimeModels)
 
 		if absence.NettoDays != currentNetto {
-			err = h.absence.Update(&absence)
+			err = h.absence.Update(nil, &absence)
 			if err != nil {
 				c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 				return
@@ -678,13 +882,163 @@ func (h *Absence) AbsenceSign(c *gin.Context) {
 		return
 	}
 
-	absence.Sign(&executingUser, absenceSignRequest.Status, absenceSignRequest.Message)
+	if !h.absenceApprove(c, &absence, &executingUser, &absenceSignRequest) {
+		return
+	}
 
-	err = h.absence.Update(&absence)
+	c.JSON(http.StatusOK, model.NewSuccessResponse(absence))
+}
+
+func (h *Absence) AbsenceApprovalOpen(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	absences, err := h.absence.FindUnsigned()
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
+	// stages of team leads are signed in the open view of the team
+	openAbsences := []model.Absence{}
+	for _, absence := range absences {
+		step := absence.GetNextApprovalStep()
+		if step == nil || step.ApproverType == model.ABSENCE_APPROVER_TYPE_TEAM_LEAD || absence.HasApproved(user.ID) {
+			continue
+		}
+
+		teams, err := h.team.TeamsFindByUserId(*absence.UserID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		if step.IsApprover(&user, teams, *absence.UserID) {
+			openAbsences = append(openAbsences, absence)
+		}
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.AbsenceReturns(openAbsences, nil, true, true, true)))
+}
+
+func (h *Absence) AbsenceApprovalSign(c *gin.Context) {
+	var absenceSignRequest model.AbsenceSignRequest
+	err := c.BindJSON(&absenceSignRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	absenceId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	absence, err := h.absence.FindByID(uint(absenceId))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if !h.absenceApprove(c, &absence, &executingUser, &absenceSignRequest) {
+		return
+	}
+
 	c.JSON(http.StatusOK, model.NewSuccessResponse(absence))
 }
+
+// absenceApprove signs the current stage of the approval chain of the absence.
+func (h *Absence) absenceApprove(c *gin.Context, absence *model.Absence, executingUser *model.User, absenceSignRequest *model.AbsenceSignRequest) bool {
+	if !absence.IsApprovalPending() {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("absence is already signed")))
+		return false
+	}
+
+	if !checkPeriodIsOpen(c, h.monthClosing, *absence.UserID, absence.AbsenceFrom, absence.AbsenceTill) {
+		return false
+	}
+
+	step := absence.GetNextApprovalStep()
+	if step == nil {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("absence needs no approval")))
+		return false
+	}
+
+	teams, err := h.team.TeamsFindByUserId(*absence.UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return false
+	}
+
+	if !step.IsApprover(executingUser, teams, *absence.UserID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("you're not approver of the current approval stage")))
+		return false
+	}
+
+	if absence.HasApproved(executingUser.ID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("you already signed a previous approval stage")))
+		return false
+	}
+
+	if absenceSignRequest.Status == model.SIGNED_STATUS_ACCEPTED {
+		violations, err := h.checkTeamRules(absence)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return false
+		}
+
+		if len(violations) > 0 {
+			if !absenceSignRequest.OverrideValidation {
+				c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(&model.AbsenceValidationError{Violations: violations}))
+				return false
+			}
+
+			absence.OverrideValidation(executingUser, violations)
+		}
+	}
+
+	approval := absence.Approve(step, executingUser, absenceSignRequest.Status, absenceSignRequest.Message)
+
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		err := h.absence.AbsenceApprovalInsert(tx, approval)
+		if err != nil {
+			return err
+		}
+
+		err = h.absence.Update(tx, absence)
+		if err != nil {
+			return err
+		}
+
+		return h.enqueueAbsenceChanged(tx, absence, model.OUTBOX_WEBHOOK_EVENT_ABSENCE_SIGNED)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// enqueueAbsenceChanged synchronises the calendar events of the absence and
+// publishes the change, tx is the transaction which stores the change.
+func (h *Absence) enqueueAbsenceChanged(tx *gorm.DB, absence *model.Absence, event string) error {
+	err := h.outbox.Enqueue(tx, model.OUTBOX_JOB_TYPE_CALENDAR_ABSENCE, model.OutboxCalendarAbsencePayload{
+		AbsenceID: absence.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.outbox.EnqueueWebhook(tx, event, model.NewOutboxAbsenceEvent(absence))
+}
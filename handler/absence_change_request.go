@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Absence) AbsenceChangeRequestGetAll(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	changeRequests, err := h.absence.AbsenceChangeRequestFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(changeRequests))
+}
+
+func (h *Absence) AbsenceChangeRequestCreate(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	var changeRequestCreateRequest model.AbsenceChangeRequestCreateRequest
+	err = c.BindJSON(&changeRequestCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	absenceId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	absence, err := h.absence.FindByID(uint(absenceId))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if *absence.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("not your own absence, can't change")))
+		return
+	}
+
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, absence.AbsenceFrom, absence.AbsenceTill) {
+		return
+	}
+
+	pendingChangeRequests, err := h.absence.AbsenceChangeRequestFindPendingByAbsenceId(absence.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if len(pendingChangeRequests) > 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("absence has already a pending change request")))
+		return
+	}
+
+	changeRequest, err := model.NewAbsenceChangeRequest(&absence, &changeRequestCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	err = h.absence.AbsenceChangeRequestInsert(&changeRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(changeRequest))
+}
+
+func (h *Absence) AbsenceChangeRequestTeamOpen(c *gin.Context) {
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	isLead := slices.ContainsFunc(team.Members, func(member model.TeamMember) bool {
+		return member.UserID == executingUser.ID && (member.Level == model.TeamLevel_Lead || member.Level == model.TeamLevel_LeadSurrogate)
+	})
+
+	if !isLead {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("you're not lead of the team")))
+		return
+	}
+
+	userIds := []uint{}
+	for _, member := range team.Members {
+		userIds = append(userIds, member.UserID)
+	}
+
+	changeRequests, err := h.absence.AbsenceChangeRequestFindPendingByUserIds(userIds)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	result := []model.AbsenceChangeRequestOpenResponse{}
+	for _, changeRequest := range changeRequests {
+		result = append(result, model.AbsenceChangeRequestOpenResponse{
+			AbsenceChangeRequest: changeRequest,
+			User:                 changeRequest.User.GetUserResponse(),
+		})
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+func (h *Absence) AbsenceChangeRequestSign(c *gin.Context) {
+	var signRequest model.AbsenceChangeRequestSignRequest
+	err := c.BindJSON(&signRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	changeRequestId, err := strconv.Atoi(c.Param("absenceChangeRequestID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	changeRequest, err := h.absence.AbsenceChangeRequestFindById(uint(changeRequestId))
+	if err != nil {
+		if errors.Is(err, repository.ErrAbsenceChangeRequestNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	isLead, err := checkUserIsUserTeamlead(c, &team, &executingUser, changeRequest.User)
+	if !isLead {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(err))
+		return
+	}
+
+	if !changeRequest.IsPending() {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("change request is already signed")))
+		return
+	}
+
+	var absence *model.Absence
+	if signRequest.Status == model.ABSENCE_CHANGE_REQUEST_STATUS_APPROVED {
+		item, err := h.absence.FindByID(changeRequest.AbsenceID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		if !checkPeriodIsOpen(c, h.monthClosing, *item.UserID, item.AbsenceFrom, item.AbsenceTill) {
+			return
+		}
+
+		if !changeRequest.IsCancellation() {
+			err = h.absenceModify(&item, &changeRequest)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+				return
+			}
+		}
+		absence = &item
+	}
+
+	changeRequest.Sign(&executingUser, signRequest.Status, signRequest.Message)
+
+	// the absence and the change request are stored together, a failed
+	// request leaves both unchanged
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		if absence != nil {
+			var err error
+			if changeRequest.IsCancellation() {
+				err = h.absenceCancel(tx, absence)
+			} else {
+				err = h.absenceUpdate(tx, absence)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return h.absence.AbsenceChangeRequestUpdate(tx, &changeRequest)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(changeRequest))
+}
+
+// absenceCancel soft deletes the absence, so it doesn't count anymore but stays
+// in the history. The calendar sync removes its events.
+func (h *Absence) absenceCancel(tx *gorm.DB, absence *model.Absence) error {
+	err := h.absence.Delete(tx, absence)
+	if err != nil {
+		return err
+	}
+
+	return h.enqueueAbsenceChanged(tx, absence, model.OUTBOX_WEBHOOK_EVENT_ABSENCE_DELETED)
+}
+
+// absenceModify applies the change request to the absence and recalculates
+// its netto days, absenceUpdate stores it.
+func (h *Absence) absenceModify(absence *model.Absence, changeRequest *model.AbsenceChangeRequest) error {
+	changeRequest.Apply(absence)
+
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(*absence.UserID, absence.AbsenceFrom, absence.AbsenceTill)
+	if err != nil {
+		return err
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(*absence.UserID)
+	if err != nil {
+		return err
+	}
+
+	absence.CalculateNettoDays(holidays, workTimeModels)
+	return nil
+}
+
+func (h *Absence) absenceUpdate(tx *gorm.DB, absence *model.Absence) error {
+	err := h.absence.Update(tx, absence)
+	if err != nil {
+		return err
+	}
+
+	return h.enqueueAbsenceChanged(tx, absence, model.OUTBOX_WEBHOOK_EVENT_ABSENCE_CHANGED)
+}
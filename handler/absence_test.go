@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+)
+
+func TestAbsenceApprovalSign(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	absenceRepo := memory.NewAbsence(db)
+	teamRepo := memory.NewTeam(db)
+	outboxWorker := worker.NewOutbox(env, memory.NewOutbox(db), nil)
+	handler := NewAbsence(env, userRepo, absenceRepo, teamRepo, memory.NewHoliday(db), memory.NewWorkTimeModel(db), memory.NewMonthClosing(db), memory.NewSettings(db), nil, outboxWorker)
+
+	employee := model.User{Username: "employee", AccessLevel: model.USER_ACCESS_LEVEL_USER}
+	lead := model.User{Username: "lead", AccessLevel: model.USER_ACCESS_LEVEL_USER}
+	administrator := model.User{Username: "administrator", AccessLevel: model.USER_ACCESS_LEVEL_ADMIN}
+	other := model.User{Username: "other", AccessLevel: model.USER_ACCESS_LEVEL_USER}
+	for _, user := range []*model.User{&employee, &lead, &administrator, &other} {
+		err := userRepo.Insert(user)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	needsApproval := true
+	nettoDays := 3.0
+	reason := model.AbsenceReason{
+		Description:   "Urlaub",
+		NeedsApproval: &needsApproval,
+		ApprovalSteps: []model.AbsenceApprovalStep{
+			{Position: 1, ApproverType: model.ABSENCE_APPROVER_TYPE_TEAM_LEAD},
+			{Position: 2, ApproverType: model.ABSENCE_APPROVER_TYPE_ADMINISTRATOR},
+		},
+	}
+	team := model.Team{
+		Members: []model.TeamMember{
+			{UserID: employee.ID, Level: model.TeamLevel_Member},
+			{UserID: lead.ID, Level: model.TeamLevel_Lead},
+		},
+	}
+	err := absenceRepo.InsertAbsenceReason(&reason)
+	if err == nil {
+		err = teamRepo.TeamInsert(&team)
+	}
+	absence := model.Absence{
+		UserID:          &employee.ID,
+		AbsenceReasonID: &reason.ID,
+		AbsenceFrom:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		AbsenceTill:     time.Date(2024, 7, 3, 0, 0, 0, 0, time.UTC),
+		NettoDays:       &nettoDays,
+	}
+	if err == nil {
+		err = absenceRepo.Insert(nil, &absence)
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name          string
+		User          model.User
+		Status        model.AbsenceSignedStatus
+		WantCode      int
+		WantApprovals int
+		WantSigned    bool
+	}{
+		{Name: "not approver", User: other, Status: model.SIGNED_STATUS_ACCEPTED, WantCode: http.StatusForbidden},
+		{Name: "team lead", User: lead, Status: model.SIGNED_STATUS_ACCEPTED, WantCode: http.StatusOK, WantApprovals: 1},
+		{Name: "team lead again", User: lead, Status: model.SIGNED_STATUS_ACCEPTED, WantCode: http.StatusForbidden, WantApprovals: 1},
+		{Name: "administrator", User: administrator, Status: model.SIGNED_STATUS_ACCEPTED, WantCode: http.StatusOK, WantApprovals: 2, WantSigned: true},
+		{Name: "already signed", User: administrator, Status: model.SIGNED_STATUS_DECLINED, WantCode: http.StatusConflict, WantApprovals: 2, WantSigned: true},
+	}
+
+	for _, test := range testData {
+		response := testRequest(handler.AbsenceApprovalSign, test.User, http.MethodPost, "/absence/:id/approval",
+			fmt.Sprintf("/absence/%d/approval", absence.ID), model.AbsenceSignRequest{Status: test.Status})
+		if response.Code != test.WantCode {
+			t.Errorf("%s: want status %d, got %d (%s)", test.Name, test.WantCode, response.Code, response.Body.String())
+		}
+
+		stored, err := absenceRepo.FindByID(absence.ID)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+		if len(stored.Approvals) != test.WantApprovals {
+			t.Errorf("%s: want %d approvals, got %d", test.Name, test.WantApprovals, len(stored.Approvals))
+		}
+		if stored.IsApprovalPending() == test.WantSigned {
+			t.Errorf("%s: want signed %t, got %v", test.Name, test.WantSigned, stored.SignedStatus)
+		}
+	}
+}
+
+func TestAbsenceCreateClosedMonth(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	absenceRepo := memory.NewAbsence(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+	outboxWorker := worker.NewOutbox(env, memory.NewOutbox(db), nil)
+	handler := NewAbsence(env, userRepo, absenceRepo, memory.NewTeam(db), memory.NewHoliday(db), memory.NewWorkTimeModel(db), monthClosingRepo, memory.NewSettings(db), nil, outboxWorker)
+
+	employee := model.User{Username: "employee", AccessLevel: model.USER_ACCESS_LEVEL_USER}
+	reason := model.AbsenceReason{Description: "Krankheit"}
+	err := userRepo.Insert(&employee)
+	if err == nil {
+		err = absenceRepo.InsertAbsenceReason(&reason)
+	}
+	if err == nil {
+		err = monthClosingRepo.MonthClosingInsert(&model.MonthClosing{UserID: &employee.ID, Year: 2024, Month: 3})
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name     string
+		From     string
+		Till     string
+		WantCode int
+	}{
+		{Name: "closed month", From: "2024-03-11", Till: "2024-03-12", WantCode: http.StatusLocked},
+		{Name: "ends in closed month", From: "2024-02-28", Till: "2024-03-01", WantCode: http.StatusLocked},
+		{Name: "starts in closed month", From: "2024-03-29", Till: "2024-04-02", WantCode: http.StatusLocked},
+		{Name: "open month", From: "2024-04-08", Till: "2024-04-09", WantCode: http.StatusCreated},
+	}
+
+	for _, test := range testData {
+		response := testRequest(handler.AbsenceCreate, employee, http.MethodPost, "/absence", "/absence", model.AbsenceCreateRequest{
+			AbsenceFrom:     test.From,
+			AbsenceTill:     test.Till,
+			AbsenceReasonID: reason.ID,
+		})
+		if response.Code != test.WantCode {
+			t.Errorf("%s: want status %d, got %d (%s)", test.Name, test.WantCode, response.Code, response.Body.String())
+		}
+	}
+
+	absences, err := absenceRepo.FindByUserID(employee.ID)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if len(absences) != 1 {
+		t.Errorf("want only the absence of the open month, got %d absences", len(absences))
+	}
+}
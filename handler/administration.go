@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,17 +16,21 @@ import (
 
 type Administration struct {
 	env      *core.Environment
-	settings *repository.Settings
-	absence  *repository.Absence
-	holiday  *repository.Holiday
+	settings repository.SettingsRepository
+	absence  repository.AbsenceRepository
+	holiday  repository.HolidayRepository
+	location repository.LocationRepository
+	outbox   *worker.Outbox
 }
 
-func NewAdministration(env *core.Environment, settings *repository.Settings, absence *repository.Absence, holiday *repository.Holiday) *Administration {
+func NewAdministration(env *core.Environment, settings repository.SettingsRepository, absence repository.AbsenceRepository, holiday repository.HolidayRepository, location repository.LocationRepository, outbox *worker.Outbox) *Administration {
 	return &Administration{
 		env:      env,
 		settings: settings,
 		absence:  absence,
 		holiday:  holiday,
+		location: location,
+		outbox:   outbox,
 	}
 }
 
@@ -57,8 +62,121 @@ func (h Administration) AdministrationUpdateSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, model.NewSuccessResponse(settings))
 }
 
+func (h Administration) AdministrationGetBreakRules(c *gin.Context) {
+	breakRules, err := h.settings.SettingsBreakRuleFindAll()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(breakRules))
+}
+
+func (h Administration) AdministrationCreateBreakRule(c *gin.Context) {
+	var breakRuleCreateRequest model.SettingsBreakRuleCreateRequest
+	err := c.BindJSON(&breakRuleCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	settings, err := h.settings.SettingsFind()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	breakRule := model.SettingsBreakRule{
+		SettingsID:          settings.ID,
+		TeamID:              breakRuleCreateRequest.TeamID,
+		ThresholdHours:      breakRuleCreateRequest.ThresholdHours,
+		DeductionMinutes:    breakRuleCreateRequest.DeductionMinutes,
+		MinimumBreakMinutes: model.DEFAULT_MINIMUM_BREAK_MINUTES,
+	}
+	if breakRuleCreateRequest.MinimumBreakMinutes != nil {
+		breakRule.MinimumBreakMinutes = *breakRuleCreateRequest.MinimumBreakMinutes
+	}
+
+	err = h.settings.SettingsBreakRuleInsert(&breakRule)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(breakRule))
+}
+
+func (h Administration) AdministrationUpdateBreakRule(c *gin.Context) {
+	var breakRuleUpdateRequest model.SettingsBreakRuleCreateRequest
+	err := c.BindJSON(&breakRuleUpdateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	breakRule, success := h.getBreakRuleFromParam(c)
+	if !success {
+		return
+	}
+
+	breakRule.TeamID = breakRuleUpdateRequest.TeamID
+	breakRule.ThresholdHours = breakRuleUpdateRequest.ThresholdHours
+	breakRule.DeductionMinutes = breakRuleUpdateRequest.DeductionMinutes
+	if breakRuleUpdateRequest.MinimumBreakMinutes != nil {
+		breakRule.MinimumBreakMinutes = *breakRuleUpdateRequest.MinimumBreakMinutes
+	}
+
+	err = h.settings.SettingsBreakRuleUpdate(&breakRule)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(breakRule))
+}
+
+func (h Administration) AdministrationDeleteBreakRule(c *gin.Context) {
+	breakRule, success := h.getBreakRuleFromParam(c)
+	if !success {
+		return
+	}
+
+	err := h.settings.SettingsBreakRuleDelete(&breakRule)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h Administration) getBreakRuleFromParam(c *gin.Context) (model.SettingsBreakRule, bool) {
+	breakRuleId, err := strconv.Atoi(c.Param("breakRuleID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return model.SettingsBreakRule{}, false
+	}
+
+	breakRule, err := h.settings.SettingsBreakRuleFindById(uint(breakRuleId))
+	if err != nil {
+		if err == repository.ErrSettingsBreakRuleNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return model.SettingsBreakRule{}, false
+	}
+
+	return breakRule, true
+}
+
 func (h Administration) AdministrationNotifyAbsenceWeek(c *gin.Context) {
-	worker.NotifyAbsenceWeek(h.env, h.absence)
+	err := worker.NotifyAbsenceWeek(h.env, h.absence, h.outbox)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -105,6 +223,8 @@ func (h Administration) GetLogo(c *gin.Context) {
 	c.DataFromReader(http.StatusOK, stat.Size, "application/pdf", file, extraHeaders)
 }
 
+// AdministrationGetHolidaysYear returns the holidays of the location given by
+// the location query parameter, of the default location without it.
 func (h Administration) AdministrationGetHolidaysYear(c *gin.Context) {
 	year, err := strconv.ParseInt(c.Param("year"), 10, 64)
 	if err != nil {
@@ -112,7 +232,28 @@ func (h Administration) AdministrationGetHolidaysYear(c *gin.Context) {
 		return
 	}
 
-	days, err := h.holiday.HolidayFindByYear(int(year))
+	var location model.Location
+	if locationParam := c.Query("location"); locationParam != "" {
+		locationId, err := strconv.ParseUint(locationParam, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+			return
+		}
+
+		location, err = h.location.LocationFindById(uint(locationId))
+	} else {
+		location, err = h.location.LocationFindDefault()
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrLocationNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	days, err := h.holiday.HolidayFindByLocationIdAndYear(location.ID, int(year))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -140,12 +281,20 @@ func (h Administration) AdministrationCreateHolidaysCustom(c *gin.Context) {
 		return
 	}
 
+	// the percentage of the working day which is off, 0 is a full day
+	if customHolidayCreateRequest.EmployeeDaySubstraction < 0 || customHolidayCreateRequest.EmployeeDaySubstraction > 100 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("employee day substraction has to be between 0 and 100")))
+		return
+	}
+
 	customHoliday := model.HolidayCustom{
-		Name:   customHolidayCreateRequest.Name,
-		Date:   customHolidayCreateRequest.Date,
-		Month:  customHolidayCreateRequest.Month,
-		Day:    customHolidayCreateRequest.Day,
-		Yearly: customHolidayCreateRequest.Yearly,
+		Name:                    customHolidayCreateRequest.Name,
+		Date:                    customHolidayCreateRequest.Date,
+		Month:                   customHolidayCreateRequest.Month,
+		Day:                     customHolidayCreateRequest.Day,
+		Yearly:                  customHolidayCreateRequest.Yearly,
+		LocationID:              customHolidayCreateRequest.LocationID,
+		EmployeeDaySubstraction: customHolidayCreateRequest.EmployeeDaySubstraction,
 	}
 
 	err = h.holiday.HolidayCustomInsert(&customHoliday)
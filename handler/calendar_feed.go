@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/ical"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CALENDAR_FEED_PAST_DAYS     = 365
+	CALENDAR_FEED_HOLIDAY_YEARS = 2
+)
+
+type CalendarFeed struct {
+	env          *core.Environment
+	user         repository.UserRepository
+	team         repository.TeamRepository
+	absence      repository.AbsenceRepository
+	externalWork repository.ExternalWorkRepository
+	holiday      repository.HolidayRepository
+}
+
+func NewCalendarFeed(env *core.Environment, user repository.UserRepository, team repository.TeamRepository, absence repository.AbsenceRepository, externalWork repository.ExternalWorkRepository, holiday repository.HolidayRepository) *CalendarFeed {
+	return &CalendarFeed{
+		env:          env,
+		user:         user,
+		team:         team,
+		absence:      absence,
+		externalWork: externalWork,
+		holiday:      holiday,
+	}
+}
+
+func (h *CalendarFeed) CurrentUserCalendarFeedGetAll(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	feeds, err := h.user.UserCalendarFeedFindAllByUserID(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	result := []model.UserCalendarFeedResponse{}
+	for _, feed := range feeds {
+		result = append(result, feed.GetUserCalendarFeedResponse())
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+func (h *CalendarFeed) CurrentUserCalendarFeedCreate(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	var createRequest model.UserCalendarFeedCreateRequest
+	err = c.BindJSON(&createRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	feed := model.UserCalendarFeed{
+		UserID:      user.ID,
+		Description: createRequest.Description,
+		Type:        createRequest.Type,
+		Token:       helper.RandomString(64),
+		ValidTill:   createRequest.ValidTill,
+	}
+
+	if createRequest.Type == model.CALENDAR_FEED_TYPE_TEAM {
+		isMember, err := h.isTeamMember(*createRequest.TeamID, user.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		if !isMember {
+			c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("you're not member of the team")))
+			return
+		}
+
+		feed.TeamID = createRequest.TeamID
+	}
+
+	err = h.user.UserCalendarFeedInsert(&feed)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(feed))
+}
+
+func (h *CalendarFeed) CurrentUserCalendarFeedDelete(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	feedId, err := strconv.Atoi(c.Param("calendarFeedID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	feed, err := h.user.UserCalendarFeedFindById(uint(feedId))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserCalendarFeedNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if feed.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("not your own calendar feed, can't delete")))
+		return
+	}
+
+	err = h.user.UserCalendarFeedDelete(&feed)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CalendarFeedGet renders the feed of the token, it is called by calendar
+// clients without authentication.
+func (h *CalendarFeed) CalendarFeedGet(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.user.UserCalendarFeedFindByToken(token)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserCalendarFeedNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if !feed.IsValid(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(repository.ErrUserCalendarFeedNotFound))
+		return
+	}
+
+	var name string
+	var entries []model.CalendarEntry
+	if feed.Type == model.CALENDAR_FEED_TYPE_TEAM {
+		name, entries, err = h.getTeamFeedEntries(&feed)
+	} else {
+		name, entries, err = h.getPersonalFeedEntries(&feed)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrUserCalendarFeedNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	location, err := time.LoadLocation(model.CALENDAR_ENTRY_DEFAULT_TIME_ZONE)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	data, err := ical.EncodeCalendar(name, entries, location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(data))
+}
+
+func (h *CalendarFeed) getPersonalFeedEntries(feed *model.UserCalendarFeed) (string, []model.CalendarEntry, error) {
+	now := time.Now()
+	since := helper.GetDayDate(now).AddDate(0, 0, -CALENDAR_FEED_PAST_DAYS)
+
+	absences, err := h.absence.FindByUserIdsSince([]uint{feed.UserID}, since)
+	if err != nil {
+		return "", nil, err
+	}
+
+	externalWorks, err := h.externalWork.ExternalWorkFindByUserIDAndEndBetween(feed.UserID, since, now.AddDate(CALENDAR_FEED_HOLIDAY_YEARS, 0, 0))
+	if err != nil {
+		return "", nil, err
+	}
+
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(feed.UserID, since, now.AddDate(CALENDAR_FEED_HOLIDAY_YEARS, 0, 0))
+	if err != nil {
+		return "", nil, err
+	}
+
+	entries := model.GetAbsenceFeedEntries(absences, &feed.User, true, false)
+	entries = append(entries, model.GetExternalWorkFeedEntries(externalWorks)...)
+	entries = append(entries, model.GetHolidayFeedEntries(holidays)...)
+
+	return fmt.Sprintf("BeeTimeClock: %s", feed.User.FullName()), entries, nil
+}
+
+// getTeamFeedEntries shows the real reasons only to administrators, like the
+// absence summary of the team.
+func (h *CalendarFeed) getTeamFeedEntries(feed *model.UserCalendarFeed) (string, []model.CalendarEntry, error) {
+	team, err := h.team.TeamFindById(*feed.TeamID, true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	isMember := slices.ContainsFunc(team.Members, func(member model.TeamMember) bool {
+		return member.UserID == feed.UserID
+	})
+	if !isMember {
+		return "", nil, repository.ErrUserCalendarFeedNotFound
+	}
+
+	userIds := []uint{}
+	for _, member := range team.Members {
+		userIds = append(userIds, member.UserID)
+	}
+
+	since := helper.GetDayDate(time.Now()).AddDate(0, 0, -CALENDAR_FEED_PAST_DAYS)
+	absences, err := h.absence.FindByUserIdsSince(userIds, since)
+	if err != nil {
+		return "", nil, err
+	}
+
+	showRealReason := feed.User.AccessLevel == model.USER_ACCESS_LEVEL_ADMIN
+	entries := model.GetAbsenceFeedEntries(absences, nil, showRealReason, true)
+
+	return fmt.Sprintf("BeeTimeClock: %s", team.Teamname), entries, nil
+}
+
+func (h *CalendarFeed) isTeamMember(teamId uint, userId uint) (bool, error) {
+	teams, err := h.team.TeamsFindByUserId(userId)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(teams, func(team model.Team) bool {
+		return team.ID == teamId
+	}), nil
+}
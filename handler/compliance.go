@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type Compliance struct {
+	env              *core.Environment
+	user             repository.UserRepository
+	team             repository.TeamRepository
+	complianceWorker *worker.Compliance
+}
+
+func NewCompliance(env *core.Environment, user repository.UserRepository, team repository.TeamRepository, complianceWorker *worker.Compliance) *Compliance {
+	return &Compliance{
+		env:              env,
+		user:             user,
+		team:             team,
+		complianceWorker: complianceWorker,
+	}
+}
+
+func (h *Compliance) TeamComplianceQueryMonth(c *gin.Context) {
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	year, month, success := getYearMonthFromParam(c)
+	if !success {
+		return
+	}
+
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	isLead := slices.ContainsFunc(team.Members, func(member model.TeamMember) bool {
+		return member.UserID == user.ID && (member.Level == model.TeamLevel_Lead || member.Level == model.TeamLevel_LeadSurrogate)
+	})
+
+	if !isLead && !auth.IsAdministrator(c) {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("you're not lead of the team")))
+		return
+	}
+
+	result := []model.ComplianceUserViolations{}
+	for _, member := range team.Members {
+		violations, err := h.complianceWorker.CheckMonth(member.UserID, year, month)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		if len(violations) == 0 {
+			continue
+		}
+
+		memberUser, err := h.user.FindByID(member.UserID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+
+		result = append(result, model.ComplianceUserViolations{
+			User:       memberUser.GetUserResponse(),
+			Violations: violations,
+		})
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
@@ -30,7 +31,7 @@ func checkUserIsUserTeamlead(c *gin.Context, team *model.Team, executingUser *mo
 	return true, nil
 }
 
-func getTimestampFromParam(c *gin.Context, timestampRepo *repository.Timestamp, userId *uint) (model.Timestamp, bool) {
+func getTimestampFromParam(c *gin.Context, timestampRepo repository.TimestampRepository, userId *uint) (model.Timestamp, bool) {
 	timestampIdParam := c.Param("timestampID")
 	timestampId, err := strconv.Atoi(timestampIdParam)
 	if err != nil {
@@ -54,7 +55,7 @@ func getTimestampFromParam(c *gin.Context, timestampRepo *repository.Timestamp,
 	return timestamp, true
 }
 
-func getUserFromParam(c *gin.Context, userRepo *repository.User) (model.User, bool) {
+func getUserFromParam(c *gin.Context, userRepo repository.UserRepository) (model.User, bool) {
 	userIdParam := c.Param("userID")
 	userId, err := strconv.Atoi(userIdParam)
 	if err != nil {
@@ -71,7 +72,7 @@ func getUserFromParam(c *gin.Context, userRepo *repository.User) (model.User, bo
 	return user, true
 }
 
-func getTeamFromParam(c *gin.Context, teamRepo *repository.Team) (model.Team, bool) {
+func getTeamFromParam(c *gin.Context, teamRepo repository.TeamRepository) (model.Team, bool) {
 	teamIdParam := c.Param("teamID")
 	teamId, err := strconv.Atoi(teamIdParam)
 	if err != nil {
@@ -115,6 +116,43 @@ func getYearMonthFromParam(c *gin.Context) (int, int, bool) {
 	return year, month, true
 }
 
+// checkPeriodIsOpen aborts the request if one of the months between the
+// earliest and the latest given date is closed for the user.
+func checkPeriodIsOpen(c *gin.Context, monthClosingRepo repository.MonthClosingRepository, userId uint, dates ...time.Time) bool {
+	if len(dates) == 0 {
+		return true
+	}
+
+	from := dates[0]
+	till := dates[0]
+	for _, date := range dates {
+		if date.IsZero() {
+			continue
+		}
+
+		if from.IsZero() || date.Before(from) {
+			from = date
+		}
+
+		if date.After(till) {
+			till = date
+		}
+	}
+
+	closed, err := monthClosingRepo.IsPeriodClosed(userId, from, till)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return false
+	}
+
+	if closed {
+		c.AbortWithStatusJSON(http.StatusLocked, model.NewErrorResponse(repository.ErrMonthClosed))
+		return false
+	}
+
+	return true
+}
+
 func getClientIPByHeaders(c *gin.Context) (ip string, err error) {
 	headers := []string{
 		"X-Forwarded-For",
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/gin-gonic/gin"
+)
+
+// testRequest calls the handler registered for route as the given user.
+func testRequest(handler gin.HandlerFunc, user model.User, method string, route string, path string, body any) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		auth.SetSessionUser(c, user)
+	}, handler)
+
+	payload, _ := json.Marshal(body)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewReader(payload)))
+	return recorder
+}
@@ -16,27 +16,34 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
-	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/text/encoding/charmap"
+	"gorm.io/gorm"
 )
 
 type ExternalWork struct {
-	env          *core.Environment
-	user         *repository.User
-	externalWork *repository.ExternalWork
-	holiday      *repository.Holiday
+	env           *core.Environment
+	user          repository.UserRepository
+	externalWork  repository.ExternalWorkRepository
+	holiday       repository.HolidayRepository
+	workTimeModel repository.WorkTimeModelRepository
+	monthClosing  repository.MonthClosingRepository
+	outbox        *worker.Outbox
 }
 
-func NewExternalWork(env *core.Environment, user *repository.User, externalWork *repository.ExternalWork, holiday *repository.Holiday) *ExternalWork {
+func NewExternalWork(env *core.Environment, user repository.UserRepository, externalWork repository.ExternalWorkRepository, holiday repository.HolidayRepository, workTimeModel repository.WorkTimeModelRepository, monthClosing repository.MonthClosingRepository, outbox *worker.Outbox) *ExternalWork {
 	return &ExternalWork{
-		env:          env,
-		user:         user,
-		externalWork: externalWork,
-		holiday:      holiday,
+		env:           env,
+		user:          user,
+		externalWork:  externalWork,
+		holiday:       holiday,
+		workTimeModel: workTimeModel,
+		monthClosing:  monthClosing,
+		outbox:        outbox,
 	}
 }
 
@@ -123,15 +130,27 @@ func (h *ExternalWork) ExternalWorkDelete(c *gin.Context) {
 		return
 	}
 
-	for _, item := range externalWorkItem.WorkExpanses {
-		err = h.externalWork.ExternalWorkExpenseDelete(&item)
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, externalWorkItem.From, externalWorkItem.Till) {
+		return
+	}
+
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		for _, item := range externalWorkItem.WorkExpanses {
+			err := h.externalWork.ExternalWorkExpenseDelete(tx, &item)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := h.externalWork.ExternalWorkDelete(tx, &externalWorkItem)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-			return
+			return err
 		}
-	}
 
-	err = h.externalWork.ExternalWorkDelete(&externalWorkItem)
+		return h.outbox.Enqueue(tx, model.OUTBOX_JOB_TYPE_CALENDAR_EXTERNAL_WORK, model.OutboxCalendarExternalWorkPayload{
+			ExternalWorkID: externalWorkItem.ID,
+		})
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -185,13 +204,19 @@ func (h *ExternalWork) ExternalWorkGetById(c *gin.Context) {
 		currentDay = currentDay.AddDate(0, 0, 1)
 	}
 
-	holidays, err := h.holiday.HolidayFindByDateRange(fromDay, tillDay)
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(user.ID, fromDay, tillDay)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(externalWorkItem.Calculate(holidays)))
+	c.JSON(http.StatusOK, model.NewSuccessResponse(externalWorkItem.Calculate(holidays, workTimeModels)))
 }
 
 func (h *ExternalWork) ExternalWorkCreate(c *gin.Context) {
@@ -208,6 +233,10 @@ func (h *ExternalWork) ExternalWorkCreate(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, externalWorkCreateRequest.From.Time, externalWorkCreateRequest.Till.Time) {
+		return
+	}
+
 	externalWork := model.ExternalWork{
 		User:                       user,
 		ExternalWorkCompensationID: externalWorkCreateRequest.ExternalWorkCompensationID,
@@ -217,30 +246,19 @@ func (h *ExternalWork) ExternalWorkCreate(c *gin.Context) {
 		Identifier:                 uuid.New(),
 	}
 
-	err = h.externalWork.ExternalWorkInsert(&externalWork)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-		return
-	}
-
-	if microsoft.IsMicrosoftConnected() {
-		eventId, err := microsoft.CreateCalendarEntryFromExternalWork(user.Username, &externalWork)
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		err := h.externalWork.ExternalWorkInsert(tx, &externalWork)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-			return
+			return err
 		}
 
-		externalWorkExternalEvent := model.ExternalWorkExternalEvent{
-			ExternalWork:          externalWork,
-			ExternalEventID:       eventId,
-			ExternalEventProvider: model.EXTERNAL_EVENT_PROVIDER_MICROSOFT,
-		}
-
-		err = h.externalWork.ExternalWorkExternalEventInsert(&externalWorkExternalEvent)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-			return
-		}
+		return h.outbox.Enqueue(tx, model.OUTBOX_JOB_TYPE_CALENDAR_EXTERNAL_WORK, model.OutboxCalendarExternalWorkPayload{
+			ExternalWorkID: externalWork.ID,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
 	}
 
 	c.JSON(http.StatusCreated, model.NewSuccessResponse(externalWork))
@@ -283,6 +301,10 @@ func (h *ExternalWork) ExternalWorkExpanseCreate(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, externalWorkItem.From, externalWorkItem.Till) {
+		return
+	}
+
 	externalWorkExpense := model.ExternalWorkExpense{
 		ExternalWork:           externalWorkItem,
 		Date:                   externalWorkExpenseCreateRequest.Date,
@@ -350,6 +372,10 @@ func (h *ExternalWork) ExternalWorkExpanseUpdate(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, externalWorkItem.From, externalWorkItem.Till) {
+		return
+	}
+
 	externalWorkExpenseItem, err := h.externalWork.ExternalWorkExpenseFindById(uint(expanseId))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -400,6 +426,10 @@ func (h *ExternalWork) ExternalWorkSubmit(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, externalWorkItem.From, externalWorkItem.Till) {
+		return
+	}
+
 	externalWorkItem.Status = model.EXTERNAL_WORK_STATUS_ACCEPTED
 
 	err = h.externalWork.ExternalWorkUpdate(&externalWorkItem)
@@ -561,7 +591,13 @@ func (h *ExternalWork) ExternalWorkExportPdf(c *gin.Context) {
 	fromDay := helper.GetDayDate(minStartDate)
 	tillDay := helper.GetDayDate(maxEndDate)
 
-	holidays, err := h.holiday.HolidayFindByDateRange(fromDay, tillDay)
+	holidays, err := h.holiday.HolidayFindByUserIdAndDateRange(user.ID, fromDay, tillDay)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -640,7 +676,7 @@ func (h *ExternalWork) ExternalWorkExportPdf(c *gin.Context) {
 		totalExpensesWithoutSocialInsurance := 0.0
 		optionsSums := make(map[string]float64)
 		for _, externalWorkItem := range externalWorkItems {
-			calculated := externalWorkItem.Calculate(holidays)
+			calculated := externalWorkItem.Calculate(holidays, workTimeModels)
 			for _, e := range calculated.WorkExpansesCalculated {
 				rowData := []string{
 					e.Date.Format("02.01.2006"),
@@ -16,11 +16,11 @@ import (
 
 type Fuel struct {
 	env  *core.Environment
-	user *repository.User
-	fuel *repository.Fuel
+	user repository.UserRepository
+	fuel repository.FuelRepository
 }
 
-func NewFuel(env *core.Environment, user *repository.User, fuel *repository.Fuel) *Fuel {
+func NewFuel(env *core.Environment, user repository.UserRepository, fuel repository.FuelRepository) *Fuel {
 	return &Fuel{
 		env:  env,
 		user: user,
@@ -1,28 +1,39 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
 	"github.com/gin-gonic/gin"
 )
 
 type Holiday struct {
-	env     *core.Environment
-	holiday *repository.Holiday
+	env           *core.Environment
+	holiday       repository.HolidayRepository
+	holidayWorker *worker.Holiday
 }
 
-func NewHoliday(env *core.Environment, holiday *repository.Holiday) *Holiday {
+func NewHoliday(env *core.Environment, holiday repository.HolidayRepository, holidayWorker *worker.Holiday) *Holiday {
 	return &Holiday{
-		env:     env,
-		holiday: holiday,
+		env:           env,
+		holiday:       holiday,
+		holidayWorker: holidayWorker,
 	}
 }
 
 func (h *Holiday) HolidayYearGet(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
 	year, err := strconv.ParseInt(c.Param("year"), 10, 64)
 
 	if err != nil {
@@ -30,7 +41,7 @@ func (h *Holiday) HolidayYearGet(c *gin.Context) {
 		return
 	}
 
-	holidays, err := h.holiday.HolidayFindByYear(int(year))
+	holidays, err := h.holiday.HolidayFindByUserIdAndYear(user.ID, int(year))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -38,3 +49,27 @@ func (h *Holiday) HolidayYearGet(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(holidays))
 }
+
+// AdministrationHolidaySync imports or re-syncs the holidays of all locations
+// for the year range and recalculates the affected absences and overtime.
+func (h *Holiday) AdministrationHolidaySync(c *gin.Context) {
+	var holidaySyncRequest model.HolidaySyncRequest
+	err := c.BindJSON(&holidaySyncRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	if holidaySyncRequest.TillYear-holidaySyncRequest.FromYear >= model.HOLIDAY_SYNC_MAX_YEARS {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("at most %d years can be synced at once", model.HOLIDAY_SYNC_MAX_YEARS)))
+		return
+	}
+
+	result, err := h.holidayWorker.Sync(holidaySyncRequest.FromYear, holidaySyncRequest.TillYear)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type Location struct {
+	env           *core.Environment
+	user          repository.UserRepository
+	location      repository.LocationRepository
+	holiday       repository.HolidayRepository
+	holidayWorker *worker.Holiday
+}
+
+func NewLocation(env *core.Environment, user repository.UserRepository, location repository.LocationRepository, holiday repository.HolidayRepository, holidayWorker *worker.Holiday) *Location {
+	return &Location{
+		env:           env,
+		user:          user,
+		location:      location,
+		holiday:       holiday,
+		holidayWorker: holidayWorker,
+	}
+}
+
+func (h *Location) AdministrationLocationGetAll(c *gin.Context) {
+	locations, err := h.location.LocationFindAll()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(locations))
+}
+
+func (h *Location) AdministrationLocationCreate(c *gin.Context) {
+	var locationCreateRequest model.LocationCreateRequest
+	err := c.BindJSON(&locationCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	location := model.Location{}
+	err = locationCreateRequest.Apply(&location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	err = h.location.LocationInsert(&location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if locationCreateRequest.IsDefault {
+		err = h.location.LocationSetDefault(&location)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	go h.syncHolidays(location)
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(location))
+}
+
+func (h *Location) AdministrationLocationUpdate(c *gin.Context) {
+	var locationUpdateRequest model.LocationCreateRequest
+	err := c.BindJSON(&locationUpdateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	location, success := h.getLocationFromParam(c)
+	if !success {
+		return
+	}
+
+	if location.IsDefault && !locationUpdateRequest.IsDefault {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("mark another location as default instead")))
+		return
+	}
+
+	previousCountry := location.Country
+	previousState := location.State
+
+	err = locationUpdateRequest.Apply(&location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	err = h.location.LocationUpdate(&location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if locationUpdateRequest.IsDefault && !location.IsDefault {
+		err = h.location.LocationSetDefault(&location)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	// the sync replaces the public holidays of the old state
+	if previousCountry != location.Country || previousState != location.State {
+		go h.syncHolidays(location)
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(location))
+}
+
+func (h *Location) AdministrationLocationDelete(c *gin.Context) {
+	location, success := h.getLocationFromParam(c)
+	if !success {
+		return
+	}
+
+	if location.IsDefault {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("the default location can't be deleted")))
+		return
+	}
+
+	isAssigned, err := h.location.LocationIsAssigned(location.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if isAssigned {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("location is assigned to users")))
+		return
+	}
+
+	err = h.holiday.HolidayDeleteByLocationId(location.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	err = h.location.LocationDelete(&location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Location) AdministrationUserLocationGetAll(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	userLocations, err := h.location.UserLocationFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(userLocations))
+}
+
+func (h *Location) AdministrationUserLocationCreate(c *gin.Context) {
+	var userLocationCreateRequest model.UserLocationCreateRequest
+	err := c.BindJSON(&userLocationCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	validFrom := helper.GetDayDate(userLocationCreateRequest.ValidFrom)
+	var validTill *time.Time
+	if userLocationCreateRequest.ValidTill != nil {
+		day := helper.GetDayDate(*userLocationCreateRequest.ValidTill)
+		if day.Before(validFrom) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("valid till is before valid from")))
+			return
+		}
+		validTill = &day
+	}
+
+	location, err := h.location.LocationFindById(userLocationCreateRequest.LocationID)
+	if err != nil {
+		if err == repository.ErrLocationNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	existingLocations, err := h.location.UserLocationFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	// an open assignment that started before the new one ends the day before
+	for _, existing := range existingLocations {
+		if existing.ValidTill != nil || !existing.ValidFrom.Before(validFrom) {
+			continue
+		}
+
+		previousDay := validFrom.AddDate(0, 0, -1)
+		existing.ValidTill = &previousDay
+
+		err = h.location.UserLocationUpdate(&existing)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	userLocation := model.UserLocation{
+		UserID:     user.ID,
+		LocationID: location.ID,
+		Location:   location,
+		ValidFrom:  validFrom,
+		ValidTill:  validTill,
+	}
+
+	err = h.location.UserLocationInsert(&userLocation)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(userLocation))
+}
+
+func (h *Location) AdministrationUserLocationDelete(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	userLocationId, err := strconv.Atoi(c.Param("userLocationID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	userLocation, err := h.location.UserLocationFindById(uint(userLocationId))
+	if err != nil {
+		if err == repository.ErrUserLocationNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	if userLocation.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("location is not assigned to user")))
+		return
+	}
+
+	err = h.location.UserLocationDelete(&userLocation)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Location) CurrentUserLocationGetAll(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	userLocations, err := h.location.UserLocationFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(userLocations))
+}
+
+// syncHolidays syncs the holidays of the current and the next year, so the
+// location can be used right away.
+func (h *Location) syncHolidays(location model.Location) {
+	year := time.Now().Year()
+	_, err := h.holidayWorker.SyncLocation(location, year, year+1)
+	if err != nil {
+		log.Printf("Holiday Sync: location %s: %s", location.Name, err)
+	}
+}
+
+func (h *Location) getLocationFromParam(c *gin.Context) (model.Location, bool) {
+	locationId, err := strconv.Atoi(c.Param("locationID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return model.Location{}, false
+	}
+
+	location, err := h.location.LocationFindById(uint(locationId))
+	if err != nil {
+		if err == repository.ErrLocationNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return model.Location{}, false
+	}
+
+	return location, true
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/migrations"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
 	"github.com/gin-gonic/gin"
@@ -11,22 +12,53 @@ import (
 
 type Migration struct {
 	env       *core.Environment
-	migration *repository.Migration
+	migration repository.MigrationRepository
+	registry  *migrations.Registry
 }
 
-func NewMigration(env *core.Environment, migration *repository.Migration) *Migration {
+func NewMigration(env *core.Environment, migration repository.MigrationRepository, registry *migrations.Registry) *Migration {
 	return &Migration{
 		env:       env,
 		migration: migration,
+		registry:  registry,
 	}
 }
 
 func (h *Migration) AdministrationMigrationGetAll(c *gin.Context) {
-	migrations, err := h.migration.MigrationFindAll()
+	items, err := h.migration.MigrationFindAll()
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(migrations))
+	c.JSON(http.StatusOK, model.NewSuccessResponse(items))
+}
+
+func (h *Migration) AdministrationMigrationGetStatus(c *gin.Context) {
+	status, err := h.registry.GetStatus()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(status))
+}
+
+// AdministrationMigrationRun runs the pending migrations, with DryRun they are
+// rolled back and only the results are returned.
+func (h *Migration) AdministrationMigrationRun(c *gin.Context) {
+	var migrationRunRequest model.MigrationRunRequest
+	err := c.BindJSON(&migrationRunRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	results, err := h.registry.RunPending(migrationRunRequest.DryRun)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(results))
 }
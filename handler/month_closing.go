@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type MonthClosing struct {
+	env            *core.Environment
+	user           repository.UserRepository
+	team           repository.TeamRepository
+	monthClosing   repository.MonthClosingRepository
+	overtimeWorker *worker.Overtime
+}
+
+func NewMonthClosing(env *core.Environment, user repository.UserRepository, team repository.TeamRepository, monthClosing repository.MonthClosingRepository, overtimeWorker *worker.Overtime) *MonthClosing {
+	return &MonthClosing{
+		env:            env,
+		user:           user,
+		team:           team,
+		monthClosing:   monthClosing,
+		overtimeWorker: overtimeWorker,
+	}
+}
+
+func (h *MonthClosing) AdministrationMonthClosingGetAll(c *gin.Context) {
+	monthClosings, err := h.monthClosing.MonthClosingFindAll()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(monthClosings))
+}
+
+func (h *MonthClosing) AdministrationMonthClosingCreate(c *gin.Context) {
+	var monthClosingCreateRequest model.MonthClosingCreateRequest
+	err := c.BindJSON(&monthClosingCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	users := []model.User{}
+	if monthClosingCreateRequest.UserID != nil {
+		user, err := h.user.FindByID(*monthClosingCreateRequest.UserID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+		users = append(users, user)
+	} else {
+		users, err = h.user.FindAll()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	h.closeMonth(c, &executingUser, monthClosingCreateRequest.UserID, users, monthClosingCreateRequest.Year, monthClosingCreateRequest.Month)
+}
+
+func (h *MonthClosing) AdministrationMonthClosingReopen(c *gin.Context) {
+	monthClosing, success := h.getMonthClosingFromParam(c)
+	if !success {
+		return
+	}
+
+	h.reopenMonth(c, &monthClosing)
+}
+
+func (h *MonthClosing) TeamUserMonthClosingGetAll(c *gin.Context) {
+	user, success := h.checkTeamLead(c)
+	if !success {
+		return
+	}
+
+	monthClosings, err := h.monthClosing.MonthClosingFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(monthClosings))
+}
+
+func (h *MonthClosing) TeamUserMonthClosingCreate(c *gin.Context) {
+	user, success := h.checkTeamLead(c)
+	if !success {
+		return
+	}
+
+	year, month, success := getYearMonthFromParam(c)
+	if !success {
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	h.closeMonth(c, &executingUser, &user.ID, []model.User{user}, year, month)
+}
+
+func (h *MonthClosing) TeamUserMonthClosingReopen(c *gin.Context) {
+	user, success := h.checkTeamLead(c)
+	if !success {
+		return
+	}
+
+	monthClosing, success := h.getMonthClosingFromParam(c)
+	if !success {
+		return
+	}
+
+	if monthClosing.UserID == nil || *monthClosing.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(errors.New("month closing is not for this user")))
+		return
+	}
+
+	h.reopenMonth(c, &monthClosing)
+}
+
+func (h *MonthClosing) closeMonth(c *gin.Context, executingUser *model.User, userId *uint, users []model.User, year int, month int) {
+	activeClosings, err := h.monthClosing.MonthClosingFindActive(userId, year, month)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if len(activeClosings) > 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(repository.ErrMonthClosed))
+		return
+	}
+
+	// the quota is calculated a last time, afterwards it's frozen
+	for _, user := range users {
+		_, _, err = h.overtimeWorker.CalculateMonth(user.ID, year, month)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	monthClosing := model.MonthClosing{
+		UserID:         userId,
+		Year:           year,
+		Month:          month,
+		ClosedByUserID: executingUser.ID,
+	}
+
+	err = h.monthClosing.MonthClosingInsert(&monthClosing)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(monthClosing))
+}
+
+func (h *MonthClosing) reopenMonth(c *gin.Context, monthClosing *model.MonthClosing) {
+	var monthClosingReopenRequest model.MonthClosingReopenRequest
+	err := c.BindJSON(&monthClosingReopenRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	if !monthClosing.IsActive() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("month closing is already reopened")))
+		return
+	}
+
+	monthClosing.Reopen(&executingUser, monthClosingReopenRequest.Reason)
+
+	err = h.monthClosing.MonthClosingUpdate(monthClosing)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(monthClosing))
+}
+
+func (h *MonthClosing) checkTeamLead(c *gin.Context) (model.User, bool) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return model.User{}, false
+	}
+
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return model.User{}, false
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return model.User{}, false
+	}
+
+	_, err = checkUserIsUserTeamlead(c, &team, &executingUser, &user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(err))
+		return model.User{}, false
+	}
+
+	return user, true
+}
+
+func (h *MonthClosing) getMonthClosingFromParam(c *gin.Context) (model.MonthClosing, bool) {
+	monthClosingId, err := strconv.Atoi(c.Param("monthClosingID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return model.MonthClosing{}, false
+	}
+
+	monthClosing, err := h.monthClosing.MonthClosingFindById(uint(monthClosingId))
+	if err != nil {
+		if err == repository.ErrMonthClosingNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return model.MonthClosing{}, false
+	}
+
+	return monthClosing, true
+}
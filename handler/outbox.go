@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type Outbox struct {
+	env          *core.Environment
+	outbox       repository.OutboxRepository
+	outboxWorker *worker.Outbox
+}
+
+func NewOutbox(env *core.Environment, outbox repository.OutboxRepository, outboxWorker *worker.Outbox) *Outbox {
+	return &Outbox{
+		env:          env,
+		outbox:       outbox,
+		outboxWorker: outboxWorker,
+	}
+}
+
+func (h *Outbox) AdministrationOutboxJobGetAll(c *gin.Context) {
+	status := model.OutboxJobStatus(c.DefaultQuery("status", string(model.OUTBOX_JOB_STATUS_DEAD)))
+
+	jobs, err := h.outbox.OutboxJobFindByStatus(status)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(jobs))
+}
+
+func (h *Outbox) AdministrationOutboxJobReplay(c *gin.Context) {
+	jobId, err := strconv.Atoi(c.Param("outboxJobID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	job, err := h.outbox.OutboxJobFindById(uint(jobId))
+	if err != nil {
+		if errors.Is(err, repository.ErrOutboxJobNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if job.Status == model.OUTBOX_JOB_STATUS_DELIVERED {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("job is already delivered")))
+		return
+	}
+
+	err = h.outboxWorker.Replay(&job)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(job))
+}
@@ -14,13 +14,13 @@ import (
 
 type Overtime struct {
 	env            *core.Environment
-	overtime       *repository.Overtime
-	user           *repository.User
+	overtime       repository.OvertimeRepository
+	user           repository.UserRepository
 	overtimeWorker *worker.Overtime
-	team           *repository.Team
+	team           repository.TeamRepository
 }
 
-func NewOvertime(env *core.Environment, user *repository.User, overtime *repository.Overtime, overtimeWorker *worker.Overtime, team *repository.Team) *Overtime {
+func NewOvertime(env *core.Environment, user repository.UserRepository, overtime repository.OvertimeRepository, overtimeWorker *worker.Overtime, team repository.TeamRepository) *Overtime {
 	return &Overtime{
 		env:            env,
 		user:           user,
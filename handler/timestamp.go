@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,29 +14,40 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type Timestamp struct {
-	env             *core.Environment
-	user            *repository.User
-	team            *repository.Team
-	timestamp       *repository.Timestamp
-	absence         *repository.Absence
-	settings        *repository.Settings
-	holiday         *repository.Holiday
-	timestampWorker *worker.Timestamp
+	env              *core.Environment
+	user             repository.UserRepository
+	team             repository.TeamRepository
+	timestamp        repository.TimestampRepository
+	absence          repository.AbsenceRepository
+	settings         repository.SettingsRepository
+	holiday          repository.HolidayRepository
+	workTimeModel    repository.WorkTimeModelRepository
+	monthClosing     repository.MonthClosingRepository
+	location         repository.LocationRepository
+	timestampWorker  *worker.Timestamp
+	complianceWorker *worker.Compliance
+	outbox           *worker.Outbox
 }
 
-func NewTimestamp(env *core.Environment, user *repository.User, timestamp *repository.Timestamp, absence *repository.Absence, settings *repository.Settings, holiday *repository.Holiday, timestampWorker *worker.Timestamp, team *repository.Team) *Timestamp {
+func NewTimestamp(env *core.Environment, user repository.UserRepository, timestamp repository.TimestampRepository, absence repository.AbsenceRepository, settings repository.SettingsRepository, holiday repository.HolidayRepository, timestampWorker *worker.Timestamp, complianceWorker *worker.Compliance, team repository.TeamRepository, workTimeModel repository.WorkTimeModelRepository, monthClosing repository.MonthClosingRepository, location repository.LocationRepository, outbox *worker.Outbox) *Timestamp {
 	return &Timestamp{
-		env:             env,
-		user:            user,
-		timestamp:       timestamp,
-		absence:         absence,
-		settings:        settings,
-		holiday:         holiday,
-		timestampWorker: timestampWorker,
-		team:            team,
+		env:              env,
+		user:             user,
+		timestamp:        timestamp,
+		absence:          absence,
+		settings:         settings,
+		holiday:          holiday,
+		timestampWorker:  timestampWorker,
+		complianceWorker: complianceWorker,
+		team:             team,
+		workTimeModel:    workTimeModel,
+		monthClosing:     monthClosing,
+		location:         location,
+		outbox:           outbox,
 	}
 }
 
@@ -135,23 +147,28 @@ func (h *Timestamp) TimestampMissingEntriesMonth(c *gin.Context) {
 	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
 }
 
-func (h *Timestamp) TimestampQuerySuspicious(c *gin.Context) {
-	user, err := auth.GetUserFromSession(c)
+// getSuspiciousTimestamps returns the broken, uncorrected and too long
+// timestamps followed by the timestamps with compliance violations of the
+// recent months. Timestamps of today are still in progress and skipped.
+func (h *Timestamp) getSuspiciousTimestamps(userID uint) ([]model.TimestampSuspiciousResponse, error) {
+	maxDurationHours := 12
+
+	timestamps, err := h.timestamp.FindSuspiciousTimestampsByUserID(userID, int64(maxDurationHours))
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
-		return
+		return nil, err
 	}
 
-	maxDurationHours := 12
+	breakRules, err := h.timestampWorker.GetBreakRulesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
 
-	timestamps, err := h.timestamp.FindSuspiciousTimestampsByUserID(user.ID, int64(maxDurationHours))
+	violations, err := h.complianceWorker.FindSuspiciousTimestamps(userID, time.Now())
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-		return
+		return nil, err
 	}
 
 	result := []model.TimestampSuspiciousResponse{}
-
 	for _, timestamp := range timestamps {
 		if timestamp.IsToday() {
 			continue
@@ -159,38 +176,50 @@ func (h *Timestamp) TimestampQuerySuspicious(c *gin.Context) {
 
 		result = append(result, model.TimestampSuspiciousResponse{
 			Timestamp:        timestamp,
-			SuspiciousReason: timestamp.SuspiciousReason(float64(maxDurationHours)),
+			SuspiciousReason: timestamp.SuspiciousReason(float64(maxDurationHours), breakRules),
 		})
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+	for _, violation := range violations {
+		if violation.IsToday() || slices.ContainsFunc(result, func(item model.TimestampSuspiciousResponse) bool {
+			return item.ID == violation.ID
+		}) {
+			continue
+		}
+
+		result = append(result, violation)
+	}
+
+	return result, nil
 }
 
-func (h *Timestamp) TimestampQuerySuspiciousCount(c *gin.Context) {
+func (h *Timestamp) TimestampQuerySuspicious(c *gin.Context) {
 	user, err := auth.GetUserFromSession(c)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
 		return
 	}
 
-	timestamps, err := h.timestamp.FindSuspiciousTimestampsByUserID(user.ID, 12)
+	result, err := h.getSuspiciousTimestamps(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	maxDurationHours := 12
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
 
-	result := []model.TimestampSuspiciousResponse{}
-	for _, timestamp := range timestamps {
-		if timestamp.IsToday() {
-			continue
-		}
+func (h *Timestamp) TimestampQuerySuspiciousCount(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
 
-		result = append(result, model.TimestampSuspiciousResponse{
-			Timestamp:        timestamp,
-			SuspiciousReason: timestamp.SuspiciousReason(float64(maxDurationHours)),
-		})
+	result, err := h.getSuspiciousTimestamps(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
 	}
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(model.CountResult{
@@ -231,7 +260,12 @@ func (h *Timestamp) TimestampActionCheckIn(c *gin.Context) {
 		}
 	}
 
-	isHomeoffice, err := h.isHomeoffice(c, timestampActionCheckInRequest.IsHomeoffice)
+	now := time.Now()
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, now) {
+		return
+	}
+
+	isHomeoffice, err := h.isHomeoffice(c, user.ID, timestampActionCheckInRequest.IsHomeoffice)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -239,7 +273,7 @@ func (h *Timestamp) TimestampActionCheckIn(c *gin.Context) {
 
 	timestamp := model.Timestamp{
 		User:            &user,
-		ComingTimestamp: time.Now(),
+		ComingTimestamp: now,
 		IsHomeoffice:    isHomeoffice,
 	}
 
@@ -252,7 +286,7 @@ func (h *Timestamp) TimestampActionCheckIn(c *gin.Context) {
 	c.JSON(http.StatusCreated, model.NewSuccessResponse(timestamp))
 }
 
-func (h *Timestamp) isHomeoffice(c *gin.Context, prefered bool) (bool, error) {
+func (h *Timestamp) isHomeoffice(c *gin.Context, userId uint, prefered bool) (bool, error) {
 	settings, err := h.settings.SettingsFind()
 	if err != nil {
 		return false, err
@@ -264,6 +298,11 @@ func (h *Timestamp) isHomeoffice(c *gin.Context, prefered bool) (bool, error) {
 			return false, err
 		}
 
+		location, err := h.getUserLocation(userId, time.Now())
+		if err != nil {
+			return false, err
+		}
+
 		isOfficeIp := false
 
 		for _, input := range strings.Split(clientIps, ",") {
@@ -276,6 +315,10 @@ func (h *Timestamp) isHomeoffice(c *gin.Context, prefered bool) (bool, error) {
 				}
 			}
 
+			if location.IsOfficeIP(clientIp) {
+				isOfficeIp = true
+			}
+
 			if isOfficeIp {
 				break
 			}
@@ -287,6 +330,21 @@ func (h *Timestamp) isHomeoffice(c *gin.Context, prefered bool) (bool, error) {
 	}
 }
 
+// getUserLocation returns the location the user works at on the given day.
+func (h *Timestamp) getUserLocation(userId uint, date time.Time) (model.Location, error) {
+	userLocations, err := h.location.UserLocationFindByUserId(userId)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	location, exists := userLocations.GetLocationForDay(date)
+	if exists {
+		return location, nil
+	}
+
+	return h.location.LocationFindDefault()
+}
+
 func (h *Timestamp) TimestampActionCheckOut(c *gin.Context) {
 	user, err := auth.GetUserFromSession(c)
 	if err != nil {
@@ -312,16 +370,110 @@ func (h *Timestamp) TimestampActionCheckOut(c *gin.Context) {
 		return
 	}
 
-	isHomeoffice, err := h.isHomeoffice(c, timestampCheckoutActionRequest.IsHomeoffice)
+	now := time.Now()
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, lastTimestamp.ComingTimestamp, now) {
+		return
+	}
+
+	isHomeoffice, err := h.isHomeoffice(c, user.ID, timestampCheckoutActionRequest.IsHomeoffice)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	lastTimestamp.GoingTimestamp = time.Now()
+	openBreak := lastTimestamp.GetOpenBreak()
+	if openBreak != nil {
+		openBreak.BreakEnd = now
+		err = h.timestamp.TimestampBreakUpdate(openBreak)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	lastTimestamp.GoingTimestamp = now
 	lastTimestamp.IsHomeofficeGoing = isHomeoffice
 
-	err = h.timestamp.Update(&lastTimestamp)
+	err = h.timestamp.Update(nil, &lastTimestamp)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(lastTimestamp))
+}
+
+func (h *Timestamp) TimestampActionPauseStart(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	lastTimestamp, err := h.timestamp.FindLastByUserID(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if lastTimestamp.IsComplete() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("there is no open timestamp")))
+		return
+	}
+
+	if lastTimestamp.GetOpenBreak() != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("there is an open break")))
+		return
+	}
+
+	now := time.Now()
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, lastTimestamp.ComingTimestamp, now) {
+		return
+	}
+
+	timestampBreak := model.TimestampBreak{
+		TimestampID: lastTimestamp.ID,
+		BreakStart:  now,
+	}
+
+	err = h.timestamp.TimestampBreakInsert(&timestampBreak)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	lastTimestamp.Breaks = append(lastTimestamp.Breaks, timestampBreak)
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(lastTimestamp))
+}
+
+func (h *Timestamp) TimestampActionPauseEnd(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	lastTimestamp, err := h.timestamp.FindLastByUserID(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	openBreak := lastTimestamp.GetOpenBreak()
+	if lastTimestamp.IsComplete() || openBreak == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("there is no open break")))
+		return
+	}
+
+	now := time.Now()
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, lastTimestamp.ComingTimestamp, now) {
+		return
+	}
+
+	openBreak.BreakEnd = now
+
+	err = h.timestamp.TimestampBreakUpdate(openBreak)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -344,6 +496,10 @@ func (h *Timestamp) TimestampCreate(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestampCreateRequest.ComingTimestamp, timestampCreateRequest.GoingTimestamp) {
+		return
+	}
+
 	timestamp := model.Timestamp{
 		User:            &user,
 		ComingTimestamp: timestampCreateRequest.ComingTimestamp,
@@ -358,11 +514,15 @@ func (h *Timestamp) TimestampCreate(c *gin.Context) {
 	}
 
 	timestampCorrection := model.TimestampCorrection{
-		Timestamp:    timestamp,
-		ChangeReason: timestampCreateRequest.ChangeReason,
+		Timestamp:          timestamp,
+		ChangeReason:       timestampCreateRequest.ChangeReason,
+		NewComingTimestamp: timestamp.ComingTimestamp,
+		NewGoingTimestamp:  timestamp.GoingTimestamp,
+		NewIsHomeoffice:    timestamp.IsHomeoffice,
+		Status:             model.TIMESTAMP_CORRECTION_STATUS_APPROVED,
 	}
 
-	err = h.timestamp.TimestampCorrectionInsert(&timestampCorrection)
+	err = h.timestamp.TimestampCorrectionInsert(nil, &timestampCorrection)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -408,13 +568,19 @@ func (h *Timestamp) TimestampUserQueryMonthOvertime(c *gin.Context) {
 		return
 	}
 
-	holidays, err := h.holiday.HolidayFindByYear(year)
+	holidays, err := h.holiday.HolidayFindByUserIdAndYear(user.ID, year)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	neededHours := model.GetNeededHoursForMonth(holidays, year, month)
+	neededHours := model.GetNeededHoursForMonth(holidays, workTimeModels, year, month)
 
 	subtractedHours := 0.0
 	if overtimeHours > 0 {
@@ -539,13 +705,19 @@ func (h *Timestamp) TimestampQueryMonthOvertime(c *gin.Context) {
 		return
 	}
 
-	holidays, err := h.holiday.HolidayFindByYear(year)
+	holidays, err := h.holiday.HolidayFindByUserIdAndYear(user.ID, year)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	neededHours := model.GetNeededHoursForMonth(holidays, year, month)
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	neededHours := model.GetNeededHoursForMonth(holidays, workTimeModels, year, month)
 
 	subtractedHours := 0.0
 	if overtimeHours > 0 {
@@ -602,6 +774,10 @@ func (h *Timestamp) TimestampOvertimeSet(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestamp.ComingTimestamp, timestamp.GoingTimestamp) {
+		return
+	}
+
 	var overtimeReasonUpdateRequest model.TimestampOvertimeReasonUpdateRequest
 
 	err = c.BindJSON(&overtimeReasonUpdateRequest)
@@ -612,7 +788,7 @@ func (h *Timestamp) TimestampOvertimeSet(c *gin.Context) {
 
 	timestamp.OvertimeReason = &overtimeReasonUpdateRequest.OvertimeReason
 
-	err = h.timestamp.Update(&timestamp)
+	err = h.timestamp.Update(nil, &timestamp)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
 		return
@@ -671,6 +847,21 @@ func (h *Timestamp) TimestampCorrectionCreate(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestamp.ComingTimestamp, timestamp.GoingTimestamp, timestampCorrectionCreateRequest.NewComingTimestamp, timestampCorrectionCreateRequest.NewGoingTimestamp) {
+		return
+	}
+
+	pendingCorrections, err := h.timestamp.TimestampCorrectionFindPendingByTimestampID(timestamp.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if len(pendingCorrections) > 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(fmt.Errorf("there is a pending correction for this timestamp")))
+		return
+	}
+
 	timestampCorrection := model.TimestampCorrection{
 		Timestamp:          timestamp,
 		ChangeReason:       timestampCorrectionCreateRequest.ChangeReason,
@@ -679,24 +870,36 @@ func (h *Timestamp) TimestampCorrectionCreate(c *gin.Context) {
 		NeededCorrection:   timestamp.NeedsCorrection,
 		CorrectionReason:   timestamp.CorrectionReason,
 		OvertimeReason:     timestamp.OvertimeReason,
+		NewComingTimestamp: timestampCorrectionCreateRequest.NewComingTimestamp,
+		NewGoingTimestamp:  timestampCorrectionCreateRequest.NewGoingTimestamp,
+		NewIsHomeoffice:    timestampCorrectionCreateRequest.IsHomeoffice,
+		Status:             model.TIMESTAMP_CORRECTION_STATUS_APPROVED,
 	}
 
-	err = h.timestamp.TimestampCorrectionInsert(&timestampCorrection)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
-		return
+	if timestampCorrection.NeedsApproval(settings, time.Now()) {
+		timestampCorrection.Status = model.TIMESTAMP_CORRECTION_STATUS_PENDING
 	}
 
-	timestamp.ComingTimestamp = timestampCorrectionCreateRequest.NewComingTimestamp
-	timestamp.GoingTimestamp = timestampCorrectionCreateRequest.NewGoingTimestamp
-	timestamp.IsHomeoffice = timestampCorrectionCreateRequest.IsHomeoffice
+	// approved corrections are applied together with storing them
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		err := h.timestamp.TimestampCorrectionInsert(tx, &timestampCorrection)
+		if err != nil || timestampCorrection.IsPending() {
+			return err
+		}
 
-	err = h.timestamp.Update(&timestamp)
+		timestampCorrection.Apply(&timestamp)
+		return h.timestamp.Update(tx, &timestamp)
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
+	if timestampCorrection.IsPending() {
+		c.JSON(http.StatusAccepted, model.NewSuccessResponse(timestamp))
+		return
+	}
+
 	c.JSON(http.StatusOK, model.NewSuccessResponse(timestamp))
 }
 
@@ -794,6 +997,10 @@ func (h *Timestamp) TimestampUserActionRequestCorrection(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestamp.ComingTimestamp, timestamp.GoingTimestamp) {
+		return
+	}
+
 	var timestampCorrectionRequest model.TimestampCorrectionRequest
 
 	err := c.BindJSON(&timestampCorrectionRequest)
@@ -805,7 +1012,7 @@ func (h *Timestamp) TimestampUserActionRequestCorrection(c *gin.Context) {
 	timestamp.CorrectionReason = &timestampCorrectionRequest.CorrectionReason
 	timestamp.NeedsCorrection = true
 
-	err = h.timestamp.Update(&timestamp)
+	err = h.timestamp.Update(nil, &timestamp)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
@@ -842,6 +1049,10 @@ func (h *Timestamp) TeamUserTimestampDelete(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestamp.ComingTimestamp, timestamp.GoingTimestamp) {
+		return
+	}
+
 	err = h.timestamp.Delete(&timestamp)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -851,6 +1062,144 @@ func (h *Timestamp) TeamUserTimestampDelete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *Timestamp) TeamTimestampCorrectionOpen(c *gin.Context) {
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	isLead := slices.ContainsFunc(team.Members, func(member model.TeamMember) bool {
+		return member.UserID == user.ID && (member.Level == model.TeamLevel_Lead || member.Level == model.TeamLevel_LeadSurrogate)
+	})
+
+	if !isLead {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(fmt.Errorf("you're not lead of the team")))
+		return
+	}
+
+	userIds := []uint{}
+	for _, member := range team.Members {
+		userIds = append(userIds, member.UserID)
+	}
+
+	timestampCorrections, err := h.timestamp.TimestampCorrectionFindPendingByUserIDs(userIds)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	users := make(map[uint]model.User)
+	result := []model.TimestampCorrectionOpenResponse{}
+	for _, timestampCorrection := range timestampCorrections {
+		userId := timestampCorrection.Timestamp.UserID
+		if _, exists := users[userId]; !exists {
+			timestampUser, err := h.user.FindByID(userId)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+				return
+			}
+			users[userId] = timestampUser
+		}
+
+		timestampUser := users[userId]
+		result = append(result, model.TimestampCorrectionOpenResponse{
+			TimestampCorrection: timestampCorrection,
+			User:                timestampUser.GetUserResponse(),
+		})
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+func (h *Timestamp) TeamTimestampCorrectionSign(c *gin.Context) {
+	var timestampCorrectionSignRequest model.TimestampCorrectionSignRequest
+	err := c.BindJSON(&timestampCorrectionSignRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	timestampCorrectionId, err := strconv.Atoi(c.Param("timestampCorrectionID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	timestampCorrection, err := h.timestamp.TimestampCorrectionFindByID(uint(timestampCorrectionId))
+	if err != nil {
+		if err == repository.ErrTimestampCorrectionNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	timestampUser, err := h.user.FindByID(timestampCorrection.Timestamp.UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	_, err = checkUserIsUserTeamlead(c, &team, &executingUser, &timestampUser)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(err))
+		return
+	}
+
+	if !timestampCorrection.IsPending() {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(fmt.Errorf("correction is already signed")))
+		return
+	}
+
+	timestampCorrection.Sign(&executingUser, timestampCorrectionSignRequest.Status, timestampCorrectionSignRequest.Message)
+
+	if timestampCorrection.Status == model.TIMESTAMP_CORRECTION_STATUS_APPROVED {
+		if !checkPeriodIsOpen(c, h.monthClosing, timestampUser.ID, timestampCorrection.OldComingTimestamp, timestampCorrection.OldGoingTimestamp, timestampCorrection.NewComingTimestamp, timestampCorrection.NewGoingTimestamp) {
+			return
+		}
+	}
+
+	err = h.outbox.Transaction(func(tx *gorm.DB) error {
+		if timestampCorrection.Status == model.TIMESTAMP_CORRECTION_STATUS_APPROVED {
+			timestamp := timestampCorrection.Timestamp
+			timestampCorrection.Apply(&timestamp)
+
+			err := h.timestamp.Update(tx, &timestamp)
+			if err != nil {
+				return err
+			}
+
+			timestampCorrection.Timestamp = timestamp
+		}
+
+		return h.timestamp.TimestampCorrectionUpdate(tx, &timestampCorrection)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(timestampCorrection))
+}
+
 func (h *Timestamp) TimestampUserDelete(c *gin.Context) {
 	user, success := getUserFromParam(c, h.user)
 	if !success {
@@ -862,6 +1211,10 @@ func (h *Timestamp) TimestampUserDelete(c *gin.Context) {
 		return
 	}
 
+	if !checkPeriodIsOpen(c, h.monthClosing, user.ID, timestamp.ComingTimestamp, timestamp.GoingTimestamp) {
+		return
+	}
+
 	err := h.timestamp.Delete(&timestamp)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -973,13 +1326,19 @@ func (h *Timestamp) TeamUserTimestampQueryMonthOvertime(c *gin.Context) {
 		return
 	}
 
-	holidays, err := h.holiday.HolidayFindByYear(year)
+	holidays, err := h.holiday.HolidayFindByUserIdAndYear(user.ID, year)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
-	neededHours := model.GetNeededHoursForMonth(holidays, year, month)
+	neededHours := model.GetNeededHoursForMonth(holidays, workTimeModels, year, month)
 
 	subtractedHours := 0.0
 	if overtimeHours > 0 {
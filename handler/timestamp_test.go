@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimestampActions(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+	handler := NewTimestamp(env, userRepo, timestampRepo, memory.NewAbsence(db), memory.NewSettings(db), memory.NewHoliday(db), nil, nil, memory.NewTeam(db), memory.NewWorkTimeModel(db), monthClosingRepo, memory.NewLocation(db), worker.NewOutbox(env, memory.NewOutbox(db), nil))
+
+	user := model.User{Username: "employee"}
+	err := userRepo.Insert(&user)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name       string
+		Action     string
+		Handler    gin.HandlerFunc
+		CloseMonth bool
+		WantCode   int
+	}{
+		{Name: "check in", Action: "checkin", Handler: handler.TimestampActionCheckIn, WantCode: http.StatusCreated},
+		{Name: "check in twice", Action: "checkin", Handler: handler.TimestampActionCheckIn, WantCode: http.StatusBadRequest},
+		{Name: "pause start", Action: "pause/start", Handler: handler.TimestampActionPauseStart, WantCode: http.StatusCreated},
+		{Name: "pause start twice", Action: "pause/start", Handler: handler.TimestampActionPauseStart, WantCode: http.StatusBadRequest},
+		{Name: "pause end", Action: "pause/end", Handler: handler.TimestampActionPauseEnd, WantCode: http.StatusOK},
+		{Name: "check out", Action: "checkout", Handler: handler.TimestampActionCheckOut, WantCode: http.StatusOK},
+		{Name: "pause end without timestamp", Action: "pause/end", Handler: handler.TimestampActionPauseEnd, WantCode: http.StatusBadRequest},
+		{Name: "check out twice", Action: "checkout", Handler: handler.TimestampActionCheckOut, WantCode: http.StatusBadRequest},
+		{Name: "check in closed month", Action: "checkin", Handler: handler.TimestampActionCheckIn, CloseMonth: true, WantCode: http.StatusLocked},
+	}
+
+	for _, test := range testData {
+		if test.CloseMonth {
+			now := time.Now()
+			err := monthClosingRepo.MonthClosingInsert(&model.MonthClosing{Year: now.Year(), Month: int(now.Month())})
+			if err != nil {
+				t.Fatalf("%s: want no error, got %s", test.Name, err)
+			}
+		}
+
+		path := "/timestamp/action/" + test.Action
+		response := testRequest(test.Handler, user, http.MethodPost, path, path, map[string]any{})
+		if response.Code != test.WantCode {
+			t.Errorf("%s: want status %d, got %d (%s)", test.Name, test.WantCode, response.Code, response.Body.String())
+		}
+	}
+
+	count, err := timestampRepo.CountByUserID(user.ID)
+	if err != nil || count != 1 {
+		t.Fatalf("stored: want one timestamp, got %d (%v)", count, err)
+	}
+
+	timestamp, err := timestampRepo.FindLastByUserID(user.ID)
+	if err != nil {
+		t.Fatalf("stored: want no error, got %s", err)
+	}
+	if !timestamp.IsComplete() {
+		t.Errorf("stored: want complete timestamp, got %+v", timestamp)
+	}
+	if len(timestamp.Breaks) != 1 || timestamp.GetOpenBreak() != nil {
+		t.Errorf("stored: want one closed break, got %+v", timestamp.Breaks)
+	}
+}
+
+func TestTimestampCorrectionCreateClosedMonth(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+	handler := NewTimestamp(env, userRepo, timestampRepo, memory.NewAbsence(db), memory.NewSettings(db), memory.NewHoliday(db), nil, nil, memory.NewTeam(db), memory.NewWorkTimeModel(db), monthClosingRepo, memory.NewLocation(db), worker.NewOutbox(env, memory.NewOutbox(db), nil))
+
+	user := model.User{Username: "employee"}
+	err := userRepo.Insert(&user)
+	if err == nil {
+		err = monthClosingRepo.MonthClosingInsert(&model.MonthClosing{Year: 2024, Month: 3})
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	newTimestamp := func(month int, day int) model.Timestamp {
+		timestamp := model.Timestamp{
+			UserID:          user.ID,
+			ComingTimestamp: time.Date(2024, time.Month(month), day, 8, 0, 0, 0, time.UTC),
+			GoingTimestamp:  time.Date(2024, time.Month(month), day, 16, 0, 0, 0, time.UTC),
+		}
+		err := timestampRepo.Insert(&timestamp)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+		return timestamp
+	}
+
+	closedTimestamp := newTimestamp(3, 12)
+	openTimestamp := newTimestamp(4, 9)
+
+	testData := []struct {
+		Name       string
+		Timestamp  model.Timestamp
+		NewComing  time.Time
+		WantLocked bool
+	}{
+		{Name: "closed month", Timestamp: closedTimestamp, NewComing: closedTimestamp.ComingTimestamp.Add(time.Hour), WantLocked: true},
+		{Name: "moved into closed month", Timestamp: openTimestamp, NewComing: time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC), WantLocked: true},
+		{Name: "open month", Timestamp: openTimestamp, NewComing: openTimestamp.ComingTimestamp.Add(time.Hour)},
+	}
+
+	route := "/timestamp/:timestampID/correction"
+	for _, test := range testData {
+		path := fmt.Sprintf("/timestamp/%d/correction", test.Timestamp.ID)
+		response := testRequest(handler.TimestampCorrectionCreate, user, http.MethodPost, route, path, model.TimestampCorrectionCreateRequest{
+			ChangeReason:       "forgot to check in on time",
+			NewComingTimestamp: test.NewComing,
+			NewGoingTimestamp:  test.Timestamp.GoingTimestamp,
+		})
+		if locked := response.Code == http.StatusLocked; locked != test.WantLocked {
+			t.Errorf("%s: want locked %t, got status %d (%s)", test.Name, test.WantLocked, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestTeamTimestampCorrectionSign(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	teamRepo := memory.NewTeam(db)
+	handler := NewTimestamp(env, userRepo, timestampRepo, memory.NewAbsence(db), memory.NewSettings(db), memory.NewHoliday(db), nil, nil, teamRepo, memory.NewWorkTimeModel(db), memory.NewMonthClosing(db), memory.NewLocation(db), worker.NewOutbox(env, memory.NewOutbox(db), nil))
+
+	lead := model.User{Username: "lead"}
+	employee := model.User{Username: "employee"}
+	err := userRepo.Insert(&lead)
+	if err == nil {
+		err = userRepo.Insert(&employee)
+	}
+	team := model.Team{Teamname: "team"}
+	if err == nil {
+		team.Members = []model.TeamMember{
+			{UserID: lead.ID, Level: model.TeamLevel_Lead},
+			{UserID: employee.ID, Level: model.TeamLevel_Member},
+		}
+		err = teamRepo.TeamInsert(&team)
+	}
+	timestamp := model.Timestamp{
+		UserID:          employee.ID,
+		ComingTimestamp: time.Date(2024, 4, 9, 8, 0, 0, 0, time.UTC),
+		GoingTimestamp:  time.Date(2024, 4, 9, 16, 0, 0, 0, time.UTC),
+	}
+	if err == nil {
+		err = timestampRepo.Insert(&timestamp)
+	}
+	timestampCorrection := model.TimestampCorrection{
+		Timestamp:          timestamp,
+		ChangeReason:       "forgot to check in on time",
+		OldComingTimestamp: timestamp.ComingTimestamp,
+		OldGoingTimestamp:  timestamp.GoingTimestamp,
+		NewComingTimestamp: timestamp.ComingTimestamp.Add(-time.Hour),
+		NewGoingTimestamp:  timestamp.GoingTimestamp,
+		Status:             model.TIMESTAMP_CORRECTION_STATUS_PENDING,
+	}
+	if err == nil {
+		err = timestampRepo.TimestampCorrectionInsert(nil, &timestampCorrection)
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name     string
+		WantCode int
+	}{
+		{Name: "pending correction", WantCode: http.StatusOK},
+		{Name: "already signed correction", WantCode: http.StatusConflict},
+	}
+
+	route := "/team/:teamID/timestamp/correction/:timestampCorrectionID/sign"
+	path := fmt.Sprintf("/team/%d/timestamp/correction/%d/sign", team.ID, timestampCorrection.ID)
+	for _, test := range testData {
+		response := testRequest(handler.TeamTimestampCorrectionSign, lead, http.MethodPost, route, path, model.TimestampCorrectionSignRequest{
+			Status: model.TIMESTAMP_CORRECTION_STATUS_APPROVED,
+		})
+		if response.Code != test.WantCode {
+			t.Errorf("%s: want status %d, got %d (%s)", test.Name, test.WantCode, response.Code, response.Body.String())
+		}
+	}
+
+	timestamp, err = timestampRepo.FindByID(timestamp.ID)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if !timestamp.ComingTimestamp.Equal(timestampCorrection.NewComingTimestamp) {
+		t.Errorf("want coming timestamp %s, got %s", timestampCorrection.NewComingTimestamp, timestamp.ComingTimestamp)
+	}
+}
@@ -16,11 +16,11 @@ import (
 
 type User struct {
 	env  *core.Environment
-	user *repository.User
-	team *repository.Team
+	user repository.UserRepository
+	team repository.TeamRepository
 }
 
-func NewUser(env *core.Environment, user *repository.User, team *repository.Team) *User {
+func NewUser(env *core.Environment, user repository.UserRepository, team repository.TeamRepository) *User {
 	return &User{
 		env:  env,
 		user: user,
@@ -88,6 +88,9 @@ func (h *User) AdministrationUserUpdate(c *gin.Context) {
 	user.OvertimeSubtractionAmount = userUpdateRequest.OvertimeSubtractionAmount
 	user.OvertimeSubtractionModel = userUpdateRequest.OvertimeSubtractionModel
 	user.StaffNumber = userUpdateRequest.StaffNumber
+	user.HolidayDaysPerYear = userUpdateRequest.HolidayDaysPerYear
+	user.EntryDate = userUpdateRequest.EntryDate
+	user.ExitDate = userUpdateRequest.ExitDate
 
 	err = h.user.Update(&user)
 	if err != nil {
@@ -298,7 +301,8 @@ func (h *User) AdministrationTeamCreate(c *gin.Context) {
 	}
 
 	team := model.Team{
-		Teamname: teamCreateRequest.Teamname,
+		Teamname:              teamCreateRequest.Teamname,
+		MinimumPresentMembers: teamCreateRequest.MinimumPresentMembers,
 	}
 
 	err = h.team.TeamInsert(&team)
@@ -337,13 +341,15 @@ func (h *User) AdministrationTeamUpdate(c *gin.Context) {
 		return
 	}
 
-	team, err := h.team.TeamFindById(uint(teamId), false)
+	// the members are loaded, TeamUpdate replaces them
+	team, err := h.team.TeamFindById(uint(teamId), true)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
 		return
 	}
 
 	team.Teamname = teamUpdateRequest.Teamname
+	team.MinimumPresentMembers = teamUpdateRequest.MinimumPresentMembers
 	err = h.team.TeamUpdate(&team)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
@@ -518,3 +524,88 @@ func (h *User) TeamUserById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(user.GetUserResponse()))
 }
+
+func (h *User) AdministrationTeamBlackoutPeriodGetAll(c *gin.Context) {
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	blackoutPeriods, err := h.team.TeamBlackoutPeriodFindByTeamId(team.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(blackoutPeriods))
+}
+
+func (h *User) AdministrationTeamBlackoutPeriodCreate(c *gin.Context) {
+	var blackoutPeriodCreateRequest model.TeamBlackoutPeriodCreateRequest
+	err := c.BindJSON(&blackoutPeriodCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	if blackoutPeriodCreateRequest.BlackoutTill.Before(blackoutPeriodCreateRequest.BlackoutFrom) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("blackout till is before blackout from")))
+		return
+	}
+
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	blackoutPeriod := model.TeamBlackoutPeriod{
+		TeamID:       team.ID,
+		BlackoutFrom: blackoutPeriodCreateRequest.BlackoutFrom,
+		BlackoutTill: blackoutPeriodCreateRequest.BlackoutTill,
+		Description:  blackoutPeriodCreateRequest.Description,
+	}
+
+	err = h.team.TeamBlackoutPeriodInsert(&blackoutPeriod)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(blackoutPeriod))
+}
+
+func (h *User) AdministrationTeamBlackoutPeriodDelete(c *gin.Context) {
+	team, success := getTeamFromParam(c, h.team)
+	if !success {
+		return
+	}
+
+	blackoutPeriodId, err := strconv.ParseUint(c.Param("teamBlackoutPeriodID"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	blackoutPeriod, err := h.team.TeamBlackoutPeriodFindById(uint(blackoutPeriodId))
+	if err != nil {
+		if err == repository.ErrTeamBlackoutPeriodNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	if blackoutPeriod.TeamID != team.ID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(fmt.Errorf("blackout period is not part of the team")))
+		return
+	}
+
+	err = h.team.TeamBlackoutPeriodDelete(&blackoutPeriod)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type Vacation struct {
+	env            *core.Environment
+	user           repository.UserRepository
+	vacation       repository.VacationRepository
+	vacationWorker *worker.Vacation
+}
+
+func NewVacation(env *core.Environment, user repository.UserRepository, vacation repository.VacationRepository, vacationWorker *worker.Vacation) *Vacation {
+	return &Vacation{
+		env:            env,
+		user:           user,
+		vacation:       vacation,
+		vacationWorker: vacationWorker,
+	}
+}
+
+func (h *Vacation) CurrentUserVacationLedger(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	ledger, err := h.vacationWorker.CalculateLedger(user, time.Now().Year())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(ledger))
+}
+
+func (h *Vacation) AdministrationUserVacationLedger(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	ledger, err := h.vacationWorker.CalculateLedger(user, time.Now().Year())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(ledger))
+}
+
+func (h *Vacation) AdministrationUserVacationAdjustmentGetAll(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	adjustments, err := h.vacation.VacationAdjustmentFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(adjustments))
+}
+
+func (h *Vacation) AdministrationUserVacationAdjustmentCreate(c *gin.Context) {
+	var vacationAdjustmentCreateRequest model.VacationAdjustmentCreateRequest
+	err := c.BindJSON(&vacationAdjustmentCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	executingUser, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	adjustment := model.VacationAdjustment{
+		UserID:          user.ID,
+		Year:            vacationAdjustmentCreateRequest.Year,
+		Days:            vacationAdjustmentCreateRequest.Days,
+		Comment:         vacationAdjustmentCreateRequest.Comment,
+		CreatedByUserID: executingUser.ID,
+	}
+
+	err = h.vacation.VacationAdjustmentInsert(&adjustment)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(adjustment))
+}
+
+func (h *Vacation) AdministrationUserVacationAdjustmentDelete(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	adjustmentId, err := strconv.Atoi(c.Param("vacationAdjustmentID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	adjustment, err := h.vacation.VacationAdjustmentFindById(uint(adjustmentId))
+	if err != nil {
+		if err == repository.ErrVacationAdjustmentNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	if adjustment.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(repository.ErrVacationAdjustmentNotFound))
+		return
+	}
+
+	err = h.vacation.VacationAdjustmentDelete(&adjustment)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
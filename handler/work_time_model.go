@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkTimeModel struct {
+	env           *core.Environment
+	user          repository.UserRepository
+	workTimeModel repository.WorkTimeModelRepository
+}
+
+func NewWorkTimeModel(env *core.Environment, user repository.UserRepository, workTimeModel repository.WorkTimeModelRepository) *WorkTimeModel {
+	return &WorkTimeModel{
+		env:           env,
+		user:          user,
+		workTimeModel: workTimeModel,
+	}
+}
+
+func (h *WorkTimeModel) AdministrationWorkTimeModelGetAll(c *gin.Context) {
+	workTimeModels, err := h.workTimeModel.WorkTimeModelFindAll()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(workTimeModels))
+}
+
+func (h *WorkTimeModel) AdministrationWorkTimeModelCreate(c *gin.Context) {
+	var workTimeModelCreateRequest model.WorkTimeModelCreateRequest
+	err := c.BindJSON(&workTimeModelCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModel := model.WorkTimeModel{
+		Name:           workTimeModelCreateRequest.Name,
+		HoursMonday:    workTimeModelCreateRequest.HoursMonday,
+		HoursTuesday:   workTimeModelCreateRequest.HoursTuesday,
+		HoursWednesday: workTimeModelCreateRequest.HoursWednesday,
+		HoursThursday:  workTimeModelCreateRequest.HoursThursday,
+		HoursFriday:    workTimeModelCreateRequest.HoursFriday,
+		HoursSaturday:  workTimeModelCreateRequest.HoursSaturday,
+		HoursSunday:    workTimeModelCreateRequest.HoursSunday,
+	}
+
+	err = h.workTimeModel.WorkTimeModelInsert(&workTimeModel)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(workTimeModel))
+}
+
+func (h *WorkTimeModel) AdministrationWorkTimeModelUpdate(c *gin.Context) {
+	var workTimeModelUpdateRequest model.WorkTimeModelCreateRequest
+	err := c.BindJSON(&workTimeModelUpdateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	workTimeModel, success := h.getWorkTimeModelFromParam(c)
+	if !success {
+		return
+	}
+
+	workTimeModel.Name = workTimeModelUpdateRequest.Name
+	workTimeModel.HoursMonday = workTimeModelUpdateRequest.HoursMonday
+	workTimeModel.HoursTuesday = workTimeModelUpdateRequest.HoursTuesday
+	workTimeModel.HoursWednesday = workTimeModelUpdateRequest.HoursWednesday
+	workTimeModel.HoursThursday = workTimeModelUpdateRequest.HoursThursday
+	workTimeModel.HoursFriday = workTimeModelUpdateRequest.HoursFriday
+	workTimeModel.HoursSaturday = workTimeModelUpdateRequest.HoursSaturday
+	workTimeModel.HoursSunday = workTimeModelUpdateRequest.HoursSunday
+
+	err = h.workTimeModel.WorkTimeModelUpdate(&workTimeModel)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(workTimeModel))
+}
+
+func (h *WorkTimeModel) AdministrationWorkTimeModelDelete(c *gin.Context) {
+	workTimeModel, success := h.getWorkTimeModelFromParam(c)
+	if !success {
+		return
+	}
+
+	isAssigned, err := h.workTimeModel.WorkTimeModelIsAssigned(workTimeModel.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	if isAssigned {
+		c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(errors.New("work time model is assigned to users")))
+		return
+	}
+
+	err = h.workTimeModel.WorkTimeModelDelete(&workTimeModel)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WorkTimeModel) AdministrationUserWorkTimeModelGetAll(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	userWorkTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(userWorkTimeModels))
+}
+
+func (h *WorkTimeModel) AdministrationUserWorkTimeModelCreate(c *gin.Context) {
+	var userWorkTimeModelCreateRequest model.UserWorkTimeModelCreateRequest
+	err := c.BindJSON(&userWorkTimeModelCreateRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	validFrom := helper.GetDayDate(userWorkTimeModelCreateRequest.ValidFrom)
+	var validTill *time.Time
+	if userWorkTimeModelCreateRequest.ValidTill != nil {
+		day := helper.GetDayDate(*userWorkTimeModelCreateRequest.ValidTill)
+		if day.Before(validFrom) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("valid till is before valid from")))
+			return
+		}
+		validTill = &day
+	}
+
+	workTimeModel, err := h.workTimeModel.WorkTimeModelFindById(userWorkTimeModelCreateRequest.WorkTimeModelID)
+	if err != nil {
+		if err == repository.ErrWorkTimeModelNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	existingWorkTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	userWorkTimeModel := model.UserWorkTimeModel{
+		UserID:          user.ID,
+		WorkTimeModelID: workTimeModel.ID,
+		WorkTimeModel:   workTimeModel,
+		ValidFrom:       validFrom,
+		ValidTill:       validTill,
+	}
+
+	// an open assignment that started before the new one ends the day before,
+	// any other overlap is rejected
+	splitWorkTimeModels := model.UserWorkTimeModels{}
+	for _, existing := range existingWorkTimeModels {
+		if existing.ValidTill == nil && existing.ValidFrom.Before(validFrom) {
+			previousDay := validFrom.AddDate(0, 0, -1)
+			existing.ValidTill = &previousDay
+			splitWorkTimeModels = append(splitWorkTimeModels, existing)
+			continue
+		}
+
+		if existing.Overlaps(&userWorkTimeModel) {
+			c.AbortWithStatusJSON(http.StatusConflict, model.NewErrorResponse(fmt.Errorf("overlaps the assignment of %s valid from %s", existing.WorkTimeModel.Name, existing.ValidFrom.Format(time.DateOnly))))
+			return
+		}
+	}
+
+	for _, existing := range splitWorkTimeModels {
+		err = h.workTimeModel.UserWorkTimeModelUpdate(&existing)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+			return
+		}
+	}
+
+	err = h.workTimeModel.UserWorkTimeModelInsert(&userWorkTimeModel)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(userWorkTimeModel))
+}
+
+func (h *WorkTimeModel) AdministrationUserWorkTimeModelDelete(c *gin.Context) {
+	user, success := getUserFromParam(c, h.user)
+	if !success {
+		return
+	}
+
+	userWorkTimeModelId, err := strconv.Atoi(c.Param("userWorkTimeModelID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return
+	}
+
+	userWorkTimeModel, err := h.workTimeModel.UserWorkTimeModelFindById(uint(userWorkTimeModelId))
+	if err != nil {
+		if err == repository.ErrUserWorkTimeModelNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return
+	}
+
+	if userWorkTimeModel.UserID != user.ID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(errors.New("work time model is not assigned to user")))
+		return
+	}
+
+	err = h.workTimeModel.UserWorkTimeModelDelete(&userWorkTimeModel)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WorkTimeModel) CurrentUserWorkTimeModelGetAll(c *gin.Context) {
+	user, err := auth.GetUserFromSession(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(err))
+		return
+	}
+
+	userWorkTimeModels, err := h.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(userWorkTimeModels))
+}
+
+func (h *WorkTimeModel) getWorkTimeModelFromParam(c *gin.Context) (model.WorkTimeModel, bool) {
+	workTimeModelId, err := strconv.Atoi(c.Param("workTimeModelID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.NewErrorResponse(err))
+		return model.WorkTimeModel{}, false
+	}
+
+	workTimeModel, err := h.workTimeModel.WorkTimeModelFindById(uint(workTimeModelId))
+	if err != nil {
+		if err == repository.ErrWorkTimeModelNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, model.NewErrorResponse(err))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.NewErrorResponse(err))
+		}
+		return model.WorkTimeModel{}, false
+	}
+
+	return workTimeModel, true
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+)
+
+func TestAdministrationUserWorkTimeModelCreate(t *testing.T) {
+	env := &core.Environment{}
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	workTimeModelRepo := memory.NewWorkTimeModel(db)
+	handler := NewWorkTimeModel(env, userRepo, workTimeModelRepo)
+
+	administrator := model.User{Username: "administrator", AccessLevel: model.USER_ACCESS_LEVEL_ADMIN}
+	employee := model.User{Username: "employee", AccessLevel: model.USER_ACCESS_LEVEL_USER}
+	workTimeModel := model.DefaultWorkTimeModel()
+	err := userRepo.Insert(&administrator)
+	if err == nil {
+		err = userRepo.Insert(&employee)
+	}
+	if err == nil {
+		err = workTimeModelRepo.WorkTimeModelInsert(&workTimeModel)
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	date := func(month int, day int) *time.Time {
+		value := time.Date(2024, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		return &value
+	}
+
+	testData := []struct {
+		Name       string
+		ValidFrom  time.Time
+		ValidTill  *time.Time
+		WantStatus int
+	}{
+		{Name: "first", ValidFrom: *date(1, 1), WantStatus: http.StatusCreated},
+		{Name: "splits the open one", ValidFrom: *date(6, 1), ValidTill: date(6, 30), WantStatus: http.StatusCreated},
+		{Name: "overlaps the closed one", ValidFrom: *date(5, 1), ValidTill: date(6, 10), WantStatus: http.StatusConflict},
+		{Name: "starts with the closed one", ValidFrom: *date(6, 1), WantStatus: http.StatusConflict},
+		{Name: "after the closed one", ValidFrom: *date(7, 1), WantStatus: http.StatusCreated},
+	}
+
+	route := "/administration/user/:userID/work_time_model"
+	path := fmt.Sprintf("/administration/user/%d/work_time_model", employee.ID)
+	for _, test := range testData {
+		recorder := testRequest(handler.AdministrationUserWorkTimeModelCreate, administrator, http.MethodPost, route, path, model.UserWorkTimeModelCreateRequest{
+			WorkTimeModelID: workTimeModel.ID,
+			ValidFrom:       test.ValidFrom,
+			ValidTill:       test.ValidTill,
+		})
+		if recorder.Code != test.WantStatus {
+			t.Errorf("%s: want status %d, got %d: %s", test.Name, test.WantStatus, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	workTimeModels, err := workTimeModelRepo.UserWorkTimeModelFindByUserId(employee.ID)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if len(workTimeModels) != 3 {
+		t.Fatalf("want 3 assignments, got %d", len(workTimeModels))
+	}
+
+	for i, current := range workTimeModels {
+		for _, other := range workTimeModels[i+1:] {
+			if current.Overlaps(&other) {
+				t.Errorf("assignment from %s overlaps the one from %s", current.ValidFrom, other.ValidFrom)
+			}
+		}
+	}
+
+	first := workTimeModels[0]
+	if first.ValidTill == nil || !first.ValidTill.Equal(*date(5, 31)) {
+		t.Errorf("first: want valid till %s, got %v", date(5, 31), first.ValidTill)
+	}
+}
@@ -0,0 +1,271 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+const (
+	ICAL_DATE_LAYOUT          = "20060102"
+	ICAL_DATE_TIME_LAYOUT     = "20060102T150405"
+	ICAL_UTC_DATE_TIME_LAYOUT = "20060102T150405Z"
+	ICAL_PRODUCT_ID           = "-//BeeTimeClock//BeeTimeClock Server//EN"
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+var icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// EncodeEvent renders the entry as iCalendar object. Timed entries are stored
+// in UTC, so no time zone definition is required.
+func EncodeEvent(uid string, entry model.CalendarEntry, location *time.Location) (string, error) {
+	event, err := encodeEventLines(uid, entry, location)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeCalendar([]string{}, event), nil
+}
+
+// EncodeCalendar renders the entries as iCalendar feed, the identifiers of the
+// entries are used as uids.
+func EncodeCalendar(name string, entries []model.CalendarEntry, location *time.Location) (string, error) {
+	events := []string{}
+	for _, entry := range entries {
+		event, err := encodeEventLines(entry.Identifier, entry, location)
+		if err != nil {
+			return "", err
+		}
+
+		events = append(events, event...)
+	}
+
+	return encodeCalendar([]string{"X-WR-CALNAME:" + icalEscaper.Replace(name)}, events), nil
+}
+
+func encodeCalendar(properties []string, events []string) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ICAL_PRODUCT_ID,
+		"CALSCALE:GREGORIAN",
+	}
+	lines = append(lines, properties...)
+	lines = append(lines, events...)
+	lines = append(lines, "END:VCALENDAR")
+
+	for i, line := range lines {
+		lines[i] = foldLine(line)
+	}
+
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func encodeEventLines(uid string, entry model.CalendarEntry, location *time.Location) ([]string, error) {
+	start, err := encodeDateTime("DTSTART", entry.From, entry.IsAllDay, location)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := encodeDateTime("DTEND", entry.Till, entry.IsAllDay, location)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "CONFIRMED"
+	busyStatus := "BUSY"
+	switch entry.ShowAs {
+	case model.CALENDAR_SHOW_AS_TENTATIVE:
+		status = "TENTATIVE"
+		busyStatus = "TENTATIVE"
+	case model.CALENDAR_SHOW_AS_OUT_OF_OFFICE:
+		busyStatus = "OOF"
+	}
+
+	return []string{
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + time.Now().UTC().Format(ICAL_UTC_DATE_TIME_LAYOUT),
+		model.CALENDAR_ENTRY_ID_PROPERTY + ":" + icalEscaper.Replace(uid),
+		"SUMMARY:" + icalEscaper.Replace(entry.Subject),
+		start,
+		end,
+		"CLASS:PRIVATE",
+		"TRANSP:OPAQUE",
+		"STATUS:" + status,
+		"X-MICROSOFT-CDO-BUSYSTATUS:" + busyStatus,
+		"END:VEVENT",
+	}, nil
+}
+
+// foldLine splits lines longer than 75 octets without breaking characters.
+func foldLine(line string) string {
+	var builder strings.Builder
+	length := 0
+
+	for _, char := range line {
+		size := len(string(char))
+		if length+size > 75 {
+			builder.WriteString("\r\n ")
+			length = 1
+		}
+		builder.WriteRune(char)
+		length += size
+	}
+
+	return builder.String()
+}
+
+func encodeDateTime(name string, dateTime string, isAllDay bool, location *time.Location) (string, error) {
+	value, err := time.ParseInLocation(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT, truncateDateTime(dateTime), location)
+	if err != nil {
+		return "", err
+	}
+
+	if isAllDay {
+		return fmt.Sprintf("%s;VALUE=DATE:%s", name, value.Format(ICAL_DATE_LAYOUT)), nil
+	}
+
+	return fmt.Sprintf("%s:%s", name, value.UTC().Format(ICAL_UTC_DATE_TIME_LAYOUT)), nil
+}
+
+func truncateDateTime(dateTime string) string {
+	if len(dateTime) > len(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT) {
+		return dateTime[:len(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT)]
+	}
+
+	return dateTime
+}
+
+// DecodeEvent reads the managed fields of the first event of the iCalendar
+// object, the date times are returned in the given location. Modified is the
+// later one of DTSTAMP and LAST-MODIFIED.
+func DecodeEvent(data string, location *time.Location) (model.CalendarEntry, error) {
+	entry := model.CalendarEntry{
+		ShowAs: model.CALENDAR_SHOW_AS_BUSY,
+	}
+	status := ""
+	inEvent := false
+
+	for _, line := range unfoldLines(data) {
+		name, params, value := splitLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			continue
+		case name == "END" && value == "VEVENT":
+			if status == "TENTATIVE" {
+				entry.ShowAs = model.CALENDAR_SHOW_AS_TENTATIVE
+			}
+			return entry, nil
+		case !inEvent:
+			continue
+		}
+
+		var err error
+		switch name {
+		case "UID":
+			entry.Identifier = value
+		case model.CALENDAR_ENTRY_ID_PROPERTY:
+			entry.BeeTimeClockID = icalUnescaper.Replace(value)
+		case "DTSTAMP", "LAST-MODIFIED":
+			// the server may keep the stamp of the client, the later one wins
+			modified, parseErr := time.Parse(ICAL_UTC_DATE_TIME_LAYOUT, value)
+			if parseErr == nil && modified.After(entry.Modified) {
+				entry.Modified = modified
+			}
+		case "SUMMARY":
+			entry.Subject = icalUnescaper.Replace(value)
+		case "DTSTART":
+			entry.From, entry.IsAllDay, err = decodeDateTime(params, value, location)
+		case "DTEND":
+			entry.Till, _, err = decodeDateTime(params, value, location)
+		case "STATUS":
+			status = value
+		case "X-MICROSOFT-CDO-BUSYSTATUS":
+			switch value {
+			case "OOF":
+				entry.ShowAs = model.CALENDAR_SHOW_AS_OUT_OF_OFFICE
+			case "TENTATIVE":
+				entry.ShowAs = model.CALENDAR_SHOW_AS_TENTATIVE
+			}
+		}
+
+		if err != nil {
+			return model.CalendarEntry{}, err
+		}
+	}
+
+	return model.CalendarEntry{}, fmt.Errorf("no event found")
+}
+
+func decodeDateTime(params map[string]string, value string, location *time.Location) (string, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(ICAL_DATE_LAYOUT) {
+		date, err := time.Parse(ICAL_DATE_LAYOUT, value)
+		if err != nil {
+			return "", false, err
+		}
+		return date.Format(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT), true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		dateTime, err := time.Parse(ICAL_UTC_DATE_TIME_LAYOUT, value)
+		if err != nil {
+			return "", false, err
+		}
+		return dateTime.In(location).Format(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT), false, nil
+	}
+
+	valueLocation := location
+	if tzid, exists := params["TZID"]; exists {
+		tzLocation, err := time.LoadLocation(tzid)
+		if err == nil {
+			valueLocation = tzLocation
+		}
+	}
+
+	dateTime, err := time.ParseInLocation(ICAL_DATE_TIME_LAYOUT, value, valueLocation)
+	if err != nil {
+		return "", false, err
+	}
+	return dateTime.In(location).Format(model.CALENDAR_ENTRY_DATE_TIME_LAYOUT), false, nil
+}
+
+func unfoldLines(data string) []string {
+	lines := []string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// splitLine splits a content line into its name, parameters and value.
+func splitLine(line string) (string, map[string]string, string) {
+	params := map[string]string{}
+
+	head, value, found := strings.Cut(line, ":")
+	if !found {
+		return strings.ToUpper(line), params, ""
+	}
+
+	parts := strings.Split(head, ";")
+	for _, param := range parts[1:] {
+		key, paramValue, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(paramValue, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, value
+}
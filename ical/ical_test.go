@@ -0,0 +1,72 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	location, err := time.LoadLocation(model.CALENDAR_ENTRY_DEFAULT_TIME_ZONE)
+	if err != nil {
+		t.Fatalf("want location, got %s", err)
+	}
+
+	tests := []struct {
+		name  string
+		entry model.CalendarEntry
+		want  string
+	}{
+		{
+			name: "all day",
+			entry: model.CalendarEntry{
+				Subject:  "BTC: Urlaub, Sommer",
+				From:     "2024-07-01T00:00:00",
+				Till:     "2024-07-06T00:00:00",
+				IsAllDay: true,
+				ShowAs:   model.CALENDAR_SHOW_AS_OUT_OF_OFFICE,
+			},
+			want: "DTSTART;VALUE=DATE:20240701",
+		},
+		{
+			name: "morning in summer time",
+			entry: model.CalendarEntry{
+				Subject: "BTC: Arzttermin mit einer sehr langen Beschreibung, die über mehrere Zeilen gefaltet wird",
+				From:    "2024-07-01T08:00:00",
+				Till:    "2024-07-01T12:00:00.0000000",
+				ShowAs:  model.CALENDAR_SHOW_AS_TENTATIVE,
+			},
+			want: "DTSTART:20240701T060000Z",
+		},
+	}
+
+	for _, test := range tests {
+		data, err := EncodeEvent("c0ffee", test.entry, location)
+		if err != nil {
+			t.Fatalf("%s: want event, got %s", test.name, err)
+		}
+
+		if !strings.Contains(data, test.want) {
+			t.Errorf("%s: want %s in event, got %s", test.name, test.want, data)
+		}
+
+		entry, err := DecodeEvent(data, location)
+		if err != nil {
+			t.Fatalf("%s: want entry, got %s", test.name, err)
+		}
+
+		if !entry.Equals(test.entry) {
+			t.Errorf("%s: want %+v, got %+v", test.name, test.entry, entry)
+		}
+
+		if entry.Identifier != "c0ffee" || entry.BeeTimeClockID != "c0ffee" {
+			t.Errorf("%s: want identifier c0ffee, got %s and %s", test.name, entry.Identifier, entry.BeeTimeClockID)
+		}
+
+		if time.Since(entry.Modified) > time.Minute {
+			t.Errorf("%s: want modified now, got %s", test.name, entry.Modified)
+		}
+	}
+}
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"io/fs"
 	"log"
 	"net/http"
@@ -12,30 +9,22 @@ import (
 	"time"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/auth"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/calendar"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/handler"
-	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/middleware"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/migrations"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/worker"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 var (
 	GitCommit string
 )
 
-const (
-	MIGRATION_HOMEOFFICE_GOING        = "HOMEOFFICE_GOING"
-	MIGRATION_EXTERNAL_CALENDAR       = "EXTERNAL_CALENDAR"
-	MIGRATION_EXTERNAL_CALENDAR_MULTI = "EXTERNAL_CALENDAR_MULTI"
-	MIGRATION_ABSENCE_APPROVAL        = "ABSENCE_APPROVAL"
-)
-
 func main() {
 	env := core.NewEnvironment()
 
@@ -96,96 +85,89 @@ func main() {
 		panic(err)
 	}
 
+	locationRepo := repository.NewLocation(env)
+	err = locationRepo.Migrate()
+	if err != nil {
+		panic(err)
+	}
+
 	holidayRepo := repository.NewHoliday(env)
 	err = holidayRepo.Migrate()
 	if err != nil {
 		panic(err)
 	}
 
-	timestampWorker := worker.NewTimestamp(env, userRepo, externalWorkRepo, timestampRepo, holidayRepo, absenceRepo)
-	overtimeWorker := worker.NewOvertime(env, userRepo, externalWorkRepo, timestampRepo, holidayRepo, overtimeRepo, timestampWorker, absenceRepo)
-
-	userHandler := handler.NewUser(env, userRepo, teamRepo)
-	timestampHandler := handler.NewTimestamp(env, userRepo, timestampRepo, absenceRepo, settingsRepo, holidayRepo, timestampWorker, teamRepo)
-	fuelHandler := handler.NewFuel(env, userRepo, fuelRepo)
-	absenceHandler := handler.NewAbsence(env, userRepo, absenceRepo, teamRepo, holidayRepo)
-	migrationHandler := handler.NewMigration(env, migrationRepo)
-	administrationHandler := handler.NewAdministration(env, settingsRepo, absenceRepo, holidayRepo)
-	externalWorkHandler := handler.NewExternalWork(env, userRepo, externalWorkRepo, holidayRepo)
-	overtimeHandler := handler.NewOvertime(env, userRepo, overtimeRepo, overtimeWorker, teamRepo)
-	holidayHandler := handler.NewHoliday(env, holidayRepo)
-
-	authProvider := auth.NewAuthProvider(env, userRepo)
-
-	go importHolidays(holidayRepo)
-	go overtimeWorker.CalculateMissingMonths()
-
-	_, err = migrationRepo.MigrationFindByTitle(MIGRATION_HOMEOFFICE_GOING)
-	homeofficeGoingMigrationExists := true
+	workTimeModelRepo := repository.NewWorkTimeModel(env)
+	err = workTimeModelRepo.Migrate()
 	if err != nil {
-		if err == repository.ErrMigrationNotFound {
-			homeofficeGoingMigrationExists = false
-		} else {
-			panic(err)
-		}
+		panic(err)
 	}
 
-	if !homeofficeGoingMigrationExists {
-		log.Println("Migration: HOMEOFFICE_GOING started")
-		timestamps, err := timestampRepo.FindAll()
-		if err != nil {
-			panic(err)
-		}
-
-		for _, timestamp := range timestamps {
-			timestamp.IsHomeofficeGoing = timestamp.IsHomeoffice
-			err = timestampRepo.Update(&timestamp)
-
-			if err != nil {
-				homeofficeGoingMigration := model.Migration{
-					Title:      MIGRATION_HOMEOFFICE_GOING,
-					Result:     err.Error(),
-					FinishedAt: time.Now(),
-					Success:    true,
-				}
-				migrationRepo.MigrationInsert(&homeofficeGoingMigration)
-
-				panic(err)
-			}
-		}
-
-		homeofficeGoingMigration := model.Migration{
-			Title:      MIGRATION_HOMEOFFICE_GOING,
-			Result:     fmt.Sprintf("%d timestamps were migrated", len(timestamps)),
-			FinishedAt: time.Now(),
-			Success:    true,
-		}
-		migrationRepo.MigrationInsert(&homeofficeGoingMigration)
-		log.Println("Migration: HOMEOFFICE_GOING finished")
-	} else {
-		log.Println("Migration: HOMEOFFICE_GOING already finished")
+	monthClosingRepo := repository.NewMonthClosing(env)
+	err = monthClosingRepo.Migrate()
+	if err != nil {
+		panic(err)
 	}
 
-	err = migrateExternalCalendar(migrationRepo, absenceRepo)
+	vacationRepo := repository.NewVacation(env)
+	err = vacationRepo.Migrate()
 	if err != nil {
 		panic(err)
 	}
 
-	err = migrateExternalCalendarMulti(migrationRepo, absenceRepo)
+	outboxRepo := repository.NewOutbox(env)
+	err = outboxRepo.Migrate()
 	if err != nil {
 		panic(err)
 	}
 
-	err = migrateAbsenceApproval(migrationRepo, absenceRepo)
+	timestampWorker := worker.NewTimestamp(env, userRepo, externalWorkRepo, timestampRepo, holidayRepo, absenceRepo, workTimeModelRepo, settingsRepo, teamRepo)
+	complianceWorker := worker.NewCompliance(env, holidayRepo, timestampWorker)
+	calendarSyncWorker := worker.NewCalendarSync(env, userRepo, absenceRepo, externalWorkRepo, calendar.GetProviders())
+	outboxWorker := worker.NewOutbox(env, outboxRepo, calendarSyncWorker)
+	autoCheckoutWorker := worker.NewAutoCheckout(env, userRepo, timestampRepo, settingsRepo, holidayRepo, workTimeModelRepo, monthClosingRepo, outboxWorker)
+	vacationWorker := worker.NewVacation(env, absenceRepo, vacationRepo, settingsRepo, holidayRepo, workTimeModelRepo)
+	overtimeWorker := worker.NewOvertime(env, userRepo, externalWorkRepo, timestampRepo, holidayRepo, overtimeRepo, timestampWorker, absenceRepo, workTimeModelRepo, monthClosingRepo)
+	holidayWorker := worker.NewHoliday(env, holidayRepo, locationRepo, absenceRepo, workTimeModelRepo, overtimeRepo, monthClosingRepo, overtimeWorker)
+
+	migrationRegistry := migrations.NewRegistry(env, migrationRepo)
+	err = migrationRegistry.Register(migrations.GetMigrations(holidayRepo, workTimeModelRepo)...)
 	if err != nil {
 		panic(err)
 	}
 
-	err = migrations.MigrateAbsenceNettoDays(migrationRepo, absenceRepo, holidayRepo)
+	userHandler := handler.NewUser(env, userRepo, teamRepo)
+	timestampHandler := handler.NewTimestamp(env, userRepo, timestampRepo, absenceRepo, settingsRepo, holidayRepo, timestampWorker, complianceWorker, teamRepo, workTimeModelRepo, monthClosingRepo, locationRepo, outboxWorker)
+	fuelHandler := handler.NewFuel(env, userRepo, fuelRepo)
+	absenceHandler := handler.NewAbsence(env, userRepo, absenceRepo, teamRepo, holidayRepo, workTimeModelRepo, monthClosingRepo, settingsRepo, vacationWorker, outboxWorker)
+	migrationHandler := handler.NewMigration(env, migrationRepo, migrationRegistry)
+	administrationHandler := handler.NewAdministration(env, settingsRepo, absenceRepo, holidayRepo, locationRepo, outboxWorker)
+	externalWorkHandler := handler.NewExternalWork(env, userRepo, externalWorkRepo, holidayRepo, workTimeModelRepo, monthClosingRepo, outboxWorker)
+	overtimeHandler := handler.NewOvertime(env, userRepo, overtimeRepo, overtimeWorker, teamRepo)
+	holidayHandler := handler.NewHoliday(env, holidayRepo, holidayWorker)
+	workTimeModelHandler := handler.NewWorkTimeModel(env, userRepo, workTimeModelRepo)
+	complianceHandler := handler.NewCompliance(env, userRepo, teamRepo, complianceWorker)
+	monthClosingHandler := handler.NewMonthClosing(env, userRepo, teamRepo, monthClosingRepo, overtimeWorker)
+	vacationHandler := handler.NewVacation(env, userRepo, vacationRepo, vacationWorker)
+	outboxHandler := handler.NewOutbox(env, outboxRepo, outboxWorker)
+	locationHandler := handler.NewLocation(env, userRepo, locationRepo, holidayRepo, holidayWorker)
+	calendarFeedHandler := handler.NewCalendarFeed(env, userRepo, teamRepo, absenceRepo, externalWorkRepo, holidayRepo)
+
+	authProvider := auth.NewAuthProvider(env, userRepo)
+
+	go holidayWorker.Run()
+	go overtimeWorker.CalculateMissingMonths()
+	go autoCheckoutWorker.Run()
+
+	_, err = migrationRegistry.RunPending(false)
 	if err != nil {
 		panic(err)
 	}
 
+	// started after the calendar migrations, they create external events themselves
+	go calendarSyncWorker.Run()
+	go outboxWorker.Run()
+
 	r := gin.Default()
 	r.Use(middleware.AcceptCors)
 
@@ -207,6 +189,7 @@ func main() {
 		v1.GET("auth", authProvider.Auth)
 		v1.GET("auth/providers", authProvider.AuthProviders)
 		v1.GET("auth/microsoft", authProvider.MicrosoftAuthSettings)
+		v1.GET("calendar/feed/:token", calendarFeedHandler.CalendarFeedGet)
 
 		v1.GET("status", func(c *gin.Context) {
 			commit := GitCommit
@@ -234,6 +217,9 @@ func main() {
 					administrationTeam.GET(":teamID/member", userHandler.AdministrationTeamMemberGetByTeamID)
 					administrationTeam.POST(":teamID/member", userHandler.AdministrationTeamMemberCreate)
 					administrationTeam.DELETE(":teamID/member/:teamMemberID", userHandler.AdministrationTeamMemberDelete)
+					administrationTeam.GET(":teamID/blackout_period", userHandler.AdministrationTeamBlackoutPeriodGetAll)
+					administrationTeam.POST(":teamID/blackout_period", userHandler.AdministrationTeamBlackoutPeriodCreate)
+					administrationTeam.DELETE(":teamID/blackout_period/:teamBlackoutPeriodID", userHandler.AdministrationTeamBlackoutPeriodDelete)
 				}
 				administrationUser := administration.Group("user")
 				{
@@ -257,6 +243,37 @@ func main() {
 					administrationUser.POST(":userID/overtime/action/calculate/:year/:month", overtimeHandler.OvertimeUserCalculateMonth)
 
 					administrationUser.GET(":userID/query/missing", timestampHandler.TimestampUserMissingEntries)
+
+					administrationUser.GET(":userID/work_time_model", workTimeModelHandler.AdministrationUserWorkTimeModelGetAll)
+					administrationUser.POST(":userID/work_time_model", workTimeModelHandler.AdministrationUserWorkTimeModelCreate)
+					administrationUser.DELETE(":userID/work_time_model/:userWorkTimeModelID", workTimeModelHandler.AdministrationUserWorkTimeModelDelete)
+					administrationUser.GET(":userID/location", locationHandler.AdministrationUserLocationGetAll)
+					administrationUser.POST(":userID/location", locationHandler.AdministrationUserLocationCreate)
+					administrationUser.DELETE(":userID/location/:userLocationID", locationHandler.AdministrationUserLocationDelete)
+					administrationUser.GET(":userID/vacation", vacationHandler.AdministrationUserVacationLedger)
+					administrationUser.GET(":userID/vacation/adjustment", vacationHandler.AdministrationUserVacationAdjustmentGetAll)
+					administrationUser.POST(":userID/vacation/adjustment", vacationHandler.AdministrationUserVacationAdjustmentCreate)
+					administrationUser.DELETE(":userID/vacation/adjustment/:vacationAdjustmentID", vacationHandler.AdministrationUserVacationAdjustmentDelete)
+				}
+				administrationWorkTimeModel := administration.Group("work_time_model")
+				{
+					administrationWorkTimeModel.GET("", workTimeModelHandler.AdministrationWorkTimeModelGetAll)
+					administrationWorkTimeModel.POST("", workTimeModelHandler.AdministrationWorkTimeModelCreate)
+					administrationWorkTimeModel.PUT(":workTimeModelID", workTimeModelHandler.AdministrationWorkTimeModelUpdate)
+					administrationWorkTimeModel.DELETE(":workTimeModelID", workTimeModelHandler.AdministrationWorkTimeModelDelete)
+				}
+				administrationLocation := administration.Group("location")
+				{
+					administrationLocation.GET("", locationHandler.AdministrationLocationGetAll)
+					administrationLocation.POST("", locationHandler.AdministrationLocationCreate)
+					administrationLocation.PUT(":locationID", locationHandler.AdministrationLocationUpdate)
+					administrationLocation.DELETE(":locationID", locationHandler.AdministrationLocationDelete)
+				}
+				administrationMonthClosing := administration.Group("month_closing")
+				{
+					administrationMonthClosing.GET("", monthClosingHandler.AdministrationMonthClosingGetAll)
+					administrationMonthClosing.POST("", monthClosingHandler.AdministrationMonthClosingCreate)
+					administrationMonthClosing.POST(":monthClosingID/reopen", monthClosingHandler.AdministrationMonthClosingReopen)
 				}
 				administrationAbsence := administration.Group("absence")
 				{
@@ -274,15 +291,27 @@ func main() {
 					administrationExternalWork.PUT("compensation/:externalWorkCompensationId", externalWorkHandler.AdministrationExternalWorkCompensationUpdate)
 				}
 
+				administrationOutbox := administration.Group("outbox")
+				{
+					administrationOutbox.GET("", outboxHandler.AdministrationOutboxJobGetAll)
+					administrationOutbox.POST(":outboxJobID/replay", outboxHandler.AdministrationOutboxJobReplay)
+				}
+
 				administrationMigrations := administration.Group("migration")
 				{
 					administrationMigrations.GET("", migrationHandler.AdministrationMigrationGetAll)
+					administrationMigrations.GET("status", migrationHandler.AdministrationMigrationGetStatus)
+					administrationMigrations.POST("run", migrationHandler.AdministrationMigrationRun)
 				}
 				administrationSettings := administration.Group("settings")
 				{
 					administrationSettings.GET("", administrationHandler.AdministrationGetSettings)
 					administrationSettings.PUT("", administrationHandler.AdministrationUpdateSettings)
 					administrationSettings.POST("logo", administrationHandler.AdministrationUploadLogo)
+					administrationSettings.GET("break_rule", administrationHandler.AdministrationGetBreakRules)
+					administrationSettings.POST("break_rule", administrationHandler.AdministrationCreateBreakRule)
+					administrationSettings.PUT("break_rule/:breakRuleID", administrationHandler.AdministrationUpdateBreakRule)
+					administrationSettings.DELETE("break_rule/:breakRuleID", administrationHandler.AdministrationDeleteBreakRule)
 				}
 				administrationNotify := administration.Group("notify")
 				{
@@ -291,9 +320,11 @@ func main() {
 
 				administrationHolidays := administration.Group("holidays")
 				{
+					administrationHolidays.GET("year/:year", administrationHandler.AdministrationGetHolidaysYear)
 					administrationHolidays.GET("custom", administrationHandler.AdministrationGetHolidaysCustom)
 					administrationHolidays.POST("custom", administrationHandler.AdministrationCreateHolidaysCustom)
 					administrationHolidays.DELETE("custom/:id", administrationHandler.AdministrationDeleteHolidaysCustom)
+					administrationHolidays.POST("sync", holidayHandler.AdministrationHolidaySync)
 				}
 			}
 
@@ -313,6 +344,8 @@ func main() {
 				timestamp.GET("query/timestamp/months", timestampHandler.TimestampQueryMonths)
 				timestamp.POST("action/checkin", timestampHandler.TimestampActionCheckIn)
 				timestamp.POST("action/checkout", timestampHandler.TimestampActionCheckOut)
+				timestamp.POST("action/pause/start", timestampHandler.TimestampActionPauseStart)
+				timestamp.POST("action/pause/end", timestampHandler.TimestampActionPauseEnd)
 				timestamp.POST(":timestampID/correction", timestampHandler.TimestampCorrectionCreate)
 				timestamp.POST(":timestampID/overtime", timestampHandler.TimestampOvertimeSet)
 				timestamp.POST("", timestampHandler.TimestampCreate)
@@ -338,6 +371,10 @@ func main() {
 				absence.GET("", absenceHandler.AbsenceGetAll)
 				absence.POST("", absenceHandler.AbsenceCreate)
 				absence.DELETE(":id", absenceHandler.AbsenceDelete)
+				absence.GET("approval/open", absenceHandler.AbsenceApprovalOpen)
+				absence.POST(":id/approval", absenceHandler.AbsenceApprovalSign)
+				absence.GET("change_request", absenceHandler.AbsenceChangeRequestGetAll)
+				absence.POST(":id/change_request", absenceHandler.AbsenceChangeRequestCreate)
 				absence.GET("query/me/summary", absenceHandler.AbsenceQueryCurrentUserSummary)
 				absence.GET("query/users/summary", absenceHandler.AbsenceQueryUsersSummary)
 				absence.GET("query/users/summary/current_year", absenceHandler.AbsenceQueryUsersSummaryCurrentYear)
@@ -372,16 +409,26 @@ func main() {
 
 				team.GET(":teamID/user/:userID/timestamp/months", timestampHandler.TeamUserTimestampQueryMonths)
 				team.DELETE(":teamID/user/:userID/timestamp/:timestampID", timestampHandler.TeamUserTimestampDelete)
+				team.GET(":teamID/timestamp/correction/open", timestampHandler.TeamTimestampCorrectionOpen)
+				team.POST(":teamID/timestamp/correction/:timestampCorrectionID/sign", timestampHandler.TeamTimestampCorrectionSign)
 				team.GET(":teamID/user/:userID/timestamp/year/:year/month/:month/grouped", timestampHandler.TimestampUserQueryMonthGrouped)
 				team.GET(":teamID/user/:userID/timestamp/year/:year/month/:month/overtime", timestampHandler.TimestampUserQueryMonthOvertime)
 
 				team.GET(":teamID/absence/query/users/summary", absenceHandler.AbsenceQueryTeamUsersSummary)
 				team.GET(":teamID/absence/open", absenceHandler.AbsenceTeamOpen)
 				team.POST(":teamID/absence/:absenceID/sign", absenceHandler.AbsenceSign)
+				team.GET(":teamID/absence/change_request/open", absenceHandler.AbsenceChangeRequestTeamOpen)
+				team.POST(":teamID/absence/change_request/:absenceChangeRequestID/sign", absenceHandler.AbsenceChangeRequestSign)
 
 				team.GET(":teamID/user/:userID/overtime", overtimeHandler.TeamUserOvertimeGetAll)
 				team.GET(":teamID/user/:userID/overtime/total", overtimeHandler.TeamUserOvertimeTotal)
 				team.POST(":teamID/user/:userID/overtime/action/calculate/:year/:month", overtimeHandler.TeamUserOvertimeCalculateMonth)
+
+				team.GET(":teamID/compliance/year/:year/month/:month", complianceHandler.TeamComplianceQueryMonth)
+
+				team.GET(":teamID/user/:userID/month_closing", monthClosingHandler.TeamUserMonthClosingGetAll)
+				team.POST(":teamID/user/:userID/month_closing/year/:year/month/:month", monthClosingHandler.TeamUserMonthClosingCreate)
+				team.POST(":teamID/user/:userID/month_closing/:monthClosingID/reopen", monthClosingHandler.TeamUserMonthClosingReopen)
 			}
 
 			user := v1.Group("user")
@@ -390,6 +437,12 @@ func main() {
 				user.PUT("me", userHandler.CurrentUserUpdate)
 				user.GET("me/apikey", userHandler.CurrentUserApikeyGet)
 				user.POST("me/apikey", userHandler.CurrentUserApikeyCreate)
+				user.GET("me/calendar_feed", calendarFeedHandler.CurrentUserCalendarFeedGetAll)
+				user.POST("me/calendar_feed", calendarFeedHandler.CurrentUserCalendarFeedCreate)
+				user.DELETE("me/calendar_feed/:calendarFeedID", calendarFeedHandler.CurrentUserCalendarFeedDelete)
+				user.GET("me/work_time_model", workTimeModelHandler.CurrentUserWorkTimeModelGetAll)
+				user.GET("me/location", locationHandler.CurrentUserLocationGetAll)
+				user.GET("me/vacation", vacationHandler.CurrentUserVacationLedger)
 			}
 
 			holiday := v1.Group("holidays")
@@ -399,334 +452,11 @@ func main() {
 		}
 	}
 
-	notify(env, absenceRepo)
+	notify(env, absenceRepo, outboxWorker)
 
 	r.Run()
 }
 
-func migrateAbsenceApproval(migrationRepo *repository.Migration, absenceRepo *repository.Absence) error {
-	_, err := migrationRepo.MigrationFindByTitle(MIGRATION_ABSENCE_APPROVAL)
-	migrationExists := true
-
-	if err != nil {
-		if err == repository.ErrMigrationNotFound {
-			migrationExists = false
-		} else {
-			return err
-		}
-	}
-
-	if migrationExists {
-		log.Println("Migration: MIGRATION_ABSENCE_APPROVAL already finished")
-		return nil
-	}
-
-	log.Println("Migration: MIGRATION_ABSENCE_APPROVAL started")
-	absences, err := absenceRepo.FindAll(true)
-	if err != nil {
-		panic(err)
-	}
-
-	for _, absence := range absences {
-		if absence.SignedUserID == nil && absence.AbsenceFrom.Before(time.Now()) {
-			absence.Sign(absence.User, model.SIGNED_STATUS_ACCEPTED, nil)
-			err = absenceRepo.Update(&absence)
-			if err != nil {
-				panic(err)
-			}
-		}
-	}
-
-	migration := model.Migration{
-		Title:      MIGRATION_ABSENCE_APPROVAL,
-		Result:     "absences migrated",
-		FinishedAt: time.Now(),
-		Success:    true,
-	}
-	migrationRepo.MigrationInsert(&migration)
-
-	log.Println("Migration: MIGRATION_ABSENCE_APPROVAL finished")
-	return nil
-}
-
-func migrateExternalCalendarMulti(migrationRepo *repository.Migration, absenceRepo *repository.Absence) error {
-	_, err := migrationRepo.MigrationFindByTitle(MIGRATION_EXTERNAL_CALENDAR_MULTI)
-	migrationExists := true
-
-	if err != nil {
-		if err == repository.ErrMigrationNotFound {
-			migrationExists = false
-		} else {
-			return err
-		}
-	}
-
-	if migrationExists {
-		log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR_MULTI already finished")
-		return nil
-	}
-
-	log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR_MULTI started")
-	absences, err := absenceRepo.FindAll(true)
-	if err != nil {
-		panic(err)
-	}
-
-	for _, absence := range absences {
-		if absence.ExternalEventID == "" {
-			continue
-		}
-
-		eventExists := false
-		for _, event := range absence.ExternalEvents {
-			if event.ExternalEventID == absence.ExternalEventID {
-				eventExists = true
-				break
-			}
-		}
-
-		if eventExists {
-			continue
-		}
-
-		absenceExternalEvent := model.AbsenceExternalEvent{
-			Absence:               absence,
-			ExternalEventProvider: absence.ExternalEventProvider,
-
-			ExternalEventID: absence.ExternalEventID,
-		}
-
-		err = absenceRepo.AbsenceExternalEventInsert(&absenceExternalEvent)
-		if err != nil {
-			migration := model.Migration{
-				Title:      MIGRATION_EXTERNAL_CALENDAR_MULTI,
-				Result:     err.Error(),
-				FinishedAt: time.Now(),
-				Success:    false,
-			}
-			migrationRepo.MigrationInsert(&migration)
-
-			return err
-		}
-
-		absence.ExternalEventID = "<migrated>"
-		absence.ExternalEventProvider = ""
-
-		err = absenceRepo.Update(&absence)
-		if err != nil {
-			migration := model.Migration{
-				Title:      MIGRATION_EXTERNAL_CALENDAR_MULTI,
-				Result:     err.Error(),
-				FinishedAt: time.Now(),
-				Success:    false,
-			}
-			migrationRepo.MigrationInsert(&migration)
-
-			return err
-		}
-	}
-
-	migration := model.Migration{
-		Title:      MIGRATION_EXTERNAL_CALENDAR_MULTI,
-		Result:     "events migrated",
-		FinishedAt: time.Now(),
-		Success:    true,
-	}
-	migrationRepo.MigrationInsert(&migration)
-
-	log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR_MULTI finished")
-	return nil
-}
-
-func migrateExternalCalendar(migrationRepo *repository.Migration, absenceRepo *repository.Absence) error {
-	if !microsoft.IsMicrosoftConnected() {
-		log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR skipped (no microsoft connection)")
-		return nil
-	}
-
-	_, err := migrationRepo.MigrationFindByTitle(MIGRATION_EXTERNAL_CALENDAR)
-	migrationExists := true
-
-	if err != nil {
-		if err == repository.ErrMigrationNotFound {
-			migrationExists = false
-		} else {
-			return err
-		}
-	}
-
-	if migrationExists {
-		log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR already finished")
-		return nil
-	}
-
-	log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR started")
-	absences, err := absenceRepo.FindAll(true)
-	if err != nil {
-		panic(err)
-	}
-
-	for _, absence := range absences {
-		if absence.AbsenceFrom.Year() < 2025 {
-			continue
-		}
-
-		if absence.ExternalEventID != "" {
-			continue
-		}
-
-		if absence.Identifier == uuid.Nil {
-			absence.Identifier = uuid.New()
-			absenceRepo.Update(&absence)
-		}
-
-		eventId, err := microsoft.CreateCalendarEntryFromAbsence(absence.User.Username, &absence)
-		if err != nil {
-			return err
-		}
-
-		absence.ExternalEventID = eventId
-		absence.ExternalEventProvider = model.EXTERNAL_EVENT_PROVIDER_MICROSOFT
-
-		err = absenceRepo.Update(&absence)
-		if err != nil {
-			migration := model.Migration{
-				Title:      MIGRATION_EXTERNAL_CALENDAR,
-				Result:     err.Error(),
-				FinishedAt: time.Now(),
-				Success:    false,
-			}
-			migrationRepo.MigrationInsert(&migration)
-
-			return err
-		}
-	}
-	migration := model.Migration{
-		Title:      MIGRATION_EXTERNAL_CALENDAR,
-		Result:     "events migrated",
-		FinishedAt: time.Now(),
-		Success:    true,
-	}
-	migrationRepo.MigrationInsert(&migration)
-
-	log.Println("Migration: MIGRATION_EXTERNAL_CALENDAR finished")
-
-	return nil
-}
-
-func importHolidays(holiday *repository.Holiday) {
-	importHolidaysCurrentYear(holiday)
-
-	for range time.Tick(time.Hour * 24) {
-		importHolidaysCurrentYear(holiday)
-	}
-}
-
-func importHolidaysCurrentYear(holiday *repository.Holiday) {
-	year := time.Now().Year()
-
-	log.Printf("Holiday Import: %d", year)
-	err := importHolidaysByYear(holiday, year)
-	if err != nil {
-		log.Println(err)
-	}
-}
-
-func importHolidaysByYear(holiday *repository.Holiday, year int) error {
-	holidays, err := holiday.HolidayFindByYear(year)
-	if err != nil {
-		panic(err)
-	}
-	if len(holidays) > 0 {
-		return nil
-	}
-
-	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://feiertage-api.de/api/?jahr=%d&nur_land=NI", year), nil)
-	if err != nil {
-		return err
-	}
-
-	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
-
-	client := &http.Client{}
-	response, err := client.Do(request)
-	if err != nil {
-		return err
-	}
-	defer response.Body.Close()
-
-	result := make(map[string]model.HolidayImport)
-
-	body, _ := io.ReadAll(response.Body)
-
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		return err
-	}
-
-	for name, info := range result {
-		date, err := info.GetDate()
-		if err != nil {
-			return err
-		}
-
-		exists, err := holiday.HolidayIsByDate(date)
-		if err != nil {
-			return err
-		}
-
-		if !exists {
-			err = holiday.HolidayInsert(&model.Holiday{
-				Name: name,
-				Date: date,
-			})
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	customHolidays, err := holiday.HolidayCustomFindAll()
-	if err != nil {
-		return err
-	}
-
-	for _, custom := range customHolidays {
-		var newDate *time.Time
-		if custom.Date != nil && custom.Date.Year() == year {
-			newDate = custom.Date
-		}
-
-		if custom.Day != nil && custom.Month != nil {
-			month := time.Month(*custom.Month)
-			generatedDate := time.Date(year, month, *custom.Day, 0, 0, 0, 0, time.Local)
-			newDate = &generatedDate
-		}
-
-		if newDate == nil {
-			continue
-		}
-
-		exists, err := holiday.HolidayIsByDate(*newDate)
-		if err != nil {
-			return err
-		}
-
-		if !exists {
-			err = holiday.HolidayInsert(&model.Holiday{
-				Name:                    custom.Name,
-				Date:                    *newDate,
-				EmployeeDaySubstraction: custom.EmployeeDaySubstraction,
-			})
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
 type uiWrapper struct {
 	FileSystem http.FileSystem
 }
@@ -748,7 +478,7 @@ func (w *uiWrapper) Open(name string) (http.File, error) {
 	return nil, err
 }
 
-func notify(env *core.Environment, absenceRepo *repository.Absence) {
+func notify(env *core.Environment, absenceRepo *repository.Absence, outbox *worker.Outbox) {
 	checkIntervalTicker := time.NewTicker(30 * time.Second)
 	send := false
 	go func() {
@@ -758,7 +488,10 @@ func notify(env *core.Environment, absenceRepo *repository.Absence) {
 				now := time.Now()
 				if now.Weekday() == time.Monday && now.Hour() == 8 && now.Minute() == 0 {
 					if !send {
-						worker.NotifyAbsenceWeek(env, absenceRepo)
+						err := worker.NotifyAbsenceWeek(env, absenceRepo, outbox)
+						if err != nil {
+							log.Printf("Notify: %s", err)
+						}
 					}
 					send = true
 				} else {
@@ -5,15 +5,58 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
 	graph "github.com/microsoftgraph/msgraph-sdk-go"
 	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
 )
 
+// MICROSOFT_ID_PROPERTY stores the model.CALENDAR_ENTRY_ID_PROPERTY as named
+// extended property in the public strings property set.
+const MICROSOFT_ID_PROPERTY = "String {00020329-0000-0000-C000-000000000046} Name " + model.CALENDAR_ENTRY_ID_PROPERTY
+
+// CalendarProvider manages the calendar entries in the Microsoft calendars of
+// the users through the Graph api.
+type CalendarProvider struct{}
+
+func NewCalendarProvider() *CalendarProvider {
+	return &CalendarProvider{}
+}
+
+func (p *CalendarProvider) Name() model.ExternalEventProvider {
+	return model.EXTERNAL_EVENT_PROVIDER_MICROSOFT
+}
+
+func (p *CalendarProvider) IsConnected() bool {
+	return IsMicrosoftConnected()
+}
+
+func (p *CalendarProvider) CreateEntry(username string, entry model.CalendarEntry) (string, error) {
+	return CreateCalendarEntry(username, entry)
+}
+
+func (p *CalendarProvider) GetEntry(username string, externalEventId string) (model.CalendarEntry, error) {
+	return GetCalendarEntry(username, externalEventId)
+}
+
+func (p *CalendarProvider) UpdateEntry(username string, externalEventId string, entry model.CalendarEntry) error {
+	return UpdateCalendarEntry(username, externalEventId, entry)
+}
+
+func (p *CalendarProvider) DeleteEntry(username string, externalEventId string) error {
+	return DeleteCalendarEntry(username, externalEventId)
+}
+
+func (p *CalendarProvider) FindEntries(username string, from time.Time, till time.Time) ([]model.CalendarEntry, error) {
+	return FindCalendarEntries(username, from, till)
+}
+
 func GetMicrosoftTenantId() string {
 	return os.Getenv("MICROSOFT_TENANT_ID")
 }
@@ -40,49 +83,65 @@ func getClient() (*graph.GraphServiceClient, error) {
 	return graph.NewGraphServiceClientWithCredentials(oboCredential, []string{"https://graph.microsoft.com/.default"})
 }
 
-func CreateCalendarEntryFromAbsence(username string, absence *model.Absence) (string, error) {
-	from := fmt.Sprintf("%sT00:00:00", absence.AbsenceFrom.Format(time.DateOnly))
-	till := fmt.Sprintf("%sT00:00:00", absence.AbsenceTill.Add(24*time.Hour).Format(time.DateOnly))
-	identifier := absence.Identifier.String()
+func getFreeBusyStatus(showAs model.CalendarShowAs) graphmodels.FreeBusyStatus {
+	switch showAs {
+	case model.CALENDAR_SHOW_AS_TENTATIVE:
+		return graphmodels.TENTATIVE_FREEBUSYSTATUS
+	case model.CALENDAR_SHOW_AS_BUSY:
+		return graphmodels.BUSY_FREEBUSYSTATUS
+	}
 
-	return CreateCalendarEntry(username, identifier, absence.AbsenceReason.Description, from, till, graphmodels.PRIVATE_SENSITIVITY)
+	return graphmodels.OOF_FREEBUSYSTATUS
 }
 
-func CreateCalendarEntryFromExternalWork(username string, externalWork *model.ExternalWork) (string, error) {
-	from := externalWork.From.Format("2006-01-02T00:00:00")
-	till := fmt.Sprintf("%sT00:00:00", externalWork.Till.Add(24*time.Hour).Format(time.DateOnly))
-	identifier := externalWork.Identifier.String()
+func getShowAs(status graphmodels.FreeBusyStatus) model.CalendarShowAs {
+	switch status {
+	case graphmodels.TENTATIVE_FREEBUSYSTATUS:
+		return model.CALENDAR_SHOW_AS_TENTATIVE
+	case graphmodels.OOF_FREEBUSYSTATUS:
+		return model.CALENDAR_SHOW_AS_OUT_OF_OFFICE
+	}
 
-	return CreateCalendarEntry(username, identifier, externalWork.Description, from, till, graphmodels.PRIVATE_SENSITIVITY)
+	return model.CALENDAR_SHOW_AS_BUSY
 }
 
-func CreateCalendarEntry(username string, identifier string, description string, fromIso string, tillIso string, sensitivity graphmodels.Sensitivity) (string, error) {
-	subject := fmt.Sprintf("BTC: %s", description)
+func getEventFromCalendarEntry(entry model.CalendarEntry) graphmodels.Eventable {
 	requestBody := graphmodels.NewEvent()
-	requestBody.SetSubject(&subject)
+	requestBody.SetSubject(&entry.Subject)
 
+	timeZone := model.CALENDAR_ENTRY_DEFAULT_TIME_ZONE
 	start := graphmodels.NewDateTimeTimeZone()
-	start.SetDateTime(&fromIso)
-	timeZone := "Europe/Berlin"
+	start.SetDateTime(&entry.From)
 	start.SetTimeZone(&timeZone)
 	requestBody.SetStart(start)
 
 	end := graphmodels.NewDateTimeTimeZone()
-	end.SetDateTime(&tillIso)
+	end.SetDateTime(&entry.Till)
 	end.SetTimeZone(&timeZone)
 	requestBody.SetEnd(end)
 
-	requestBody.SetTransactionId(&identifier)
-
-	isAllDay := true
-	requestBody.SetIsAllDay(&isAllDay)
+	requestBody.SetIsAllDay(&entry.IsAllDay)
 
-	showAs := graphmodels.OOF_FREEBUSYSTATUS
+	showAs := getFreeBusyStatus(entry.ShowAs)
 	requestBody.SetShowAs(&showAs)
 
+	idProperty := graphmodels.NewSingleValueLegacyExtendedProperty()
+	propertyId := MICROSOFT_ID_PROPERTY
+	idProperty.SetId(&propertyId)
+	idProperty.SetValue(&entry.Identifier)
+	requestBody.SetSingleValueExtendedProperties([]graphmodels.SingleValueLegacyExtendedPropertyable{idProperty})
+
+	return requestBody
+}
+
+func CreateCalendarEntry(username string, entry model.CalendarEntry) (string, error) {
+	requestBody := getEventFromCalendarEntry(entry)
+	requestBody.SetTransactionId(&entry.Identifier)
+
 	reminder := false
 	requestBody.SetIsReminderOn(&reminder)
 
+	sensitivity := graphmodels.PRIVATE_SENSITIVITY
 	requestBody.SetSensitivity(&sensitivity)
 
 	graphClient, err := getClient()
@@ -104,6 +163,29 @@ func CreateCalendarEntry(username string, identifier string, description string,
 	return *result.GetId(), nil
 }
 
+// UpdateCalendarEntry returns model.ErrCalendarEntryNotFound if the event was
+// removed from the calendar.
+func UpdateCalendarEntry(username string, externalEventId string, entry model.CalendarEntry) error {
+	requestBody := getEventFromCalendarEntry(entry)
+
+	graphClient, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = graphClient.Users().ByUserId(username).Events().ByEventId(externalEventId).Patch(context.Background(), requestBody, nil)
+	if err != nil {
+		if odataErr, ok := err.(*odataerrors.ODataError); ok {
+			if *odataErr.GetErrorEscaped().GetCode() == "ErrorItemNotFound" {
+				return model.ErrCalendarEntryNotFound
+			}
+			return fmt.Errorf("error updating event: %v", odataErr.GetErrorEscaped().GetMessage())
+		}
+		return fmt.Errorf("error updating event: %v", err)
+	}
+	return nil
+}
+
 func DeleteCalendarEntry(username string, externalEventId string) error {
 	graphClient, err := getClient()
 	if err != nil {
@@ -122,3 +204,125 @@ func DeleteCalendarEntry(username string, externalEventId string) error {
 	}
 	return nil
 }
+
+func getCalendarEntryFromEvent(event graphmodels.Eventable) model.CalendarEntry {
+	entry := model.CalendarEntry{}
+
+	if event.GetId() != nil {
+		entry.ID = *event.GetId()
+	}
+	if event.GetSubject() != nil {
+		entry.Subject = *event.GetSubject()
+	}
+	if event.GetStart() != nil && event.GetStart().GetDateTime() != nil {
+		entry.From = *event.GetStart().GetDateTime()
+	}
+	if event.GetEnd() != nil && event.GetEnd().GetDateTime() != nil {
+		entry.Till = *event.GetEnd().GetDateTime()
+	}
+	if event.GetIsAllDay() != nil {
+		entry.IsAllDay = *event.GetIsAllDay()
+	}
+	if event.GetTransactionId() != nil {
+		entry.Identifier = *event.GetTransactionId()
+	}
+	if event.GetShowAs() != nil {
+		entry.ShowAs = getShowAs(*event.GetShowAs())
+	}
+	if event.GetLastModifiedDateTime() != nil {
+		entry.Modified = *event.GetLastModifiedDateTime()
+	}
+	for _, property := range event.GetSingleValueExtendedProperties() {
+		if property.GetId() != nil && property.GetValue() != nil && strings.EqualFold(*property.GetId(), MICROSOFT_ID_PROPERTY) {
+			entry.BeeTimeClockID = *property.GetValue()
+		}
+	}
+
+	return entry
+}
+
+func getTimeZoneHeaders() *abstractions.RequestHeaders {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", fmt.Sprintf("outlook.timezone=\"%s\"", model.CALENDAR_ENTRY_DEFAULT_TIME_ZONE))
+
+	return headers
+}
+
+// GetCalendarEntry returns the event with the date times in the calendar time
+// zone, model.ErrCalendarEntryNotFound if the event was removed from the calendar.
+func GetCalendarEntry(username string, externalEventId string) (model.CalendarEntry, error) {
+	graphClient, err := getClient()
+	if err != nil {
+		return model.CalendarEntry{}, err
+	}
+
+	event, err := graphClient.Users().ByUserId(username).Events().ByEventId(externalEventId).Get(context.Background(), &graphusers.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		Headers: getTimeZoneHeaders(),
+	})
+	if err != nil {
+		if odataErr, ok := err.(*odataerrors.ODataError); ok {
+			if *odataErr.GetErrorEscaped().GetCode() == "ErrorItemNotFound" {
+				return model.CalendarEntry{}, model.ErrCalendarEntryNotFound
+			}
+			return model.CalendarEntry{}, fmt.Errorf("error getting event: %v", odataErr.GetErrorEscaped().GetMessage())
+		}
+		return model.CalendarEntry{}, fmt.Errorf("error getting event: %v", err)
+	}
+
+	if event.GetIsCancelled() != nil && *event.GetIsCancelled() {
+		return model.CalendarEntry{}, model.ErrCalendarEntryNotFound
+	}
+
+	return getCalendarEntryFromEvent(event), nil
+}
+
+// FindCalendarEntries returns all events created by BeeTimeClock in the
+// calendar of the user between from and till.
+func FindCalendarEntries(username string, from time.Time, till time.Time) ([]model.CalendarEntry, error) {
+	entries := []model.CalendarEntry{}
+
+	graphClient, err := getClient()
+	if err != nil {
+		return entries, err
+	}
+
+	startDateTime := from.Format(time.RFC3339)
+	endDateTime := till.Format(time.RFC3339)
+	top := int32(100)
+
+	requestBuilder := graphClient.Users().ByUserId(username).CalendarView()
+	requestConfiguration := &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+		Headers: getTimeZoneHeaders(),
+		QueryParameters: &graphusers.ItemCalendarViewRequestBuilderGetQueryParameters{
+			StartDateTime: &startDateTime,
+			EndDateTime:   &endDateTime,
+			Select:        []string{"id", "subject", "start", "end", "isAllDay", "showAs", "transactionId", "lastModifiedDateTime"},
+			Expand:        []string{fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", MICROSOFT_ID_PROPERTY)},
+			Top:           &top,
+		},
+	}
+
+	for {
+		result, err := requestBuilder.Get(context.Background(), requestConfiguration)
+		if err != nil {
+			if odataErr, ok := err.(*odataerrors.ODataError); ok {
+				return entries, fmt.Errorf("error getting events: %v", odataErr.GetErrorEscaped().GetMessage())
+			}
+			return entries, fmt.Errorf("error getting events: %v", err)
+		}
+
+		for _, event := range result.GetValue() {
+			entry := getCalendarEntryFromEvent(event)
+			if entry.IsCreatedByBeeTimeClock() {
+				entries = append(entries, entry)
+			}
+		}
+
+		if result.GetOdataNextLink() == nil {
+			return entries, nil
+		}
+
+		requestBuilder = requestBuilder.WithUrl(*result.GetOdataNextLink())
+		requestConfiguration.QueryParameters = nil
+	}
+}
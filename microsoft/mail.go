@@ -0,0 +1,48 @@
+package microsoft
+
+import (
+	"context"
+	"fmt"
+
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// SendMail sends a plain text mail from the mailbox of the user to the user,
+// the app needs the Mail.Send permission.
+func SendMail(username string, subject string, text string) error {
+	body := graphmodels.NewItemBody()
+	contentType := graphmodels.TEXT_BODYTYPE
+	body.SetContentType(&contentType)
+	body.SetContent(&text)
+
+	emailAddress := graphmodels.NewEmailAddress()
+	emailAddress.SetAddress(&username)
+	recipient := graphmodels.NewRecipient()
+	recipient.SetEmailAddress(emailAddress)
+
+	message := graphmodels.NewMessage()
+	message.SetSubject(&subject)
+	message.SetBody(body)
+	message.SetToRecipients([]graphmodels.Recipientable{recipient})
+
+	requestBody := graphusers.NewItemSendMailPostRequestBody()
+	requestBody.SetMessage(message)
+	saveToSentItems := false
+	requestBody.SetSaveToSentItems(&saveToSentItems)
+
+	graphClient, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	err = graphClient.Users().ByUserId(username).SendMail().Post(context.Background(), requestBody, nil)
+	if err != nil {
+		if odataErr, ok := err.(*odataerrors.ODataError); ok {
+			return fmt.Errorf("error sending mail: %v", odataErr.GetErrorEscaped().GetMessage())
+		}
+		return fmt.Errorf("error sending mail: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm/clause"
+)
+
+const MIGRATION_ABSENCE_APPROVAL = "ABSENCE_APPROVAL"
+
+// AbsenceApproval accepts all past absences created before absences had to be
+// approved.
+func AbsenceApproval() Migration {
+	return Migration{
+		Version:     4,
+		Title:       MIGRATION_ABSENCE_APPROVAL,
+		Description: "accept past absences without approval",
+		Run: func(ctx *Context) (string, error) {
+			var absences []model.Absence
+			err := ctx.Tx.Preload("User").Find(&absences, "signed_user_id is null and absence_from < ?", time.Now()).Error
+			if err != nil {
+				return "", err
+			}
+
+			for _, absence := range absences {
+				absence.Sign(absence.User, model.SIGNED_STATUS_ACCEPTED, nil)
+				absence.SignedUserID = absence.UserID
+
+				err = ctx.Tx.Omit(clause.Associations).Updates(&absence).Error
+				if err != nil {
+					return "", err
+				}
+			}
+
+			return fmt.Sprintf("%d absences migrated", len(absences)), nil
+		},
+	}
+}
@@ -1,80 +1,49 @@
 package migrations
 
 import (
-	"log"
-	"time"
+	"fmt"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm/clause"
 )
 
 const MIGRATION_ABSENCE_NETTO_DAYS = "ABSENCE_NETTO_DAYS"
 
-func MigrateAbsenceNettoDays(migrationRepo *repository.Migration, absenceRepo *repository.Absence, holidayRepo *repository.Holiday) error {
-	_, err := migrationRepo.MigrationFindByTitle(MIGRATION_ABSENCE_NETTO_DAYS)
-	migrationExists := true
-
-	if err != nil {
-		if err == repository.ErrMigrationNotFound {
-			migrationExists = false
-		} else {
-			return err
-		}
-	}
-
-	if migrationExists {
-		log.Printf("Migration: %s already finished", MIGRATION_ABSENCE_NETTO_DAYS)
-		return nil
-	}
-
-	log.Printf("Migration: %s started", MIGRATION_ABSENCE_NETTO_DAYS)
-	absences, err := absenceRepo.FindAll(true)
-	if err != nil {
-		panic(err)
-	}
-
-	for _, absence := range absences {
-		if absence.NettoDays != nil && *absence.NettoDays > 0 {
-			continue
-		}
-
-		holidays, err := holidayRepo.HolidayFindByDateRange(absence.AbsenceFrom, absence.AbsenceTill)
-		if err != nil {
-			migration := model.Migration{
-				Title:      MIGRATION_ABSENCE_NETTO_DAYS,
-				Result:     err.Error(),
-				FinishedAt: time.Now(),
-				Success:    false,
+// AbsenceNettoDays calculates the netto days of absences created before they
+// were stored.
+func AbsenceNettoDays(holidayRepo repository.HolidayRepository, workTimeModelRepo repository.WorkTimeModelRepository) Migration {
+	return Migration{
+		Version:     5,
+		Title:       MIGRATION_ABSENCE_NETTO_DAYS,
+		Description: "calculate the netto days of the absences",
+		Run: func(ctx *Context) (string, error) {
+			var absences []model.Absence
+			err := ctx.Tx.Find(&absences, "netto_days is null or netto_days <= 0").Error
+			if err != nil {
+				return "", err
 			}
-			migrationRepo.MigrationInsert(&migration)
 
-			return err
-		}
+			for _, absence := range absences {
+				holidays, err := holidayRepo.HolidayFindByUserIdAndDateRange(*absence.UserID, absence.AbsenceFrom, absence.AbsenceTill)
+				if err != nil {
+					return "", err
+				}
 
-		absence.CalculateNettoDays(holidays)
+				workTimeModels, err := workTimeModelRepo.UserWorkTimeModelFindByUserId(*absence.UserID)
+				if err != nil {
+					return "", err
+				}
 
-		err = absenceRepo.Update(&absence)
-		if err != nil {
-			migration := model.Migration{
-				Title:      MIGRATION_ABSENCE_NETTO_DAYS,
-				Result:     err.Error(),
-				FinishedAt: time.Now(),
-				Success:    false,
-			}
-			migrationRepo.MigrationInsert(&migration)
+				absence.CalculateNettoDays(holidays, workTimeModels)
 
-			return err
-		}
-	}
+				err = ctx.Tx.Model(&absence).Omit(clause.Associations).Update("netto_days", absence.NettoDays).Error
+				if err != nil {
+					return "", err
+				}
+			}
 
-	migration := model.Migration{
-		Title:      MIGRATION_ABSENCE_NETTO_DAYS,
-		Result:     "absence netto days migrated",
-		FinishedAt: time.Now(),
-		Success:    true,
+			return fmt.Sprintf("%d absences migrated", len(absences)), nil
+		},
 	}
-	migrationRepo.MigrationInsert(&migration)
-
-	log.Printf("Migration: %s finished", MIGRATION_ABSENCE_NETTO_DAYS)
-	return nil
 }
@@ -0,0 +1,227 @@
+//go:build cgo
+
+package migrations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+// newTestEnvironment uses a new sqlite database. With DB_TYPE=mysql or
+// DB_TYPE=psql and the connection variables set that database is used
+// instead, see the README.
+func newTestEnvironment(t *testing.T) *core.Environment {
+	if os.Getenv("DB_TYPE") == "" {
+		t.Setenv("DB_TYPE", database.DB_TYPE_SQLITE)
+		t.Setenv("DATABASE", filepath.Join(t.TempDir(), "beetimeclock.db"))
+	}
+
+	env := core.NewEnvironment()
+	env.DatabaseManager = database.NewDatabaseManager("beetc")
+	return env
+}
+
+// TestDatabaseMigrate creates the schema and runs all migrations.
+func TestDatabaseMigrate(t *testing.T) {
+	env := newTestEnvironment(t)
+
+	holidayRepo := repository.NewHoliday(env)
+	workTimeModelRepo := repository.NewWorkTimeModel(env)
+	migrationRepo := repository.NewMigration(env)
+
+	testData := []struct {
+		Name    string
+		Migrate func() error
+	}{
+		{Name: "user", Migrate: repository.NewUser(env).Migrate},
+		{Name: "team", Migrate: repository.NewTeam(env).Migrate},
+		{Name: "timestamp", Migrate: repository.NewTimestamp(env).Migrate},
+		{Name: "fuel", Migrate: repository.NewFuel(env).Migrate},
+		{Name: "absence", Migrate: repository.NewAbsence(env).Migrate},
+		{Name: "migration", Migrate: migrationRepo.Migrate},
+		{Name: "settings", Migrate: repository.NewSettings(env).Migrate},
+		{Name: "external work", Migrate: repository.NewExternalWork(env).Migrate},
+		{Name: "overtime", Migrate: repository.NewOvertime(env).Migrate},
+		{Name: "location", Migrate: repository.NewLocation(env).Migrate},
+		{Name: "holiday", Migrate: holidayRepo.Migrate},
+		{Name: "work time model", Migrate: workTimeModelRepo.Migrate},
+		{Name: "month closing", Migrate: repository.NewMonthClosing(env).Migrate},
+		{Name: "vacation", Migrate: repository.NewVacation(env).Migrate},
+		{Name: "outbox", Migrate: repository.NewOutbox(env).Migrate},
+	}
+
+	// the second run has to accept the existing schema
+	for run := 1; run <= 2; run++ {
+		for _, test := range testData {
+			err := test.Migrate()
+			if err != nil {
+				t.Fatalf("%s (run %d): want no error, got %s", test.Name, run, err)
+			}
+		}
+	}
+
+	// holidays without location have to be unique too
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := holidayRepo.HolidayInsert(&model.Holiday{Name: "Neujahr", Date: date})
+	if err != nil {
+		t.Fatalf("holiday: want no error, got %s", err)
+	}
+	err = holidayRepo.HolidayInsert(&model.Holiday{Name: "Neujahr", Date: date})
+	if err == nil && os.Getenv("DB_TYPE") != database.DB_TYPE_MYSQL && os.Getenv("DB_TYPE") != "mariadb" {
+		t.Errorf("holiday: want duplicate without location rejected")
+	}
+
+	registry := NewRegistry(env, migrationRepo)
+	err = registry.Register(GetMigrations(holidayRepo, workTimeModelRepo)...)
+	if err != nil {
+		t.Fatalf("register: want no error, got %s", err)
+	}
+
+	_, err = registry.RunPending(false)
+	if err != nil {
+		t.Fatalf("run: want no error, got %s", err)
+	}
+
+	status, err := registry.GetStatus()
+	if err != nil {
+		t.Fatalf("status: want no error, got %s", err)
+	}
+
+	for _, migration := range status {
+		enabled := true
+		for _, registered := range registry.migrations {
+			if registered.Title == migration.Title && registered.Enabled != nil {
+				enabled = registered.Enabled()
+			}
+		}
+
+		if migration.Applied != enabled {
+			t.Errorf("%s: want applied %t, got %+v", migration.Title, enabled, migration)
+		}
+	}
+}
+
+// newTestRegistry registers migrations which each insert an outbox job, the
+// failing one returns an error after the insert.
+func newTestRegistry(t *testing.T, env *core.Environment, failing int) (*Registry, repository.OutboxRepository) {
+	migrationRepo := repository.NewMigration(env)
+	outboxRepo := repository.NewOutbox(env)
+	for _, migrate := range []func() error{migrationRepo.Migrate, outboxRepo.Migrate} {
+		err := migrate()
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	registry := NewRegistry(env, migrationRepo)
+	for _, title := range []string{"FIRST", "SECOND", "THIRD"} {
+		version := len(registry.migrations) + 1
+		err := registry.Register(Migration{
+			Version: version,
+			Title:   title,
+			Run: func(ctx *Context) (string, error) {
+				job, err := model.NewOutboxJob(model.OUTBOX_JOB_TYPE_WEBHOOK, title)
+				if err == nil {
+					err = ctx.Tx.Create(&job).Error
+				}
+				if err == nil && version == failing {
+					err = errors.New("failed")
+				}
+				return title, err
+			},
+		})
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	return registry, outboxRepo
+}
+
+func TestRegistryRunPending(t *testing.T) {
+	env := newTestEnvironment(t)
+	registry, outboxRepo := newTestRegistry(t, env, 2)
+
+	results, err := registry.RunPending(false)
+	if err == nil {
+		t.Errorf("want error of the failing migration")
+	}
+	if len(results) != 2 || !results[0].Success || results[1].Success {
+		t.Fatalf("want the first run and the second failed, got %+v", results)
+	}
+
+	jobs, err := outboxRepo.OutboxJobFindByStatus(model.OUTBOX_JOB_STATUS_PENDING)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if len(jobs) != 1 {
+		t.Errorf("want the job of the failed migration rolled back, got %d jobs", len(jobs))
+	}
+
+	status, err := registry.GetStatus()
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name      string
+		Applied   bool
+		LastError string
+	}{
+		{Name: "FIRST", Applied: true},
+		{Name: "SECOND", LastError: "failed"},
+		{Name: "THIRD"},
+	}
+
+	for i, test := range testData {
+		got := status[i]
+		if got.Title != test.Name || got.Applied != test.Applied || got.LastError != test.LastError {
+			t.Errorf("%s: want %+v, got %+v", test.Name, test, got)
+		}
+	}
+}
+
+func TestRegistryRunPendingDryRun(t *testing.T) {
+	env := newTestEnvironment(t)
+	registry, outboxRepo := newTestRegistry(t, env, 0)
+
+	results, err := registry.RunPending(true)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if !result.Success || !result.DryRun || result.Result != result.Title {
+			t.Errorf("%s: want a successful dry run, got %+v", result.Title, result)
+		}
+	}
+
+	jobs, err := outboxRepo.OutboxJobFindByStatus(model.OUTBOX_JOB_STATUS_PENDING)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("want all changes rolled back, got %d jobs", len(jobs))
+	}
+
+	status, err := registry.GetStatus()
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	for _, migration := range status {
+		if migration.Applied || migration.LastError != "" {
+			t.Errorf("%s: want nothing recorded, got %+v", migration.Title, migration)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+const MIGRATION_EXTERNAL_CALENDAR = "EXTERNAL_CALENDAR"
+
+// ExternalCalendar enqueues the calendar sync of the absences from 2025 on, the
+// outbox creates the entries after the commit. It stays pending until
+// microsoft is connected.
+func ExternalCalendar() Migration {
+	return Migration{
+		Version:     2,
+		Revision:    1,
+		Title:       MIGRATION_EXTERNAL_CALENDAR,
+		Description: "create microsoft calendar entries for absences from 2025 on",
+		Enabled:     microsoft.IsMicrosoftConnected,
+		Run: func(ctx *Context) (string, error) {
+			var absences []model.Absence
+			err := ctx.Tx.Find(&absences).Error
+			if err != nil {
+				return "", err
+			}
+
+			count := 0
+			for _, absence := range absences {
+				if absence.AbsenceFrom.Year() < 2025 || absence.ExternalEventID != "" {
+					continue
+				}
+
+				if absence.Identifier == uuid.Nil {
+					absence.Identifier = uuid.New()
+
+					err = ctx.Tx.Omit(clause.Associations).Select("identifier").Updates(&absence).Error
+					if err != nil {
+						return "", err
+					}
+				}
+
+				job, err := model.NewOutboxJob(model.OUTBOX_JOB_TYPE_CALENDAR_ABSENCE, model.OutboxCalendarAbsencePayload{
+					AbsenceID: absence.ID,
+				})
+				if err != nil {
+					return "", err
+				}
+
+				err = ctx.Tx.Create(&job).Error
+				if err != nil {
+					return "", err
+				}
+				count++
+			}
+
+			return fmt.Sprintf("%d events enqueued", count), nil
+		},
+	}
+}
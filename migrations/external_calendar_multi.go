@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm/clause"
+)
+
+const MIGRATION_EXTERNAL_CALENDAR_MULTI = "EXTERNAL_CALENDAR_MULTI"
+
+// ExternalCalendarMulti moves the single external event of an absence to the
+// external events, so an absence can be synced to several calendars.
+func ExternalCalendarMulti() Migration {
+	return Migration{
+		Version:     3,
+		Title:       MIGRATION_EXTERNAL_CALENDAR_MULTI,
+		Description: "move the external event of the absences to the external events",
+		Run: func(ctx *Context) (string, error) {
+			var absences []model.Absence
+			err := ctx.Tx.Preload("ExternalEvents").Find(&absences, "external_event_id <> ''").Error
+			if err != nil {
+				return "", err
+			}
+
+			count := 0
+			for _, absence := range absences {
+				eventExists := false
+				for _, event := range absence.ExternalEvents {
+					if event.ExternalEventID == absence.ExternalEventID {
+						eventExists = true
+						break
+					}
+				}
+
+				if eventExists {
+					continue
+				}
+
+				absenceExternalEvent := model.AbsenceExternalEvent{
+					AbsenceID:             absence.ID,
+					ExternalEventProvider: absence.ExternalEventProvider,
+					ExternalEventID:       absence.ExternalEventID,
+				}
+
+				err = ctx.Tx.Omit(clause.Associations).Create(&absenceExternalEvent).Error
+				if err != nil {
+					return "", err
+				}
+
+				absence.ExternalEventID = "<migrated>"
+				absence.ExternalEventProvider = ""
+
+				err = ctx.Tx.Omit(clause.Associations).Select("external_event_id", "external_event_provider").Updates(&absence).Error
+				if err != nil {
+					return "", err
+				}
+				count++
+			}
+
+			return fmt.Sprintf("%d events migrated", count), nil
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
+)
+
+const MIGRATION_HOMEOFFICE_GOING = "HOMEOFFICE_GOING"
+
+func HomeofficeGoing() Migration {
+	return Migration{
+		Version:     1,
+		Title:       MIGRATION_HOMEOFFICE_GOING,
+		Description: "copy the homeoffice flag of the timestamps to the going homeoffice flag",
+		Run: func(ctx *Context) (string, error) {
+			result := ctx.Tx.Model(&model.Timestamp{}).
+				Where("is_homeoffice_going <> is_homeoffice").
+				Update("is_homeoffice_going", gorm.Expr("is_homeoffice"))
+			if result.Error != nil {
+				return "", result.Error
+			}
+
+			return fmt.Sprintf("%d timestamps were migrated", result.RowsAffected), nil
+		},
+	}
+}
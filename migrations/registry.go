@@ -0,0 +1,229 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm"
+)
+
+// Context is handed to a migration. All changes have to be written with Tx,
+// side effects outside of the database have to be enqueued in the outbox with
+// Tx, so they only happen after the commit and never on a dry run.
+type Context struct {
+	Tx     *gorm.DB
+	DryRun bool
+}
+
+// Migration is a one-off data migration. The version defines the order,
+// applied migrations are identified by their title. A migration with Enabled
+// returning false is skipped and stays pending. Revision has to be increased
+// whenever Run of a released migration is changed.
+type Migration struct {
+	Version     int
+	Revision    int
+	Title       string
+	Description string
+	Enabled     func() bool
+	Run         func(ctx *Context) (string, error)
+}
+
+// Checksum identifies the revision of the migration, a changed checksum of an
+// applied migration is reported in the status.
+func (m *Migration) Checksum() string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%d", m.Version, m.Title, m.Revision)))
+	return hex.EncodeToString(hash[:])
+}
+
+var errDryRun = errors.New("dry run")
+
+type Registry struct {
+	env        *core.Environment
+	migration  repository.MigrationRepository
+	migrations []Migration
+	running    sync.Mutex
+}
+
+func NewRegistry(env *core.Environment, migration repository.MigrationRepository) *Registry {
+	return &Registry{
+		env:       env,
+		migration: migration,
+	}
+}
+
+// Register adds the migrations, versions and titles have to be unique.
+func (r *Registry) Register(migrations ...Migration) error {
+	for _, migration := range migrations {
+		if slices.ContainsFunc(r.migrations, func(existing Migration) bool {
+			return existing.Version == migration.Version || existing.Title == migration.Title
+		}) {
+			return fmt.Errorf("migration %d %s is already registered", migration.Version, migration.Title)
+		}
+
+		r.migrations = append(r.migrations, migration)
+	}
+
+	slices.SortFunc(r.migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+
+	return nil
+}
+
+func (r *Registry) GetStatus() ([]model.MigrationStatus, error) {
+	records, err := r.migration.MigrationFindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getStatus(records), nil
+}
+
+func (r *Registry) getStatus(records []model.Migration) []model.MigrationStatus {
+	status := []model.MigrationStatus{}
+
+	for _, migration := range r.migrations {
+		current := model.MigrationStatus{
+			Version:     migration.Version,
+			Title:       migration.Title,
+			Description: migration.Description,
+			Checksum:    migration.Checksum(),
+		}
+
+		for _, record := range records {
+			if record.Title != migration.Title {
+				continue
+			}
+
+			if !record.Success {
+				current.LastError = record.Result
+				continue
+			}
+
+			finishedAt := record.FinishedAt
+			current.Applied = true
+			current.AppliedAt = &finishedAt
+			current.LastError = ""
+			// runs recorded before the registry have no checksum
+			current.ChecksumMismatch = record.Checksum != "" && record.Checksum != current.Checksum
+		}
+
+		status = append(status, current)
+	}
+
+	return status
+}
+
+// RunPending runs all migrations which are not applied yet in order and stops
+// at the first failing one. On a dry run every migration is rolled back and
+// nothing is recorded.
+func (r *Registry) RunPending(dryRun bool) ([]model.Migration, error) {
+	r.running.Lock()
+	defer r.running.Unlock()
+
+	results := []model.Migration{}
+
+	status, err := r.GetStatus()
+	if err != nil {
+		return results, err
+	}
+
+	for i, migration := range r.migrations {
+		if status[i].Applied {
+			continue
+		}
+
+		if migration.Enabled != nil && !migration.Enabled() {
+			log.Printf("Migration: %s skipped", migration.Title)
+			continue
+		}
+
+		result, err := r.run(migration, dryRun)
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("migration %s: %w", migration.Title, err)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Registry) run(migration Migration, dryRun bool) (model.Migration, error) {
+	record := model.Migration{
+		Version:   migration.Version,
+		Title:     migration.Title,
+		Checksum:  migration.Checksum(),
+		StartedAt: time.Now(),
+		DryRun:    dryRun,
+	}
+
+	log.Printf("Migration: %s started (dry run: %t)", migration.Title, dryRun)
+
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return record, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result, err := migration.Run(&Context{Tx: tx, DryRun: dryRun})
+		if err != nil {
+			return err
+		}
+
+		record.Result = result
+		record.Success = true
+		record.FinishedAt = time.Now()
+		record.DurationMs = record.FinishedAt.Sub(record.StartedAt).Milliseconds()
+		if dryRun {
+			return errDryRun
+		}
+
+		return tx.Create(&record).Error
+	})
+
+	if dryRun && errors.Is(err, errDryRun) {
+		log.Printf("Migration: %s dry run finished: %s", migration.Title, record.Result)
+		return record, nil
+	}
+
+	if err != nil {
+		record.Result = err.Error()
+		record.Success = false
+		record.FinishedAt = time.Now()
+		record.DurationMs = record.FinishedAt.Sub(record.StartedAt).Milliseconds()
+
+		if !dryRun {
+			insertErr := r.migration.MigrationInsert(&record)
+			if insertErr != nil {
+				log.Printf("Migration: %s failed to record error: %s", migration.Title, insertErr)
+			}
+		}
+
+		log.Printf("Migration: %s failed: %s", migration.Title, err)
+		return record, err
+	}
+
+	log.Printf("Migration: %s finished: %s", migration.Title, record.Result)
+	return record, nil
+}
+
+// GetMigrations returns all migrations of the application.
+func GetMigrations(holidayRepo repository.HolidayRepository, workTimeModelRepo repository.WorkTimeModelRepository) []Migration {
+	return []Migration{
+		HomeofficeGoing(),
+		ExternalCalendar(),
+		ExternalCalendarMulti(),
+		AbsenceApproval(),
+		AbsenceNettoDays(holidayRepo, workTimeModelRepo),
+	}
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+func TestRegistryStatus(t *testing.T) {
+	registry := NewRegistry(nil, nil)
+	first := Migration{Version: 1, Title: "FIRST", Description: "first"}
+	second := Migration{Version: 2, Title: "SECOND", Description: "second"}
+	third := Migration{Version: 3, Title: "THIRD", Description: "third"}
+
+	err := registry.Register(third, first, second)
+	if err != nil {
+		t.Fatalf("register: want no error, got %s", err)
+	}
+
+	err = registry.Register(Migration{Version: 4, Title: "FIRST"})
+	if err == nil {
+		t.Errorf("register: want error for duplicate title")
+	}
+
+	changed := first
+	changed.Revision = 1
+	records := []model.Migration{
+		{Title: "FIRST", Checksum: changed.Checksum(), Success: true, FinishedAt: time.Now()},
+		{Title: "SECOND", Result: "failed", Success: false},
+		{Title: "THIRD", Success: true, FinishedAt: time.Now()},
+	}
+
+	status := registry.getStatus(records)
+	testData := []struct {
+		Name             string
+		Applied          bool
+		ChecksumMismatch bool
+		LastError        string
+	}{
+		{Name: "FIRST", Applied: true, ChecksumMismatch: true},
+		{Name: "SECOND", LastError: "failed"},
+		{Name: "THIRD", Applied: true},
+	}
+
+	for i, test := range testData {
+		got := status[i]
+		if got.Title != test.Name {
+			t.Errorf("%s: want position %d, got %s", test.Name, i, got.Title)
+		}
+		if got.Applied != test.Applied || got.ChecksumMismatch != test.ChecksumMismatch || got.LastError != test.LastError {
+			t.Errorf("%s: want %v, got %+v", test.Name, test, got)
+		}
+	}
+}
@@ -1,39 +1,60 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 const (
 	EXTERNAL_EVENT_PROVIDER_MICROSOFT ExternalEventProvider = "microsoft"
+	EXTERNAL_EVENT_PROVIDER_CALDAV    ExternalEventProvider = "caldav"
 	SIGNED_STATUS_ACCEPTED            AbsenceSignedStatus   = "accepted"
 	SIGNED_STATUS_DECLINED            AbsenceSignedStatus   = "declined"
 )
 
+const (
+	ABSENCE_DAY_PART_FULL      AbsenceDayPart = "full"
+	ABSENCE_DAY_PART_MORNING   AbsenceDayPart = "morning"
+	ABSENCE_DAY_PART_AFTERNOON AbsenceDayPart = "afternoon"
+	ABSENCE_DAY_PART_HOURS     AbsenceDayPart = "hours"
+)
+
 type AbsenceSignedStatus string
 type ExternalEventProvider string
+type AbsenceDayPart string
 
 type Absence struct {
 	gorm.Model
-	UserID                *uint `gorm:"not null"`
-	User                  *User
-	AbsenceFrom           time.Time
-	AbsenceTill           time.Time
-	AbsenceReasonID       *uint `gorm:"not null"`
-	AbsenceReason         AbsenceReason
-	SignedUserID          *uint
-	SignedUser            *User
-	SignedMessage         *string
-	SignedStatus          *AbsenceSignedStatus
-	SignedTimestamp       *time.Time
-	ExternalEventProvider ExternalEventProvider
-	ExternalEventID       string
-	Identifier            uuid.UUID
-	ExternalEvents        []AbsenceExternalEvent
-	NettoDays             *float64
+	UserID                      *uint `gorm:"not null"`
+	User                        *User
+	AbsenceFrom                 time.Time
+	AbsenceTill                 time.Time
+	AbsenceReasonID             *uint `gorm:"not null"`
+	AbsenceReason               AbsenceReason
+	SignedUserID                *uint
+	SignedUser                  *User
+	SignedMessage               *string
+	SignedStatus                *AbsenceSignedStatus
+	SignedTimestamp             *time.Time
+	ExternalEventProvider       ExternalEventProvider
+	ExternalEventID             string
+	Identifier                  uuid.UUID
+	ExternalEvents              []AbsenceExternalEvent
+	NettoDays                   *float64
+	DayPart                     AbsenceDayPart `gorm:"default:full"`
+	HoursFrom                   *time.Time
+	HoursTill                   *time.Time
+	ValidationOverrideUserID    *uint
+	ValidationOverrideTimestamp *time.Time
+	ValidationOverrideReasons   *string
+	Approvals                   []AbsenceApproval      `gorm:"constraint:OnDelete:CASCADE"`
+	ChangeRequests              []AbsenceChangeRequest `gorm:"constraint:OnDelete:CASCADE"`
 }
 
 type AbsenceExternalEvent struct {
@@ -43,6 +64,7 @@ type AbsenceExternalEvent struct {
 	ExternalEventID       string
 	ExternalEventProvider ExternalEventProvider
 	Update                bool
+	ExternalEventSync
 }
 
 const (
@@ -56,30 +78,53 @@ type AbsenceReasonOvertimeImpact string
 
 type AbsenceReason struct {
 	gorm.Model
-	Description    string
-	OvertimeImpact AbsenceReasonOvertimeImpact `gorm:"default:none"`
-	ImpactHours    float64
-	ImpactDays     float64
-	NeedsApproval  *bool
+	Description     string
+	OvertimeImpact  AbsenceReasonOvertimeImpact `gorm:"default:none"`
+	ImpactHours     float64
+	ImpactDays      float64
+	NeedsApproval   *bool
+	DeductsVacation *bool
+	ApprovalSteps   []AbsenceApprovalStep `gorm:"constraint:OnDelete:CASCADE"`
 }
 
 type AbsenceReasonCreateRequest struct {
-	Description    string `binding:"required"`
-	NeedsApproval  *bool
-	OvertimeImpact AbsenceReasonOvertimeImpact
-	ImpactHours    float64
-	ImpactDays     float64
+	Description     string `binding:"required"`
+	NeedsApproval   *bool
+	OvertimeImpact  AbsenceReasonOvertimeImpact
+	ImpactHours     float64
+	ImpactDays      float64
+	DeductsVacation *bool
+	ApprovalSteps   []AbsenceApprovalStepCreateRequest `binding:"dive"`
+}
+
+func (r *AbsenceReasonCreateRequest) GetApprovalSteps() []AbsenceApprovalStep {
+	steps := []AbsenceApprovalStep{}
+	for i, step := range r.ApprovalSteps {
+		steps = append(steps, AbsenceApprovalStep{
+			Position:       uint(i + 1),
+			ApproverType:   step.ApproverType,
+			ApproverUserID: step.ApproverUserID,
+			MinimumDays:    step.MinimumDays,
+		})
+	}
+
+	return steps
 }
 
 type AbsenceSignRequest struct {
-	Status  AbsenceSignedStatus `binding:"required"`
-	Message *string
+	Status             AbsenceSignedStatus `binding:"required,oneof=accepted declined"`
+	Message            *string
+	OverrideValidation bool
 }
 
 type AbsenceCreateRequest struct {
-	AbsenceFrom     string `binding:"required" time_format:"2006-01-02"`
-	AbsenceTill     string `binding:"required" time_format:"2006-01-02"`
-	AbsenceReasonID uint   `binding:"required"`
+	AbsenceFrom        string         `binding:"required" time_format:"2006-01-02"`
+	AbsenceTill        string         `binding:"required" time_format:"2006-01-02"`
+	AbsenceReasonID    uint           `binding:"required"`
+	DayPart            AbsenceDayPart `binding:"omitempty,oneof=full morning afternoon hours"`
+	HoursFrom          string         `time_format:"15:04"`
+	HoursTill          string         `time_format:"15:04"`
+	OverrideValidation bool
 }
 
 func (acr *AbsenceCreateRequest) AbsenceFromParsed() (time.Time, error) {
@@ -90,18 +135,56 @@ func (acr *AbsenceCreateRequest) AbsenceTillParsed() (time.Time, error) {
 	return time.Parse("2006-01-02", acr.AbsenceTill)
 }
 
+func (acr *AbsenceCreateRequest) GetDayPart() AbsenceDayPart {
+	if acr.DayPart == "" {
+		return ABSENCE_DAY_PART_FULL
+	}
+
+	return acr.DayPart
+}
+
+// HoursParsed returns the begin and end of a partial day absence on the given
+// day in local time. Hourly absences use the requested hours, half days the
+// times of the settings.
+func (acr *AbsenceCreateRequest) HoursParsed(day time.Time, settings Settings) (time.Time, time.Time, error) {
+	date := day.Format("2006-01-02")
+
+	from, till := acr.HoursFrom, acr.HoursTill
+	if acr.GetDayPart() != ABSENCE_DAY_PART_HOURS {
+		from, till = settings.GetHalfDayRange(acr.GetDayPart())
+	}
+
+	hoursFrom, err := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("%s %s", date, from), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	hoursTill, err := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("%s %s", date, till), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !hoursTill.After(hoursFrom) {
+		return time.Time{}, time.Time{}, errors.New("hours till has to be after hours from")
+	}
+
+	return hoursFrom, hoursTill, nil
+}
+
 type AbsenceUserSummaryYearReason struct {
 	Upcoming float64
 	Past     float64
 }
 
 type AbsenceUserSummaryYear struct {
-	ByAbsenceReason map[uint]AbsenceUserSummaryYearReason
+	ByAbsenceReason       map[uint]AbsenceUserSummaryYearReason
+	RemainingVacationDays float64
 }
 
 type AbsenceUserSummary struct {
-	ByYear             map[int]AbsenceUserSummaryYear
-	HolidayDaysPerYear uint
+	ByYear                map[int]AbsenceUserSummaryYear
+	HolidayDaysPerYear    uint
+	RemainingVacationDays float64
 }
 
 type AbsenceReturn struct {
@@ -117,29 +200,132 @@ type AbsenceReturn struct {
 	SignedStatus    *AbsenceSignedStatus
 	SignedTimestamp *time.Time
 	NettoDays       float64
+	DayPart         AbsenceDayPart
+	HoursFrom       *time.Time
+	HoursTill       *time.Time
 	CreatedAt       time.Time
 	Reason          string `json:",omitempty"`
 	Deletable       bool
+	Conflicts       []AbsenceValidationViolation `json:",omitempty"`
+	Approvals       []AbsenceApproval            `json:",omitempty"`
+}
+
+func (a *Absence) GetDayPart() AbsenceDayPart {
+	if a.DayPart == "" {
+		return ABSENCE_DAY_PART_FULL
+	}
+
+	return a.DayPart
+}
+
+func (a *Absence) IsFullDay() bool {
+	return a.GetDayPart() == ABSENCE_DAY_PART_FULL
+}
+
+// GetDayFactor returns the share of a working day with the given planned hours
+// which is covered by the absence.
+func (a *Absence) GetDayFactor(plannedHours float64) float64 {
+	switch a.GetDayPart() {
+	case ABSENCE_DAY_PART_MORNING, ABSENCE_DAY_PART_AFTERNOON:
+		return 0.5
+	case ABSENCE_DAY_PART_HOURS:
+		if a.HoursFrom == nil || a.HoursTill == nil || plannedHours <= 0 {
+			return 0
+		}
+
+		return math.Min(a.HoursTill.Sub(*a.HoursFrom).Hours()/plannedHours, 1)
+	}
+
+	return 1
+}
+
+// GetDayShare returns the share of the working day covered by the absence and
+// the planned hours of the full day. A partial holiday reduces the share which
+// is left to be covered, e.g. a full day absence on a half holiday is half a day.
+func (a *Absence) GetDayShare(date time.Time, holidays Holidays, workTimeModels UserWorkTimeModels) (float64, float64) {
+	workTimeModel := workTimeModels.GetWorkTimeModelForDay(date)
+	fullHours := workTimeModel.GetHoursForWeekday(date.Weekday())
+	if fullHours <= 0 {
+		return 0, 0
+	}
+
+	share := math.Min(a.GetDayFactor(fullHours), 1-holidays.GetDayOffFactor(date))
+	return math.Max(share, 0), fullHours
+}
+
+// GetAbsentHoursForDay returns the planned hours of the day covered by the
+// absence.
+func (a *Absence) GetAbsentHoursForDay(date time.Time, holidays Holidays, workTimeModels UserWorkTimeModels) float64 {
+	if !a.IsDateInAbsence(helper.GetDayDate(date)) {
+		return 0
+	}
+
+	share, fullHours := a.GetDayShare(date, holidays, workTimeModels)
+	return fullHours * share
 }
 
-func (a *Absence) CalculateNettoDays(holidays Holidays) {
-	days := 0
+func (a *Absence) CalculateNettoDays(holidays Holidays, workTimeModels UserWorkTimeModels) {
+	total := 0.0
 
 	currentDay := a.AbsenceFrom
 
 	for !currentDay.After(a.AbsenceTill) {
-		if currentDay.Weekday() != time.Saturday && currentDay.Weekday() != time.Sunday && !holidays.Contains(currentDay) {
-			days++
-		}
+		share, _ := a.GetDayShare(currentDay, holidays, workTimeModels)
+		total += share
 
 		currentDay = currentDay.Add(24 * time.Hour)
 	}
 
-	total := float64(days)
-
 	a.NettoDays = &total
 }
 
+// CalculateOvertimeImpact returns the hours the absence credits or debits on
+// the working days between from and till, holidays and days without planned
+// hours are skipped. The duration of partial day absences isn't credited, it
+// already lowers the planned hours of the day.
+func (a *Absence) CalculateOvertimeImpact(from time.Time, till time.Time, holidays Holidays, workTimeModels UserWorkTimeModels) float64 {
+	impact := 0.0
+
+	currentDay := helper.GetDayDate(a.AbsenceFrom)
+	if currentDay.Before(helper.GetDayDate(from)) {
+		currentDay = helper.GetDayDate(from)
+	}
+
+	lastDay := helper.GetDayDate(a.AbsenceTill)
+	if lastDay.After(helper.GetDayDate(till)) {
+		lastDay = helper.GetDayDate(till)
+	}
+
+	for !currentDay.After(lastDay) {
+		share, fullHours := a.GetDayShare(currentDay, holidays, workTimeModels)
+
+		if share > 0 {
+			switch a.AbsenceReason.OvertimeImpact {
+			case ABESENCE_REASON_OVERTIME_IMPACT_DURATION:
+				if a.IsFullDay() {
+					impact += fullHours * share
+				}
+			case ABESENCE_REASON_OVERTIME_IMPACT_HOURS:
+				impact += a.AbsenceReason.ImpactHours * share
+			case ABESENCE_REASON_OVERTIME_IMPACT_DAYS:
+				impact += a.AbsenceReason.ImpactDays * fullHours * share
+			}
+		}
+
+		currentDay = currentDay.AddDate(0, 0, 1)
+	}
+
+	return impact
+}
+
+func (a *Absence) DeductsVacation() bool {
+	if a.SignedStatus != nil && *a.SignedStatus == SIGNED_STATUS_DECLINED {
+		return false
+	}
+
+	return a.AbsenceReason.DeductsVacation != nil && *a.AbsenceReason.DeductsVacation
+}
+
 func (a *Absence) IsDeletableByUser() bool {
 	return a.AbsenceFrom.After(time.Now()) || time.Now().Sub(a.CreatedAt).Hours() <= 24
 }
@@ -165,6 +351,9 @@ func AbsenceReturns(absences []Absence, user *User, withReason bool, showRealRea
 			AbsenceFrom: absence.AbsenceFrom,
 			AbsenceTill: absence.AbsenceTill,
 			NettoDays:   *absence.NettoDays,
+			DayPart:     absence.GetDayPart(),
+			HoursFrom:   absence.HoursFrom,
+			HoursTill:   absence.HoursTill,
 			CreatedAt:   absence.CreatedAt,
 			Deletable:   absence.IsDeletableByUser(),
 		}
@@ -191,6 +380,7 @@ func AbsenceReturns(absences []Absence, user *User, withReason bool, showRealRea
 			returnObj.SignedMessage = absence.SignedMessage
 			returnObj.SignedStatus = absence.SignedStatus
 			returnObj.SignedTimestamp = absence.SignedTimestamp
+			returnObj.Approvals = absence.Approvals
 		}
 
 		result = append(result, returnObj)
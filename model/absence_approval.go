@@ -0,0 +1,167 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AbsenceApproverType string
+
+const (
+	ABSENCE_APPROVER_TYPE_TEAM_LEAD     AbsenceApproverType = "team_lead"
+	ABSENCE_APPROVER_TYPE_ADMINISTRATOR AbsenceApproverType = "administrator"
+	ABSENCE_APPROVER_TYPE_USER          AbsenceApproverType = "user"
+)
+
+// AbsenceApprovalStep is one stage of the approval chain of an absence reason.
+// Steps with minimum days only apply to absences with more netto days.
+type AbsenceApprovalStep struct {
+	gorm.Model
+	AbsenceReasonID uint           `gorm:"not null;index"`
+	AbsenceReason   *AbsenceReason `json:"-"`
+	Position        uint
+	ApproverType    AbsenceApproverType
+	ApproverUserID  *uint
+	ApproverUser    *User `json:"-"`
+	MinimumDays     float64
+}
+
+type AbsenceApprovalStepCreateRequest struct {
+	ApproverType   AbsenceApproverType `binding:"required,oneof=team_lead administrator user"`
+	ApproverUserID *uint               `binding:"required_if=ApproverType user"`
+	MinimumDays    float64
+}
+
+type AbsenceApproval struct {
+	gorm.Model
+	AbsenceID       uint     `gorm:"not null;index"`
+	Absence         *Absence `json:"-"`
+	Position        uint
+	ApproverType    AbsenceApproverType
+	SignedUserID    uint
+	SignedUser      *User `json:"-"`
+	SignedStatus    AbsenceSignedStatus
+	SignedMessage   *string
+	SignedTimestamp time.Time
+}
+
+// GetApprovalChain returns the steps needed to accept an absence with the given
+// netto days. Reasons needing an approval without configured steps are signed
+// by a team lead.
+func (r *AbsenceReason) GetApprovalChain(nettoDays float64) []AbsenceApprovalStep {
+	chain := []AbsenceApprovalStep{}
+
+	if r.NeedsApproval == nil || !*r.NeedsApproval {
+		return chain
+	}
+
+	if len(r.ApprovalSteps) == 0 {
+		return append(chain, AbsenceApprovalStep{
+			Position:     1,
+			ApproverType: ABSENCE_APPROVER_TYPE_TEAM_LEAD,
+		})
+	}
+
+	for _, step := range r.ApprovalSteps {
+		if step.MinimumDays > 0 && nettoDays <= step.MinimumDays {
+			continue
+		}
+
+		chain = append(chain, step)
+	}
+
+	slices.SortFunc(chain, func(a, b AbsenceApprovalStep) int {
+		return int(a.Position) - int(b.Position)
+	})
+
+	return chain
+}
+
+// IsApprover returns true if the user is allowed to sign the step for an
+// absence of the given user.
+func (s *AbsenceApprovalStep) IsApprover(user *User, teams []Team, absenceUserID uint) bool {
+	switch s.ApproverType {
+	case ABSENCE_APPROVER_TYPE_ADMINISTRATOR:
+		return user.AccessLevel == USER_ACCESS_LEVEL_ADMIN
+	case ABSENCE_APPROVER_TYPE_USER:
+		return s.ApproverUserID != nil && *s.ApproverUserID == user.ID
+	case ABSENCE_APPROVER_TYPE_TEAM_LEAD:
+		for _, team := range teams {
+			isLead := false
+			isMember := false
+			for _, member := range team.Members {
+				if member.UserID == user.ID && (member.Level == TeamLevel_Lead || member.Level == TeamLevel_LeadSurrogate) {
+					isLead = true
+				}
+				if member.UserID == absenceUserID {
+					isMember = true
+				}
+			}
+
+			if isLead && isMember {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *Absence) IsApprovalPending() bool {
+	return a.SignedStatus == nil
+}
+
+// GetNextApprovalStep returns the first step of the chain without an accepted
+// approval, nil if the chain is complete.
+func (a *Absence) GetNextApprovalStep() *AbsenceApprovalStep {
+	nettoDays := 0.0
+	if a.NettoDays != nil {
+		nettoDays = *a.NettoDays
+	}
+
+	for _, step := range a.AbsenceReason.GetApprovalChain(nettoDays) {
+		approved := slices.ContainsFunc(a.Approvals, func(approval AbsenceApproval) bool {
+			return approval.Position == step.Position && approval.SignedStatus == SIGNED_STATUS_ACCEPTED
+		})
+
+		if !approved {
+			return &step
+		}
+	}
+
+	return nil
+}
+
+func (a *Absence) HasApproved(userID uint) bool {
+	return slices.ContainsFunc(a.Approvals, func(approval AbsenceApproval) bool {
+		return approval.SignedUserID == userID
+	})
+}
+
+// Approve records the decision of the user for the step. A decline ends the
+// chain, the absence is accepted once the last step is approved. The added
+// approval is returned, updates of the absence don't store it.
+func (a *Absence) Approve(step *AbsenceApprovalStep, signingUser *User, status AbsenceSignedStatus, message *string) *AbsenceApproval {
+	a.Approvals = append(a.Approvals, AbsenceApproval{
+		AbsenceID:       a.ID,
+		Position:        step.Position,
+		ApproverType:    step.ApproverType,
+		SignedUserID:    signingUser.ID,
+		SignedStatus:    status,
+		SignedMessage:   message,
+		SignedTimestamp: time.Now(),
+	})
+	approval := &a.Approvals[len(a.Approvals)-1]
+
+	if status != SIGNED_STATUS_ACCEPTED {
+		a.Sign(signingUser, status, message)
+		return approval
+	}
+
+	if a.GetNextApprovalStep() == nil {
+		a.Sign(signingUser, SIGNED_STATUS_ACCEPTED, message)
+	}
+	return approval
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestAbsenceApprovalChain(t *testing.T) {
+	needsApproval := true
+	lead := User{Model: gorm.Model{ID: 2}}
+	surrogate := User{Model: gorm.Model{ID: 3}}
+	hr := User{Model: gorm.Model{ID: 4}}
+
+	reason := AbsenceReason{
+		NeedsApproval: &needsApproval,
+		ApprovalSteps: []AbsenceApprovalStep{
+			{Position: 3, ApproverType: ABSENCE_APPROVER_TYPE_USER, ApproverUserID: &hr.ID},
+			{Position: 1, ApproverType: ABSENCE_APPROVER_TYPE_TEAM_LEAD},
+			{Position: 2, ApproverType: ABSENCE_APPROVER_TYPE_TEAM_LEAD, MinimumDays: 10},
+		},
+	}
+
+	testData := []struct {
+		Name      string
+		NettoDays float64
+		Wanted    int
+	}{
+		{Name: "short absence", NettoDays: 5, Wanted: 2},
+		{Name: "long absence", NettoDays: 12, Wanted: 3},
+	}
+
+	for _, item := range testData {
+		chain := reason.GetApprovalChain(item.NettoDays)
+		if len(chain) != item.Wanted {
+			t.Errorf("%s: want %d steps, got %d", item.Name, item.Wanted, len(chain))
+		}
+	}
+
+	nettoDays := 12.0
+	absence := Absence{AbsenceReason: reason, NettoDays: &nettoDays}
+
+	for _, signingUser := range []User{lead, surrogate} {
+		step := absence.GetNextApprovalStep()
+		if step == nil || step.ApproverType != ABSENCE_APPROVER_TYPE_TEAM_LEAD {
+			t.Fatalf("want team lead step, got %+v", step)
+		}
+
+		absence.Approve(step, &signingUser, SIGNED_STATUS_ACCEPTED, nil)
+		if !absence.IsApprovalPending() {
+			t.Fatalf("absence accepted before chain completed")
+		}
+	}
+
+	if !absence.HasApproved(lead.ID) {
+		t.Errorf("want approval of lead recorded")
+	}
+
+	step := absence.GetNextApprovalStep()
+	if step == nil || !step.IsApprover(&hr, nil, 1) || step.IsApprover(&lead, nil, 1) {
+		t.Fatalf("want user step for hr, got %+v", step)
+	}
+
+	absence.Approve(step, &hr, SIGNED_STATUS_ACCEPTED, nil)
+	if absence.SignedStatus == nil || *absence.SignedStatus != SIGNED_STATUS_ACCEPTED {
+		t.Errorf("want accepted after last step, got %v", absence.SignedStatus)
+	}
+
+	declined := Absence{AbsenceReason: reason, NettoDays: &nettoDays}
+	declined.Approve(declined.GetNextApprovalStep(), &lead, SIGNED_STATUS_DECLINED, nil)
+	if declined.SignedStatus == nil || *declined.SignedStatus != SIGNED_STATUS_DECLINED {
+		t.Errorf("want declined after first step, got %v", declined.SignedStatus)
+	}
+}
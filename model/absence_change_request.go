@@ -0,0 +1,143 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AbsenceChangeRequestType string
+type AbsenceChangeRequestStatus string
+
+const (
+	ABSENCE_CHANGE_REQUEST_TYPE_CANCEL AbsenceChangeRequestType = "cancel"
+	ABSENCE_CHANGE_REQUEST_TYPE_MODIFY AbsenceChangeRequestType = "modify"
+)
+
+const (
+	ABSENCE_CHANGE_REQUEST_STATUS_PENDING  AbsenceChangeRequestStatus = "pending"
+	ABSENCE_CHANGE_REQUEST_STATUS_APPROVED AbsenceChangeRequestStatus = "approved"
+	ABSENCE_CHANGE_REQUEST_STATUS_DECLINED AbsenceChangeRequestStatus = "declined"
+)
+
+// AbsenceChangeRequest asks the team lead to cancel or shorten an accepted
+// absence. The old values are kept as history of the absence.
+type AbsenceChangeRequest struct {
+	gorm.Model
+	AbsenceID       uint     `gorm:"not null;index"`
+	Absence         *Absence `json:",omitempty"`
+	UserID          uint     `gorm:"not null;index"`
+	User            *User    `json:"-"`
+	Type            AbsenceChangeRequestType
+	ChangeReason    string
+	OldAbsenceFrom  time.Time
+	OldAbsenceTill  time.Time
+	OldNettoDays    float64
+	NewAbsenceFrom  *time.Time
+	NewAbsenceTill  *time.Time
+	Status          AbsenceChangeRequestStatus `gorm:"default:pending"`
+	SignedUserID    *uint
+	SignedUser      *User `json:"-"`
+	SignedMessage   *string
+	SignedTimestamp *time.Time
+}
+
+type AbsenceChangeRequestCreateRequest struct {
+	Type           AbsenceChangeRequestType `binding:"required,oneof=cancel modify"`
+	ChangeReason   string                   `binding:"required"`
+	NewAbsenceFrom string                   `binding:"required_if=Type modify" time_format:"2006-01-02"`
+	NewAbsenceTill string                   `binding:"required_if=Type modify" time_format:"2006-01-02"`
+}
+
+type AbsenceChangeRequestSignRequest struct {
+	Status  AbsenceChangeRequestStatus `binding:"required,oneof=approved declined"`
+	Message *string
+}
+
+type AbsenceChangeRequestOpenResponse struct {
+	AbsenceChangeRequest
+	User UserResponse
+}
+
+// NewAbsenceChangeRequest creates a pending change request for the absence. A
+// modification may only shorten the absence.
+func NewAbsenceChangeRequest(absence *Absence, request *AbsenceChangeRequestCreateRequest) (AbsenceChangeRequest, error) {
+	if absence.SignedStatus == nil || *absence.SignedStatus != SIGNED_STATUS_ACCEPTED {
+		return AbsenceChangeRequest{}, errors.New("only accepted absences can be changed")
+	}
+
+	changeRequest := AbsenceChangeRequest{
+		AbsenceID:      absence.ID,
+		UserID:         *absence.UserID,
+		Type:           request.Type,
+		ChangeReason:   request.ChangeReason,
+		OldAbsenceFrom: absence.AbsenceFrom,
+		OldAbsenceTill: absence.AbsenceTill,
+		Status:         ABSENCE_CHANGE_REQUEST_STATUS_PENDING,
+	}
+
+	if absence.NettoDays != nil {
+		changeRequest.OldNettoDays = *absence.NettoDays
+	}
+
+	if request.Type == ABSENCE_CHANGE_REQUEST_TYPE_CANCEL {
+		return changeRequest, nil
+	}
+
+	newAbsenceFrom, err := time.Parse("2006-01-02", request.NewAbsenceFrom)
+	if err != nil {
+		return AbsenceChangeRequest{}, err
+	}
+
+	newAbsenceTill, err := time.Parse("2006-01-02", request.NewAbsenceTill)
+	if err != nil {
+		return AbsenceChangeRequest{}, err
+	}
+
+	if newAbsenceTill.Before(newAbsenceFrom) {
+		return AbsenceChangeRequest{}, errors.New("new absence till has to be after new absence from")
+	}
+
+	if newAbsenceFrom.Before(absence.AbsenceFrom) || newAbsenceTill.After(absence.AbsenceTill) {
+		return AbsenceChangeRequest{}, errors.New("an absence can only be shortened")
+	}
+
+	if newAbsenceFrom.Equal(absence.AbsenceFrom) && newAbsenceTill.Equal(absence.AbsenceTill) {
+		return AbsenceChangeRequest{}, errors.New("absence is not changed")
+	}
+
+	changeRequest.NewAbsenceFrom = &newAbsenceFrom
+	changeRequest.NewAbsenceTill = &newAbsenceTill
+
+	return changeRequest, nil
+}
+
+func (r *AbsenceChangeRequest) IsPending() bool {
+	return r.Status == ABSENCE_CHANGE_REQUEST_STATUS_PENDING
+}
+
+func (r *AbsenceChangeRequest) IsCancellation() bool {
+	return r.Type == ABSENCE_CHANGE_REQUEST_TYPE_CANCEL
+}
+
+// Apply shortens the absence to the requested range, the netto days have to be
+// recalculated afterwards.
+func (r *AbsenceChangeRequest) Apply(absence *Absence) {
+	if r.NewAbsenceFrom == nil || r.NewAbsenceTill == nil {
+		return
+	}
+
+	absence.AbsenceFrom = *r.NewAbsenceFrom
+	absence.AbsenceTill = *r.NewAbsenceTill
+}
+
+func (r *AbsenceChangeRequest) Sign(signingUser *User, status AbsenceChangeRequestStatus, message *string) {
+	now := time.Now()
+
+	r.SignedUser = signingUser
+	r.SignedUserID = &signingUser.ID
+	r.SignedTimestamp = &now
+	r.Status = status
+	r.SignedMessage = message
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewAbsenceChangeRequest(t *testing.T) {
+	userId := uint(1)
+	nettoDays := 5.0
+	accepted := SIGNED_STATUS_ACCEPTED
+
+	absence := Absence{
+		UserID:       &userId,
+		AbsenceFrom:  time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		AbsenceTill:  time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		NettoDays:    &nettoDays,
+		SignedStatus: &accepted,
+	}
+
+	testData := []struct {
+		Name    string
+		Request AbsenceChangeRequestCreateRequest
+		Wanted  bool
+	}{
+		{
+			Name:    "cancel",
+			Request: AbsenceChangeRequestCreateRequest{Type: ABSENCE_CHANGE_REQUEST_TYPE_CANCEL},
+			Wanted:  true,
+		},
+		{
+			Name:    "shorten",
+			Request: AbsenceChangeRequestCreateRequest{Type: ABSENCE_CHANGE_REQUEST_TYPE_MODIFY, NewAbsenceFrom: "2024-01-08", NewAbsenceTill: "2024-01-10"},
+			Wanted:  true,
+		},
+		{
+			Name:    "extend",
+			Request: AbsenceChangeRequestCreateRequest{Type: ABSENCE_CHANGE_REQUEST_TYPE_MODIFY, NewAbsenceFrom: "2024-01-08", NewAbsenceTill: "2024-01-15"},
+			Wanted:  false,
+		},
+		{
+			Name:    "unchanged",
+			Request: AbsenceChangeRequestCreateRequest{Type: ABSENCE_CHANGE_REQUEST_TYPE_MODIFY, NewAbsenceFrom: "2024-01-08", NewAbsenceTill: "2024-01-12"},
+			Wanted:  false,
+		},
+	}
+
+	for _, item := range testData {
+		changeRequest, err := NewAbsenceChangeRequest(&absence, &item.Request)
+		if (err == nil) != item.Wanted {
+			t.Errorf("%s: want valid %t, got %v", item.Name, item.Wanted, err)
+			continue
+		}
+
+		if err == nil && changeRequest.OldNettoDays != nettoDays {
+			t.Errorf("%s: want old netto days %f, got %f", item.Name, nettoDays, changeRequest.OldNettoDays)
+		}
+	}
+
+	changeRequest, _ := NewAbsenceChangeRequest(&absence, &testData[1].Request)
+	shortened := absence
+	changeRequest.Apply(&shortened)
+	if !shortened.AbsenceTill.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("want shortened absence, got %s", shortened.AbsenceTill)
+	}
+
+	pending := absence
+	pending.SignedStatus = nil
+	_, err := NewAbsenceChangeRequest(&pending, &testData[0].Request)
+	if err == nil {
+		t.Errorf("want error for pending absence")
+	}
+}
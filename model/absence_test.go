@@ -43,7 +43,8 @@ func TestAbsenceDaysCalculation(t *testing.T) {
 			AbsenceTill: item.Till,
 		}
 
-		workdays := absence.GetAbsenceWorkDays()
+		absence.CalculateNettoDays(nil, nil)
+		workdays := int(*absence.NettoDays)
 		if workdays != item.Wanted {
 			t.Fatalf("From: %s, Till: %s, Want: %d, Got: %d\n", item.From, item.Till, item.Wanted, workdays)
 		} else {
@@ -51,3 +52,157 @@ func TestAbsenceDaysCalculation(t *testing.T) {
 		}
 	}
 }
+
+func TestAbsenceCalculateOvertimeImpact(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endOfJanuary := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+	february := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	endOfFebruary := time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)
+
+	testData := []struct {
+		Name     string
+		Reason   AbsenceReason
+		From     time.Time
+		Till     time.Time
+		Holidays Holidays
+		Wanted   float64
+	}{
+		{
+			Name:   "none",
+			Reason: AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_NONE},
+			From:   january,
+			Till:   endOfJanuary,
+			Wanted: 0,
+		},
+		{
+			Name:   "duration uses planned hours",
+			Reason: AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_DURATION},
+			From:   january,
+			Till:   endOfJanuary,
+			Wanted: 24,
+		},
+		{
+			Name:     "duration skips holidays",
+			Reason:   AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_DURATION},
+			From:     january,
+			Till:     endOfJanuary,
+			Holidays: Holidays{{Date: time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)}},
+			Wanted:   16,
+		},
+		{
+			Name:   "hours per working day",
+			Reason: AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_HOURS, ImpactHours: 2},
+			From:   january,
+			Till:   endOfJanuary,
+			Wanted: 6,
+		},
+		{
+			Name:   "days debit",
+			Reason: AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_DAYS, ImpactDays: -1},
+			From:   january,
+			Till:   endOfJanuary,
+			Wanted: -24,
+		},
+		{
+			Name:   "days in following month",
+			Reason: AbsenceReason{OvertimeImpact: ABESENCE_REASON_OVERTIME_IMPACT_DAYS, ImpactDays: 0.5},
+			From:   february,
+			Till:   endOfFebruary,
+			Wanted: 7,
+		},
+	}
+
+	for _, item := range testData {
+		absence := Absence{
+			AbsenceFrom:   time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+			AbsenceTill:   time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+			AbsenceReason: item.Reason,
+		}
+
+		got := absence.CalculateOvertimeImpact(item.From, item.Till, item.Holidays, nil)
+		if got != item.Wanted {
+			t.Fatalf("%s: want %f, got %f", item.Name, item.Wanted, got)
+		}
+	}
+}
+
+func TestAbsencePartialDayNettoDays(t *testing.T) {
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	hoursFrom := time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)
+	hoursTill := time.Date(2024, 1, 8, 11, 0, 0, 0, time.UTC)
+
+	testData := []struct {
+		Absence Absence
+		Wanted  float64
+	}{
+		{
+			Absence: Absence{AbsenceFrom: monday, AbsenceTill: monday},
+			Wanted:  1,
+		},
+		{
+			Absence: Absence{AbsenceFrom: monday, AbsenceTill: monday, DayPart: ABSENCE_DAY_PART_MORNING},
+			Wanted:  0.5,
+		},
+		{
+			Absence: Absence{AbsenceFrom: monday, AbsenceTill: monday, DayPart: ABSENCE_DAY_PART_HOURS, HoursFrom: &hoursFrom, HoursTill: &hoursTill},
+			Wanted:  0.25,
+		},
+	}
+
+	for _, item := range testData {
+		item.Absence.CalculateNettoDays(nil, nil)
+		if *item.Absence.NettoDays != item.Wanted {
+			t.Fatalf("%s: want %f, got %f", item.Absence.GetDayPart(), item.Wanted, *item.Absence.NettoDays)
+		}
+
+		absentHours := item.Absence.GetAbsentHoursForDay(monday, nil, nil)
+		if absentHours != item.Wanted*8 {
+			t.Fatalf("%s: want %f absent hours, got %f", item.Absence.GetDayPart(), item.Wanted*8, absentHours)
+		}
+	}
+}
+
+func TestAbsenceCreateRequestHoursParsed(t *testing.T) {
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	settings := Settings{AbsenceMorningFrom: "07:30", AbsenceAfternoonFrom: "11:30", AbsenceAfternoonTill: "16:00"}
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, 7, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	testData := []struct {
+		Name     string
+		Request  AbsenceCreateRequest
+		Settings Settings
+		WantFrom time.Time
+		WantTill time.Time
+	}{
+		{Name: "hours", Request: AbsenceCreateRequest{DayPart: ABSENCE_DAY_PART_HOURS, HoursFrom: "09:00", HoursTill: "11:00"}, Settings: settings, WantFrom: at(9, 0), WantTill: at(11, 0)},
+		{Name: "morning", Request: AbsenceCreateRequest{DayPart: ABSENCE_DAY_PART_MORNING}, Settings: settings, WantFrom: at(7, 30), WantTill: at(11, 30)},
+		{Name: "afternoon", Request: AbsenceCreateRequest{DayPart: ABSENCE_DAY_PART_AFTERNOON}, Settings: settings, WantFrom: at(11, 30), WantTill: at(16, 0)},
+		{Name: "afternoon without settings", Request: AbsenceCreateRequest{DayPart: ABSENCE_DAY_PART_AFTERNOON}, WantFrom: at(12, 0), WantTill: at(17, 0)},
+	}
+
+	for _, test := range testData {
+		from, till, err := test.Request.HoursParsed(day, test.Settings)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		if !from.Equal(test.WantFrom) || !till.Equal(test.WantTill) {
+			t.Errorf("%s: want %s - %s, got %s - %s", test.Name, test.WantFrom, test.WantTill, from, till)
+		}
+
+		absence := Absence{AbsenceFrom: day, AbsenceTill: day, DayPart: test.Request.GetDayPart(), HoursFrom: &from, HoursTill: &till}
+		entry := absence.GetCalendarEntry()
+		wantFrom := test.WantFrom.Format(CALENDAR_ENTRY_DATE_TIME_LAYOUT)
+		wantTill := test.WantTill.Format(CALENDAR_ENTRY_DATE_TIME_LAYOUT)
+		if entry.From != wantFrom || entry.Till != wantTill {
+			t.Errorf("%s: want calendar entry %s - %s, got %s - %s", test.Name, wantFrom, wantTill, entry.From, entry.Till)
+		}
+	}
+
+	_, _, err := (&AbsenceCreateRequest{DayPart: ABSENCE_DAY_PART_HOURS, HoursFrom: "11:00", HoursTill: "09:00"}).HoursParsed(day, settings)
+	if err == nil {
+		t.Errorf("till before from: want error")
+	}
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AbsenceValidationReason string
+
+const (
+	ABSENCE_VALIDATION_REASON_OVERLAP          AbsenceValidationReason = "overlap"
+	ABSENCE_VALIDATION_REASON_NO_WORKING_DAYS  AbsenceValidationReason = "no_working_days"
+	ABSENCE_VALIDATION_REASON_EXCEEDS_VACATION AbsenceValidationReason = "exceeds_vacation"
+	ABSENCE_VALIDATION_REASON_BLACKOUT_PERIOD  AbsenceValidationReason = "blackout_period"
+	ABSENCE_VALIDATION_REASON_TEAM_CAPACITY    AbsenceValidationReason = "team_capacity"
+)
+
+type AbsenceValidationViolation struct {
+	Reason               AbsenceValidationReason
+	AbsenceID            *uint      `json:",omitempty"`
+	TeamID               *uint      `json:",omitempty"`
+	TeamBlackoutPeriodID *uint      `json:",omitempty"`
+	Date                 *time.Time `json:",omitempty"`
+	Value                float64
+	Limit                float64
+}
+
+// AbsenceValidationError is returned as error of the response, so the
+// violations are part of the json body.
+type AbsenceValidationError struct {
+	Violations []AbsenceValidationViolation
+}
+
+func (e *AbsenceValidationError) Error() string {
+	return fmt.Sprintf("absence is not valid: %s", e.Reasons())
+}
+
+func (e *AbsenceValidationError) Reasons() string {
+	reasons := []string{}
+	for _, violation := range e.Violations {
+		reasons = append(reasons, string(violation.Reason))
+	}
+
+	return strings.Join(reasons, ",")
+}
+
+// Overlaps returns true if both absences cover the same time. Half days only
+// overlap on the same part of the day, hourly absences by their hours.
+func (a *Absence) Overlaps(other *Absence) bool {
+	if a.AbsenceFrom.After(other.AbsenceTill) || other.AbsenceFrom.After(a.AbsenceTill) {
+		return false
+	}
+
+	if a.IsFullDay() || other.IsFullDay() {
+		return true
+	}
+
+	dayParts := []AbsenceDayPart{a.GetDayPart(), other.GetDayPart()}
+	if dayParts[0] != ABSENCE_DAY_PART_HOURS && dayParts[1] != ABSENCE_DAY_PART_HOURS {
+		return dayParts[0] == dayParts[1]
+	}
+
+	if dayParts[0] == ABSENCE_DAY_PART_HOURS && dayParts[1] == ABSENCE_DAY_PART_HOURS {
+		return a.HoursFrom.Before(*other.HoursTill) && other.HoursFrom.Before(*a.HoursTill)
+	}
+
+	return true
+}
+
+// ValidateAbsence checks a new absence against the existing absences of the
+// user and the vacation days remaining for the absence. Without a remaining
+// value the vacation check is skipped.
+func ValidateAbsence(absence *Absence, existing []Absence, remainingVacationDays *float64) []AbsenceValidationViolation {
+	violations := []AbsenceValidationViolation{}
+
+	for _, other := range existing {
+		if other.SignedStatus != nil && *other.SignedStatus == SIGNED_STATUS_DECLINED {
+			continue
+		}
+
+		if absence.Overlaps(&other) {
+			violations = append(violations, AbsenceValidationViolation{
+				Reason:    ABSENCE_VALIDATION_REASON_OVERLAP,
+				AbsenceID: &other.ID,
+			})
+		}
+	}
+
+	nettoDays := 0.0
+	if absence.NettoDays != nil {
+		nettoDays = *absence.NettoDays
+	}
+
+	if nettoDays <= 0 {
+		violations = append(violations, AbsenceValidationViolation{
+			Reason: ABSENCE_VALIDATION_REASON_NO_WORKING_DAYS,
+			Value:  nettoDays,
+		})
+	}
+
+	if remainingVacationDays != nil && absence.DeductsVacation() && nettoDays > *remainingVacationDays {
+		violations = append(violations, AbsenceValidationViolation{
+			Reason: ABSENCE_VALIDATION_REASON_EXCEEDS_VACATION,
+			Value:  nettoDays,
+			Limit:  *remainingVacationDays,
+		})
+	}
+
+	return violations
+}
+
+func (a *Absence) OverrideValidation(overridingUser *User, violations []AbsenceValidationViolation) {
+	now := time.Now()
+	validationError := AbsenceValidationError{Violations: violations}
+	reasons := validationError.Reasons()
+
+	a.ValidationOverrideUserID = &overridingUser.ID
+	a.ValidationOverrideTimestamp = &now
+	a.ValidationOverrideReasons = &reasons
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestValidateAbsence(t *testing.T) {
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	friday := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	deductsVacation := true
+	declined := SIGNED_STATUS_DECLINED
+
+	existing := []Absence{
+		{Model: gorm.Model{ID: 1}, AbsenceFrom: monday, AbsenceTill: monday, DayPart: ABSENCE_DAY_PART_MORNING},
+		{Model: gorm.Model{ID: 2}, AbsenceFrom: friday, AbsenceTill: friday, SignedStatus: &declined},
+	}
+
+	testData := []struct {
+		Name      string
+		Absence   Absence
+		Remaining *float64
+		Wanted    []AbsenceValidationReason
+	}{
+		{
+			Name:    "afternoon next to morning",
+			Absence: Absence{AbsenceFrom: monday, AbsenceTill: monday, DayPart: ABSENCE_DAY_PART_AFTERNOON},
+			Wanted:  []AbsenceValidationReason{},
+		},
+		{
+			Name:    "full day over morning",
+			Absence: Absence{AbsenceFrom: monday, AbsenceTill: friday},
+			Wanted:  []AbsenceValidationReason{ABSENCE_VALIDATION_REASON_OVERLAP},
+		},
+		{
+			Name:    "weekend only",
+			Absence: Absence{AbsenceFrom: saturday, AbsenceTill: sunday},
+			Wanted:  []AbsenceValidationReason{ABSENCE_VALIDATION_REASON_NO_WORKING_DAYS},
+		},
+		{
+			Name:      "exceeds vacation",
+			Absence:   Absence{AbsenceFrom: friday, AbsenceTill: friday, AbsenceReason: AbsenceReason{DeductsVacation: &deductsVacation}},
+			Remaining: func() *float64 { remaining := 0.5; return &remaining }(),
+			Wanted:    []AbsenceValidationReason{ABSENCE_VALIDATION_REASON_EXCEEDS_VACATION},
+		},
+	}
+
+	for _, item := range testData {
+		item.Absence.CalculateNettoDays(nil, nil)
+
+		violations := ValidateAbsence(&item.Absence, existing, item.Remaining)
+		if len(violations) != len(item.Wanted) {
+			t.Fatalf("%s: want %v, got %+v", item.Name, item.Wanted, violations)
+		}
+
+		for i, violation := range violations {
+			if violation.Reason != item.Wanted[i] {
+				t.Fatalf("%s: want %v, got %+v", item.Name, item.Wanted, violations)
+			}
+		}
+	}
+}
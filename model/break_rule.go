@@ -0,0 +1,93 @@
+package model
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+const DEFAULT_MINIMUM_BREAK_MINUTES = 15.0
+
+type SettingsBreakRule struct {
+	gorm.Model
+	SettingsID          uint
+	TeamID              *uint
+	Team                *Team `json:"-"`
+	ThresholdHours      float64
+	DeductionMinutes    float64
+	MinimumBreakMinutes float64 `gorm:"default:15"`
+}
+
+type SettingsBreakRuleCreateRequest struct {
+	TeamID              *uint
+	ThresholdHours      float64  `binding:"required"`
+	DeductionMinutes    float64  `binding:"required"`
+	MinimumBreakMinutes *float64 `binding:"omitempty,min=0"`
+}
+
+type BreakRules []SettingsBreakRule
+
+// DefaultBreakRules returns the statutory breaks of the german ArbZG,
+// 30 minutes after 6 hours and another 15 minutes after 9 hours, split into
+// segments of at least 15 minutes.
+func DefaultBreakRules() BreakRules {
+	return BreakRules{
+		{
+			ThresholdHours:      6.0,
+			DeductionMinutes:    30.0,
+			MinimumBreakMinutes: DEFAULT_MINIMUM_BREAK_MINUTES,
+		},
+		{
+			ThresholdHours:      9.0,
+			DeductionMinutes:    15.0,
+			MinimumBreakMinutes: DEFAULT_MINIMUM_BREAK_MINUTES,
+		},
+	}
+}
+
+// Apply deducts the required breaks from the worked hours of a day. Breaks
+// already taken between the timestamps count toward the required break if
+// they last at least the rule's minimum break, and a deduction never reduces
+// the working hours below the rule's threshold.
+func (b BreakRules) Apply(workedHours float64, takenBreaks []float64) (float64, float64) {
+	rules := slices.Clone(b)
+	slices.SortFunc(rules, func(a, b SettingsBreakRule) int {
+		if a.ThresholdHours < b.ThresholdHours {
+			return -1
+		}
+		if a.ThresholdHours > b.ThresholdHours {
+			return 1
+		}
+		return 0
+	})
+
+	remainingBreaks := slices.Clone(takenBreaks)
+	calculatedHours := workedHours
+	for _, rule := range rules {
+		if calculatedHours <= rule.ThresholdHours {
+			continue
+		}
+
+		deduction := rule.DeductionMinutes / 60.0
+		for i, takenBreak := range takenBreaks {
+			if takenBreak < rule.MinimumBreakMinutes/60.0 {
+				continue
+			}
+
+			credited := min(remainingBreaks[i], deduction)
+			remainingBreaks[i] -= credited
+			deduction -= credited
+		}
+
+		if deduction <= 0 {
+			continue
+		}
+
+		calculatedHours -= deduction
+		if calculatedHours < rule.ThresholdHours {
+			calculatedHours = rule.ThresholdHours
+		}
+	}
+
+	return calculatedHours, workedHours - calculatedHours
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CalendarShowAs string
+
+const (
+	CALENDAR_SHOW_AS_BUSY          CalendarShowAs = "busy"
+	CALENDAR_SHOW_AS_TENTATIVE     CalendarShowAs = "tentative"
+	CALENDAR_SHOW_AS_OUT_OF_OFFICE CalendarShowAs = "oof"
+)
+
+const (
+	CALENDAR_ENTRY_SUBJECT_PREFIX    = "BTC: "
+	CALENDAR_ENTRY_DATE_TIME_LAYOUT  = "2006-01-02T15:04:05"
+	CALENDAR_ENTRY_DEFAULT_TIME_ZONE = "Europe/Berlin"
+	// CALENDAR_ENTRY_ID_PROPERTY marks the events created by BeeTimeClock, its
+	// value is the identifier of the absence or external work.
+	CALENDAR_ENTRY_ID_PROPERTY = "X-BTC-ID"
+)
+
+var ErrCalendarEntryNotFound = errors.New("calendar entry not found")
+
+// CalendarEntry is the part of a calendar event which is managed by
+// BeeTimeClock. From and Till are local date times in the calendar time zone.
+// BeeTimeClockID is the value of the CALENDAR_ENTRY_ID_PROPERTY and Modified
+// the time of the last change, both are only read from the calendar.
+type CalendarEntry struct {
+	ID             string
+	Identifier     string
+	BeeTimeClockID string
+	Subject        string
+	From           string
+	Till           string
+	IsAllDay       bool
+	ShowAs         CalendarShowAs
+	Modified       time.Time
+}
+
+// Equals compares the managed fields, the date times are compared without
+// fractional seconds.
+func (e CalendarEntry) Equals(other CalendarEntry) bool {
+	return e.Subject == other.Subject &&
+		truncateDateTime(e.From) == truncateDateTime(other.From) &&
+		truncateDateTime(e.Till) == truncateDateTime(other.Till) &&
+		e.IsAllDay == other.IsAllDay &&
+		e.ShowAs == other.ShowAs
+}
+
+// IsCreatedByBeeTimeClock returns true for events carrying the
+// CALENDAR_ENTRY_ID_PROPERTY, events created before the property was added are
+// recognised by the subject prefix.
+func (e CalendarEntry) IsCreatedByBeeTimeClock() bool {
+	return e.BeeTimeClockID != "" || strings.HasPrefix(e.Subject, CALENDAR_ENTRY_SUBJECT_PREFIX)
+}
+
+func truncateDateTime(dateTime string) string {
+	if len(dateTime) > len(CALENDAR_ENTRY_DATE_TIME_LAYOUT) {
+		return dateTime[:len(CALENDAR_ENTRY_DATE_TIME_LAYOUT)]
+	}
+
+	return dateTime
+}
+
+// getCalendarRange returns the range of the event. Partial day absences use
+// their hours in local time, half days created without hours fall back to the
+// default half day times.
+func (a *Absence) getCalendarRange() (string, string) {
+	if a.IsFullDay() {
+		from := fmt.Sprintf("%sT00:00:00", a.AbsenceFrom.Format(time.DateOnly))
+		till := fmt.Sprintf("%sT00:00:00", a.AbsenceTill.Add(24*time.Hour).Format(time.DateOnly))
+		return from, till
+	}
+
+	if a.HoursFrom != nil && a.HoursTill != nil {
+		from := a.HoursFrom.In(time.Local).Format(CALENDAR_ENTRY_DATE_TIME_LAYOUT)
+		till := a.HoursTill.In(time.Local).Format(CALENDAR_ENTRY_DATE_TIME_LAYOUT)
+		return from, till
+	}
+
+	defaults := Settings{}
+	day := a.AbsenceFrom.Format(time.DateOnly)
+	from, till := defaults.GetHalfDayRange(a.GetDayPart())
+	return fmt.Sprintf("%sT%s:00", day, from), fmt.Sprintf("%sT%s:00", day, till)
+}
+
+// GetCalendarEntry returns the calendar entry the absence should have, absences
+// waiting for approval are shown as tentative.
+func (a *Absence) GetCalendarEntry() CalendarEntry {
+	from, till := a.getCalendarRange()
+
+	showAs := CALENDAR_SHOW_AS_OUT_OF_OFFICE
+	if a.IsApprovalPending() {
+		showAs = CALENDAR_SHOW_AS_TENTATIVE
+	}
+
+	return CalendarEntry{
+		Identifier: a.Identifier.String(),
+		Subject:    CALENDAR_ENTRY_SUBJECT_PREFIX + a.AbsenceReason.Description,
+		From:       from,
+		Till:       till,
+		IsAllDay:   a.IsFullDay(),
+		ShowAs:     showAs,
+	}
+}
+
+// GetCalendarEntry returns the calendar entry the external work should have.
+func (e *ExternalWork) GetCalendarEntry() CalendarEntry {
+	return CalendarEntry{
+		Identifier: e.Identifier.String(),
+		Subject:    CALENDAR_ENTRY_SUBJECT_PREFIX + e.Description,
+		From:       e.From.Format("2006-01-02T00:00:00"),
+		Till:       fmt.Sprintf("%sT00:00:00", e.Till.Add(24*time.Hour).Format(time.DateOnly)),
+		IsAllDay:   true,
+		ShowAs:     CALENDAR_SHOW_AS_OUT_OF_OFFICE,
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedType string
+
+const (
+	CALENDAR_FEED_TYPE_PERSONAL CalendarFeedType = "personal"
+	CALENDAR_FEED_TYPE_TEAM     CalendarFeedType = "team"
+)
+
+// UserCalendarFeed grants access to an iCalendar feed with the token in the
+// url, so calendar clients can subscribe without authentication.
+type UserCalendarFeed struct {
+	gorm.Model
+	UserID      uint
+	User        User `json:"-"`
+	Description string
+	Type        CalendarFeedType
+	TeamID      *uint
+	Token       string `gorm:"unique"`
+	ValidTill   time.Time
+}
+
+type UserCalendarFeedCreateRequest struct {
+	Description string           `binding:"required"`
+	Type        CalendarFeedType `binding:"required,oneof=personal team"`
+	TeamID      *uint            `binding:"required_if=Type team"`
+	ValidTill   time.Time
+}
+
+type UserCalendarFeedResponse struct {
+	gorm.Model
+	Description string
+	Type        CalendarFeedType
+	TeamID      *uint
+	ValidTill   time.Time
+}
+
+func (f *UserCalendarFeed) GetUserCalendarFeedResponse() UserCalendarFeedResponse {
+	return UserCalendarFeedResponse{
+		Model:       f.Model,
+		Description: f.Description,
+		Type:        f.Type,
+		TeamID:      f.TeamID,
+		ValidTill:   f.ValidTill,
+	}
+}
+
+// IsValid returns false for expired feeds, feeds without valid till don't
+// expire.
+func (f *UserCalendarFeed) IsValid(now time.Time) bool {
+	return f.ValidTill.IsZero() || now.Before(f.ValidTill)
+}
+
+// GetAbsenceFeedEntries returns the entries of the not declined absences. The
+// reasons follow the privacy rules of AbsenceReturns, the names of the users
+// are added for team feeds.
+func GetAbsenceFeedEntries(absences []Absence, user *User, showRealReason bool, withUserName bool) []CalendarEntry {
+	visibleAbsences := []Absence{}
+	for _, absence := range absences {
+		if absence.SignedStatus != nil && *absence.SignedStatus == SIGNED_STATUS_DECLINED {
+			continue
+		}
+		visibleAbsences = append(visibleAbsences, absence)
+	}
+
+	absenceReturns := AbsenceReturns(visibleAbsences, user, true, showRealReason, false)
+
+	entries := []CalendarEntry{}
+	for i, absence := range visibleAbsences {
+		entry := absence.GetCalendarEntry()
+		entry.Identifier = fmt.Sprintf("absence-%d@beetimeclock", absence.ID)
+		entry.Subject = absenceReturns[i].Reason
+
+		if withUserName {
+			entry.Subject = fmt.Sprintf("%s %s: %s", absenceReturns[i].User.FirstName, absenceReturns[i].User.LastName, entry.Subject)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func GetExternalWorkFeedEntries(externalWorks []ExternalWork) []CalendarEntry {
+	entries := []CalendarEntry{}
+	for _, externalWork := range externalWorks {
+		entry := externalWork.GetCalendarEntry()
+		entry.Identifier = fmt.Sprintf("external-work-%d@beetimeclock", externalWork.ID)
+		entry.Subject = externalWork.Description
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func GetHolidayFeedEntries(holidays []Holiday) []CalendarEntry {
+	entries := []CalendarEntry{}
+	for _, holiday := range holidays {
+		entries = append(entries, CalendarEntry{
+			Identifier: fmt.Sprintf("holiday-%d@beetimeclock", holiday.ID),
+			Subject:    holiday.Name,
+			From:       holiday.Date.Format("2006-01-02T00:00:00"),
+			Till:       holiday.Date.AddDate(0, 0, 1).Format("2006-01-02T00:00:00"),
+			IsAllDay:   true,
+			ShowAs:     CALENDAR_SHOW_AS_OUT_OF_OFFICE,
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAbsenceFeedEntries(t *testing.T) {
+	nettoDays := 1.0
+	declined := SIGNED_STATUS_DECLINED
+	user := User{FirstName: "Erika", LastName: "Mustermann"}
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	absences := []Absence{
+		{
+			AbsenceFrom:   day,
+			AbsenceTill:   day,
+			NettoDays:     &nettoDays,
+			User:          &user,
+			AbsenceReason: AbsenceReason{Description: "Krankheit"},
+		},
+		{
+			AbsenceFrom:   day.AddDate(0, 0, 7),
+			AbsenceTill:   day.AddDate(0, 0, 7),
+			NettoDays:     &nettoDays,
+			User:          &user,
+			AbsenceReason: AbsenceReason{Description: "Urlaub"},
+			SignedStatus:  &declined,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		showRealReason bool
+		withUserName   bool
+		want           string
+	}{
+		{name: "personal", showRealReason: true, want: "Krankheit"},
+		{name: "team", showRealReason: false, withUserName: true, want: "Erika Mustermann: Abwesend"},
+		{name: "team as administrator", showRealReason: true, withUserName: true, want: "Erika Mustermann: Krankheit"},
+	}
+
+	for _, test := range tests {
+		entries := GetAbsenceFeedEntries(absences, nil, test.showRealReason, test.withUserName)
+		if len(entries) != 1 {
+			t.Fatalf("%s: want 1 entry without declined absence, got %d", test.name, len(entries))
+		}
+
+		if entries[0].Subject != test.want {
+			t.Errorf("%s: want subject %s, got %s", test.name, test.want, entries[0].Subject)
+		}
+
+		if entries[0].From != "2024-07-01T00:00:00" || entries[0].Till != "2024-07-02T00:00:00" || !entries[0].IsAllDay {
+			t.Errorf("%s: want all day entry on 2024-07-01, got %+v", test.name, entries[0])
+		}
+	}
+}
@@ -12,7 +12,7 @@ import (
 type StringArray []string
 
 func (s *StringArray) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
+	bytes, ok := getJSONBytes(value)
 	if !ok {
 		return fmt.Errorf("failed to unmarshal JSONB value: %v", value)
 	}
@@ -30,7 +30,16 @@ func (s StringArray) Value() (driver.Value, error) {
 	return json.Marshal(&s)
 }
 
+func (s StringArray) GormDataType() string {
+	return "json"
+}
+
 func (s StringArray) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return getJSONDBDataType(db)
+}
+
+// getJSONDBDataType returns the column type for json values of the dialect.
+func getJSONDBDataType(db *gorm.DB) string {
 	switch db.Dialector.Name() {
 	case "mysql", "sqlite":
 		return "JSON"
@@ -39,3 +48,15 @@ func (s StringArray) GormDBDataType(db *gorm.DB, field *schema.Field) string {
 	}
 	return ""
 }
+
+// getJSONBytes returns the raw json of a scanned column, sqlite returns json
+// columns as string.
+func getJSONBytes(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	}
+	return nil, false
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestJSONScan(t *testing.T) {
+	testData := []struct {
+		Name  string
+		Value interface{}
+	}{
+		{Name: "postgres", Value: []byte(`[{"Source":"timestamp","Value":2.5,"Factor":1}]`)},
+		{Name: "sqlite", Value: `[{"Source":"timestamp","Value":2.5,"Factor":1}]`},
+	}
+
+	for _, test := range testData {
+		var summary OvertimeSummary
+		err := summary.Scan(test.Value)
+		if err != nil {
+			t.Errorf("%s: want no error, got %s", test.Name, err)
+			continue
+		}
+
+		if len(summary) != 1 || summary[0].Value != 2.5 {
+			t.Errorf("%s: want one entry with 2.5, got %v", test.Name, summary)
+		}
+	}
+
+	var options StringArray
+	err := options.Scan(42)
+	if err == nil {
+		t.Errorf("int: want error, got %v", options)
+	}
+}
@@ -0,0 +1,265 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+)
+
+type ComplianceReason string
+
+const (
+	COMPLIANCE_REASON_BROKEN_TIMESTAMP ComplianceReason = "broken_timestamp"
+	COMPLIANCE_REASON_CORRECTION       ComplianceReason = "correction"
+	COMPLIANCE_REASON_OVERTIME         ComplianceReason = "overtime"
+	COMPLIANCE_REASON_REST_PERIOD      ComplianceReason = "rest_period"
+	COMPLIANCE_REASON_DAILY_MAXIMUM    ComplianceReason = "daily_maximum"
+	COMPLIANCE_REASON_AVERAGE_MAXIMUM  ComplianceReason = "average_maximum"
+	COMPLIANCE_REASON_SUNDAY_WORK      ComplianceReason = "sunday_work"
+	COMPLIANCE_REASON_HOLIDAY_WORK     ComplianceReason = "holiday_work"
+)
+
+const (
+	COMPLIANCE_MINIMUM_REST_HOURS   = 11.0
+	COMPLIANCE_MAXIMUM_DAILY_HOURS  = 10.0
+	COMPLIANCE_MAXIMUM_AVERAGE      = 8.0
+	COMPLIANCE_AVERAGE_PERIOD_MONTH = 6
+)
+
+type ComplianceViolation struct {
+	Date   time.Time
+	Reason ComplianceReason
+	Value  float64
+	Limit  float64
+}
+
+type ComplianceUserViolations struct {
+	User       UserResponse
+	Violations []ComplianceViolation
+}
+
+// ComplianceInput holds the calculated days of a user. TimestampGroups may
+// contain days before From, they are only used as history, e.g. for the
+// average working hours. Days after Now haven't elapsed yet, the zero value
+// treats the whole period as elapsed.
+type ComplianceInput struct {
+	From            time.Time
+	Till            time.Time
+	Now             time.Time
+	TimestampGroups []TimestampGroup
+	Holidays        Holidays
+}
+
+func (i *ComplianceInput) isInPeriod(date time.Time) bool {
+	day := helper.GetDayDate(date)
+	return !day.Before(helper.GetDayDate(i.From)) && !day.After(helper.GetDayDate(i.Till))
+}
+
+type ComplianceRule func(input ComplianceInput) []ComplianceViolation
+
+var ComplianceRules = []ComplianceRule{
+	CheckRestPeriod,
+	CheckDailyMaximum,
+	CheckAverageMaximum,
+	CheckSundayAndHolidayWork,
+}
+
+func CheckCompliance(input ComplianceInput) []ComplianceViolation {
+	violations := []ComplianceViolation{}
+	for _, rule := range ComplianceRules {
+		violations = append(violations, rule(input)...)
+	}
+
+	slices.SortStableFunc(violations, func(a, b ComplianceViolation) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return violations
+}
+
+// CheckRestPeriod flags a check-in when the rest since the last checkout of
+// the previous day is shorter than 11 hours.
+func CheckRestPeriod(input ComplianceInput) []ComplianceViolation {
+	timestamps := []Timestamp{}
+	for _, group := range input.TimestampGroups {
+		for _, timestamp := range group.Timestamps {
+			if timestamp.IsComplete() {
+				timestamps = append(timestamps, timestamp)
+			}
+		}
+	}
+
+	slices.SortFunc(timestamps, func(a, b Timestamp) int {
+		return a.ComingTimestamp.Compare(b.ComingTimestamp)
+	})
+
+	violations := []ComplianceViolation{}
+	for i := 1; i < len(timestamps); i++ {
+		previous := timestamps[i-1]
+		current := timestamps[i]
+
+		if helper.GetDayDate(previous.ComingTimestamp) == helper.GetDayDate(current.ComingTimestamp) {
+			continue
+		}
+
+		if !input.isInPeriod(current.ComingTimestamp) {
+			continue
+		}
+
+		restHours := current.ComingTimestamp.Sub(previous.GoingTimestamp).Hours()
+		if restHours < COMPLIANCE_MINIMUM_REST_HOURS {
+			violations = append(violations, ComplianceViolation{
+				Date:   helper.GetDayDate(current.ComingTimestamp),
+				Reason: COMPLIANCE_REASON_REST_PERIOD,
+				Value:  restHours,
+				Limit:  COMPLIANCE_MINIMUM_REST_HOURS,
+			})
+		}
+	}
+
+	return violations
+}
+
+func CheckDailyMaximum(input ComplianceInput) []ComplianceViolation {
+	violations := []ComplianceViolation{}
+	for _, group := range input.TimestampGroups {
+		if !input.isInPeriod(group.Date) {
+			continue
+		}
+
+		if group.WorkingHours > COMPLIANCE_MAXIMUM_DAILY_HOURS {
+			violations = append(violations, ComplianceViolation{
+				Date:   group.Date,
+				Reason: COMPLIANCE_REASON_DAILY_MAXIMUM,
+				Value:  group.WorkingHours,
+				Limit:  COMPLIANCE_MAXIMUM_DAILY_HOURS,
+			})
+		}
+	}
+
+	return violations
+}
+
+// CheckAverageMaximum calculates the average working hours per working day
+// (monday till saturday without holidays) over the last six months ending
+// with the checked period. Only the elapsed days of the period are counted.
+func CheckAverageMaximum(input ComplianceInput) []ComplianceViolation {
+	till := helper.GetDayDate(input.Till)
+	from := time.Date(till.Year(), till.Month()-COMPLIANCE_AVERAGE_PERIOD_MONTH+1, 1, 0, 0, 0, 0, till.Location())
+
+	if !input.Now.IsZero() && helper.GetDayDate(input.Now).Before(till) {
+		till = helper.GetDayDate(input.Now)
+	}
+
+	workingDays := 0
+	for day := from; !day.After(till); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Sunday && !input.Holidays.ContainsFullDay(day) {
+			workingDays++
+		}
+	}
+
+	if workingDays == 0 {
+		return nil
+	}
+
+	workingHours := 0.0
+	for _, group := range input.TimestampGroups {
+		day := helper.GetDayDate(group.Date)
+		if day.Before(from) || day.After(till) {
+			continue
+		}
+
+		workingHours += group.WorkingHours
+	}
+
+	average := workingHours / float64(workingDays)
+	if average <= COMPLIANCE_MAXIMUM_AVERAGE {
+		return nil
+	}
+
+	return []ComplianceViolation{
+		{
+			Date:   till,
+			Reason: COMPLIANCE_REASON_AVERAGE_MAXIMUM,
+			Value:  average,
+			Limit:  COMPLIANCE_MAXIMUM_AVERAGE,
+		},
+	}
+}
+
+func CheckSundayAndHolidayWork(input ComplianceInput) []ComplianceViolation {
+	violations := []ComplianceViolation{}
+	for _, group := range input.TimestampGroups {
+		if !input.isInPeriod(group.Date) || group.WorkingHours <= 0 {
+			continue
+		}
+
+		if group.Date.Weekday() == time.Sunday {
+			violations = append(violations, ComplianceViolation{
+				Date:   group.Date,
+				Reason: COMPLIANCE_REASON_SUNDAY_WORK,
+				Value:  group.WorkingHours,
+			})
+		} else if input.Holidays.ContainsFullDay(group.Date) {
+			violations = append(violations, ComplianceViolation{
+				Date:   group.Date,
+				Reason: COMPLIANCE_REASON_HOLIDAY_WORK,
+				Value:  group.WorkingHours,
+			})
+		}
+	}
+
+	return violations
+}
+
+// GetComplianceSuspiciousTimestamps reports the violations at the timestamps
+// of their days, at the first one of the day. The average maximum is reported
+// at the last timestamp of its period. Every timestamp is reported once with
+// the reason of its first violation.
+func GetComplianceSuspiciousTimestamps(groups []TimestampGroup, violations []ComplianceViolation) []TimestampSuspiciousResponse {
+	timestamps := []Timestamp{}
+	for _, group := range groups {
+		for _, timestamp := range group.Timestamps {
+			if timestamp.IsComplete() {
+				timestamps = append(timestamps, timestamp)
+			}
+		}
+	}
+
+	slices.SortFunc(timestamps, func(a, b Timestamp) int {
+		return a.ComingTimestamp.Compare(b.ComingTimestamp)
+	})
+
+	result := []TimestampSuspiciousResponse{}
+	for _, violation := range violations {
+		index := -1
+		for i, timestamp := range timestamps {
+			day := helper.GetDayDate(timestamp.ComingTimestamp)
+			if violation.Reason == COMPLIANCE_REASON_AVERAGE_MAXIMUM && !day.After(violation.Date) {
+				index = i
+			} else if day.Equal(violation.Date) {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			continue
+		}
+
+		timestamp := timestamps[index]
+		if slices.ContainsFunc(result, func(item TimestampSuspiciousResponse) bool {
+			return item.ID == timestamp.ID
+		}) {
+			continue
+		}
+
+		result = append(result, TimestampSuspiciousResponse{
+			Timestamp:        timestamp,
+			SuspiciousReason: violation.Reason,
+		})
+	}
+
+	return result
+}
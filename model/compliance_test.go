@@ -0,0 +1,152 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckCompliance(t *testing.T) {
+	group := func(date time.Time, workingHours float64, timestamps ...Timestamp) TimestampGroup {
+		return TimestampGroup{
+			Date:         date,
+			Timestamps:   timestamps,
+			WorkingHours: workingHours,
+		}
+	}
+
+	// nine hours from monday till saturday since october till the 5th of march
+	averageGroups := []TimestampGroup{}
+	for day := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC); !day.After(time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Sunday {
+			averageGroups = append(averageGroups, group(day, 9))
+		}
+	}
+
+	tests := []struct {
+		name  string // description of this test case
+		input ComplianceInput
+		want  []ComplianceReason
+	}{
+		{
+			name: "Short rest period",
+			input: ComplianceInput{
+				From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				Till: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+				TimestampGroups: []TimestampGroup{
+					group(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), 7.5, Timestamp{
+						ComingTimestamp: time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC),
+						GoingTimestamp:  time.Date(2025, 3, 3, 22, 0, 0, 0, time.UTC),
+					}),
+					group(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), 7.5, Timestamp{
+						ComingTimestamp: time.Date(2025, 3, 4, 6, 0, 0, 0, time.UTC),
+						GoingTimestamp:  time.Date(2025, 3, 4, 14, 0, 0, 0, time.UTC),
+					}),
+				},
+			},
+			want: []ComplianceReason{COMPLIANCE_REASON_REST_PERIOD},
+		},
+		{
+			name: "Long day on a sunday",
+			input: ComplianceInput{
+				From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				Till: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+				TimestampGroups: []TimestampGroup{
+					group(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), 10.5),
+				},
+			},
+			want: []ComplianceReason{COMPLIANCE_REASON_DAILY_MAXIMUM, COMPLIANCE_REASON_SUNDAY_WORK},
+		},
+		{
+			name: "Work on a holiday before the period is ignored",
+			input: ComplianceInput{
+				From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				Till: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+				TimestampGroups: []TimestampGroup{
+					group(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 8),
+				},
+				Holidays: Holidays{
+					{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			want: []ComplianceReason{},
+		},
+		{
+			name: "Average over the elapsed days",
+			input: ComplianceInput{
+				From:            time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				Till:            time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+				Now:             time.Date(2025, 3, 5, 18, 0, 0, 0, time.UTC),
+				TimestampGroups: averageGroups,
+			},
+			want: []ComplianceReason{COMPLIANCE_REASON_AVERAGE_MAXIMUM},
+		},
+		{
+			name: "Average over the whole month",
+			input: ComplianceInput{
+				From:            time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				Till:            time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+				TimestampGroups: averageGroups,
+			},
+			want: []ComplianceReason{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckCompliance(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d violations (%v), want %v", len(got), got, tt.want)
+			}
+
+			for i, violation := range got {
+				if violation.Reason != tt.want[i] {
+					t.Errorf("Reason = %v, want %v", violation.Reason, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetComplianceSuspiciousTimestamps(t *testing.T) {
+	timestamp := func(id uint, day int, comingHour int, goingHour int) Timestamp {
+		item := Timestamp{
+			ComingTimestamp: time.Date(2025, 3, day, comingHour, 0, 0, 0, time.UTC),
+			GoingTimestamp:  time.Date(2025, 3, day, goingHour, 0, 0, 0, time.UTC),
+		}
+		item.ID = id
+		return item
+	}
+
+	groups := []TimestampGroup{
+		{Date: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), Timestamps: []Timestamp{timestamp(2, 3, 13, 23), timestamp(1, 3, 6, 12)}},
+		{Date: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), Timestamps: []Timestamp{timestamp(3, 4, 6, 14)}},
+	}
+
+	violation := func(day int, reason ComplianceReason) ComplianceViolation {
+		return ComplianceViolation{Date: time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC), Reason: reason}
+	}
+
+	testData := []struct {
+		Name       string
+		Violations []ComplianceViolation
+		Want       []uint
+	}{
+		{Name: "first timestamp of the day", Violations: []ComplianceViolation{violation(3, COMPLIANCE_REASON_DAILY_MAXIMUM)}, Want: []uint{1}},
+		{Name: "reported once", Violations: []ComplianceViolation{violation(4, COMPLIANCE_REASON_REST_PERIOD), violation(4, COMPLIANCE_REASON_DAILY_MAXIMUM)}, Want: []uint{3}},
+		{Name: "day without timestamps", Violations: []ComplianceViolation{violation(2, COMPLIANCE_REASON_SUNDAY_WORK)}, Want: []uint{}},
+		{Name: "average at the last timestamp", Violations: []ComplianceViolation{violation(31, COMPLIANCE_REASON_AVERAGE_MAXIMUM)}, Want: []uint{3}},
+	}
+
+	for _, test := range testData {
+		got := GetComplianceSuspiciousTimestamps(groups, test.Violations)
+		if len(got) != len(test.Want) {
+			t.Fatalf("%s: want %d timestamps, got %+v", test.Name, len(test.Want), got)
+		}
+
+		for i, item := range got {
+			if item.ID != test.Want[i] || item.SuspiciousReason != test.Violations[0].Reason {
+				t.Errorf("%s: want timestamp %d with %s, got %d with %s", test.Name, test.Want[i], test.Violations[0].Reason, item.ID, item.SuspiciousReason)
+			}
+		}
+	}
+}
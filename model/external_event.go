@@ -0,0 +1,65 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+const EXTERNAL_EVENT_SYNC_MAX_BACKOFF = 6 * time.Hour
+
+// ExternalEventSync tracks the synchronisation of an external event. Failed
+// synchronisations are retried with an exponential backoff.
+type ExternalEventSync struct {
+	SyncAttempts  int
+	NextSyncAt    *time.Time
+	LastSyncError *string
+	LastSyncAt    *time.Time
+}
+
+// GetRetryBackoff doubles the waiting time with every failed attempt, starting
+// with one minute.
+func GetRetryBackoff(attempts int, maxBackoff time.Duration) time.Duration {
+	backoff := time.Minute * time.Duration(math.Pow(2, float64(attempts-1)))
+	if backoff > maxBackoff || backoff <= 0 {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+func (s *ExternalEventSync) IsSyncDue(now time.Time) bool {
+	return s.NextSyncAt == nil || !now.Before(*s.NextSyncAt)
+}
+
+// IsChangedSince returns true if the event was never synchronised or one of
+// the given times is after the last synchronisation.
+func (s *ExternalEventSync) IsChangedSince(changes ...time.Time) bool {
+	if s.LastSyncAt == nil {
+		return true
+	}
+
+	for _, change := range changes {
+		if change.After(*s.LastSyncAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *ExternalEventSync) SyncFailed(err error, now time.Time) {
+	s.SyncAttempts++
+
+	nextSyncAt := now.Add(GetRetryBackoff(s.SyncAttempts, EXTERNAL_EVENT_SYNC_MAX_BACKOFF))
+	message := err.Error()
+
+	s.NextSyncAt = &nextSyncAt
+	s.LastSyncError = &message
+}
+
+func (s *ExternalEventSync) SyncSucceeded(now time.Time) {
+	s.LastSyncAt = &now
+	s.SyncAttempts = 0
+	s.NextSyncAt = nil
+	s.LastSyncError = nil
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExternalEventSyncBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)
+	sync := ExternalEventSync{}
+
+	if !sync.IsSyncDue(now) {
+		t.Fatalf("want new event to be due")
+	}
+
+	wanted := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute}
+	for _, backoff := range wanted {
+		sync.SyncFailed(errors.New("graph unavailable"), now)
+		if !sync.NextSyncAt.Equal(now.Add(backoff)) {
+			t.Errorf("attempt %d: want next sync at %s, got %s", sync.SyncAttempts, now.Add(backoff), sync.NextSyncAt)
+		}
+	}
+
+	if sync.IsSyncDue(now.Add(time.Minute)) {
+		t.Errorf("want event not due during backoff")
+	}
+
+	for range 20 {
+		sync.SyncFailed(errors.New("graph unavailable"), now)
+	}
+	if !sync.NextSyncAt.Equal(now.Add(EXTERNAL_EVENT_SYNC_MAX_BACKOFF)) {
+		t.Errorf("want backoff capped at %s, got %s", EXTERNAL_EVENT_SYNC_MAX_BACKOFF, sync.NextSyncAt.Sub(now))
+	}
+
+	sync.SyncSucceeded(now)
+	if sync.SyncAttempts != 0 || sync.LastSyncError != nil || !sync.IsSyncDue(now) {
+		t.Errorf("want reset after success, got %+v", sync)
+	}
+
+	if sync.IsChangedSince(now.Add(-time.Minute)) || !sync.IsChangedSince(now.Add(-time.Minute), now.Add(time.Minute)) {
+		t.Errorf("want changed only for changes after the last sync at %s", now)
+	}
+}
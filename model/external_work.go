@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 type ExternalWorkCompensationHourSlot struct {
@@ -23,7 +24,7 @@ type ExternalWorkCompensationHourSlot struct {
 type ExternalWorkCompensationHourSlots []ExternalWorkCompensationHourSlot
 
 func (e *ExternalWorkCompensationHourSlots) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
+	bytes, ok := getJSONBytes(value)
 	if !ok {
 		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
 	}
@@ -47,10 +48,18 @@ func (e ExternalWorkCompensationHourSlots) Value() (driver.Value, error) {
 	return bytes, nil
 }
 
+func (e ExternalWorkCompensationHourSlots) GormDataType() string {
+	return "json"
+}
+
+func (e ExternalWorkCompensationHourSlots) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return getJSONDBDataType(db)
+}
+
 type ExternalWorkCompensationAdditionalOptions map[string]float64
 
 func (e *ExternalWorkCompensationAdditionalOptions) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
+	bytes, ok := getJSONBytes(value)
 	if !ok {
 		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
 	}
@@ -74,6 +83,14 @@ func (e ExternalWorkCompensationAdditionalOptions) Value() (driver.Value, error)
 	return bytes, nil
 }
 
+func (e ExternalWorkCompensationAdditionalOptions) GormDataType() string {
+	return "json"
+}
+
+func (e ExternalWorkCompensationAdditionalOptions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return getJSONDBDataType(db)
+}
+
 func (e ExternalWorkCompensationAdditionalOptions) Keys() []string {
 	allOptions := []string{}
 	for key, _ := range e {
@@ -87,9 +104,9 @@ func (e ExternalWorkCompensationAdditionalOptions) Keys() []string {
 type ExternalWorkCompensation struct {
 	gorm.Model
 	IsoCountryCodeA2            string                                    `gorm:"index:idx_compensation,unique;index"`
-	WithSocialInsuranceSlots    ExternalWorkCompensationHourSlots         `gorm:"type:jsonb" sql:"json"`
-	WithoutSocialInsuranceSlots ExternalWorkCompensationHourSlots         `gorm:"type:jsonb" sql:"json"`
-	AdditionalOptions           ExternalWorkCompensationAdditionalOptions `gorm:"type:jsonb" sql:"json"`
+	WithSocialInsuranceSlots    ExternalWorkCompensationHourSlots         `sql:"json"`
+	WithoutSocialInsuranceSlots ExternalWorkCompensationHourSlots         `sql:"json"`
+	AdditionalOptions           ExternalWorkCompensationAdditionalOptions `sql:"json"`
 	ValidFrom                   time.Time                                 `gorm:"index:idx_compensation,unique;index"`
 	ValidTill                   time.Time                                 `gorm:"index:idx_compensation,unique;index"`
 	PrivateCarKmCompensation    float64
@@ -134,7 +151,7 @@ func (e *ExternalWork) IsEditable() bool {
 	return e.Status == EXTERNAL_WORK_STATUS_PLANNED || e.Status == EXTERNAL_WORK_STATUS_MISSING_INFO
 }
 
-func (e *ExternalWork) Calculate(holidays Holidays) ExternalWorkCalculated {
+func (e *ExternalWork) Calculate(holidays Holidays, workTimeModels UserWorkTimeModels) ExternalWorkCalculated {
 	externalWorkCalculated := ExternalWorkCalculated{
 		ExternalWork:                        *e,
 		TotalOvertimeHours:                  0,
@@ -148,7 +165,7 @@ func (e *ExternalWork) Calculate(holidays Holidays) ExternalWorkCalculated {
 
 	for _, workExpense := range e.WorkExpanses {
 		workExpense.ExternalWork = *e
-		workExpenseCalculated := workExpense.Calculate(holidays)
+		workExpenseCalculated := workExpense.Calculate(holidays, workTimeModels)
 
 		externalWorkCalculated.TotalExpensesWithSocialInsurance += workExpenseCalculated.ExpensesWithSocialInsurance
 		externalWorkCalculated.TotalExpensesWithoutSocialInsurance += workExpenseCalculated.ExpensesWithoutSocialInsurance
@@ -191,12 +208,11 @@ type ExternalWorkExpense struct {
 	OnSiteTill             *time.Time
 	Place                  string
 	TravelWithPrivateCarKm float64
-	AdditionalOptions      StringArray `gorm:"type:jsonb" sql:"json"`
+	AdditionalOptions      StringArray `sql:"json"`
 }
 
-func (e *ExternalWorkExpense) Calculate(holidays Holidays) ExternalWorkExpenseCalculated {
-	workTimeModel := DefaultWorkTimeModel()
-	neededWorkingHours := workTimeModel.GetWorkingHoursForDay(e.Date, holidays)
+func (e *ExternalWorkExpense) Calculate(holidays Holidays, workTimeModels UserWorkTimeModels) ExternalWorkExpenseCalculated {
+	neededWorkingHours := workTimeModels.GetWorkingHoursForDay(e.Date, holidays)
 
 	expensesWithSocialInsurance := 0.0
 	expensesWithoutSocialInsurance := 0.0
@@ -334,4 +350,5 @@ type ExternalWorkExternalEvent struct {
 	ExternalEventID       string
 	ExternalEventProvider ExternalEventProvider
 	Update                bool
+	ExternalEventSync
 }
@@ -58,7 +58,7 @@ func TestExternalWorkExpense_Calculate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.input.Calculate()
+			got := tt.input.Calculate(nil, nil)
 			if got.TotalAwayHours != tt.want.TotalAwayHours {
 				t.Errorf("TotalAwayHours = %v, want %v", got.TotalAwayHours, tt.want.TotalAwayHours)
 			}
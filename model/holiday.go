@@ -17,7 +17,8 @@ type HolidaySource string
 type Holiday struct {
 	gorm.Model
 	Name                    string
-	Date                    time.Time `gorm:"uniqueIndex"`
+	Date                    time.Time `gorm:"uniqueIndex:idx_holiday_location_date"`
+	LocationID              *uint     `gorm:"uniqueIndex:idx_holiday_location_date"`
 	State                   string
 	Source                  HolidaySource `gorm:"default: imported"`
 	EmployeeDaySubstraction int
@@ -25,20 +26,23 @@ type Holiday struct {
 
 type HolidayCustom struct {
 	gorm.Model
-	Name                    string `gorm:"uniqueIndex"`
+	Name                    string `gorm:"uniqueIndex;size:191"`
 	Date                    *time.Time
 	Month                   *int
 	Day                     *int
 	Yearly                  *bool `gorm:"default: true"`
+	LocationID              *uint
 	EmployeeDaySubstraction int
 }
 
 type HolidayCustomCreateRequest struct {
-	Name   string `binding:"required"`
-	Date   *time.Time
-	Month  *int
-	Day    *int
-	Yearly bool
+	Name                    string `binding:"required"`
+	Date                    *time.Time
+	Month                   *int
+	Day                     *int
+	Yearly                  bool
+	LocationID              *uint
+	EmployeeDaySubstraction int `binding:"min=0,max=100"`
 }
 
 type Holidays []Holiday
@@ -52,17 +56,43 @@ func (hi HolidayImport) GetDate() (time.Time, error) {
 	return time.Parse("2006-01-02", hi.Datum)
 }
 
+// GetDayOffFactor returns the share of the working day which is off.
+// EmployeeDaySubstraction is the percentage, 0 means a full day off.
+func (h *Holiday) GetDayOffFactor() float64 {
+	if h.EmployeeDaySubstraction <= 0 || h.EmployeeDaySubstraction >= 100 {
+		return 1
+	}
+
+	return float64(h.EmployeeDaySubstraction) / 100
+}
+
+func (h *Holiday) IsFullDay() bool {
+	return h.GetDayOffFactor() >= 1
+}
+
 func (h Holidays) Contains(date time.Time) bool {
+	return h.GetDayOffFactor(date) > 0
+}
+
+// ContainsFullDay reports if the date is a holiday without any working time.
+func (h Holidays) ContainsFullDay(date time.Time) bool {
+	return h.GetDayOffFactor(date) >= 1
+}
+
+// GetDayOffFactor returns the share of the working day which is off on the
+// date, 0 if it isn't a holiday. The largest share wins if holidays overlap.
+func (h Holidays) GetDayOffFactor(date time.Time) float64 {
+	factor := 0.0
 	for _, holiday := range h {
 		if helper.GetDayDate(holiday.Date) == helper.GetDayDate(date) {
-			return true
+			factor = max(factor, holiday.GetDayOffFactor())
 		}
 	}
 
-	return false
+	return factor
 }
 
-func GetNeededHoursForMonth(holidays Holidays, year int, month int) float64 {
+func GetNeededHoursForMonth(holidays Holidays, workTimeModels UserWorkTimeModels, year int, month int) float64 {
 	firstOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
 	lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
 
@@ -71,23 +101,7 @@ func GetNeededHoursForMonth(holidays Holidays, year int, month int) float64 {
 	currentDay := firstOfMonth
 
 	for !currentDay.After(lastOfMonth) {
-		skip := false
-
-		if currentDay.Weekday() == time.Saturday || currentDay.Weekday() == time.Sunday {
-			skip = true
-		}
-
-		if holidays.Contains(currentDay) {
-			skip = true
-		}
-
-		if !skip {
-			if worktime, exists := DefaultWorkTimeModel().HoursPerWeekdayException[currentDay.Weekday()]; exists {
-				hours += worktime
-			} else {
-				hours += DefaultWorkTimeModel().DefaultHoursPerWeekday
-			}
-		}
+		hours += workTimeModels.GetWorkingHoursForDay(currentDay, holidays)
 		currentDay = currentDay.AddDate(0, 0, 1)
 	}
 
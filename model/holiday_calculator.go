@@ -0,0 +1,182 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+type HolidayState string
+
+const (
+	HOLIDAY_STATE_BADEN_WUERTTEMBERG     HolidayState = "BW"
+	HOLIDAY_STATE_BAYERN                 HolidayState = "BY"
+	HOLIDAY_STATE_BERLIN                 HolidayState = "BE"
+	HOLIDAY_STATE_BRANDENBURG            HolidayState = "BB"
+	HOLIDAY_STATE_BREMEN                 HolidayState = "HB"
+	HOLIDAY_STATE_HAMBURG                HolidayState = "HH"
+	HOLIDAY_STATE_HESSEN                 HolidayState = "HE"
+	HOLIDAY_STATE_MECKLENBURG_VORPOMMERN HolidayState = "MV"
+	HOLIDAY_STATE_NIEDERSACHSEN          HolidayState = "NI"
+	HOLIDAY_STATE_NORDRHEIN_WESTFALEN    HolidayState = "NW"
+	HOLIDAY_STATE_RHEINLAND_PFALZ        HolidayState = "RP"
+	HOLIDAY_STATE_SAARLAND               HolidayState = "SL"
+	HOLIDAY_STATE_SACHSEN                HolidayState = "SN"
+	HOLIDAY_STATE_SACHSEN_ANHALT         HolidayState = "ST"
+	HOLIDAY_STATE_SCHLESWIG_HOLSTEIN     HolidayState = "SH"
+	HOLIDAY_STATE_THUERINGEN             HolidayState = "TH"
+)
+
+const HOLIDAY_STATE_DEFAULT = HOLIDAY_STATE_NIEDERSACHSEN
+
+var ErrHolidayStateUnknown = errors.New("unknown holiday state")
+
+var HolidayStates = []HolidayState{
+	HOLIDAY_STATE_BADEN_WUERTTEMBERG,
+	HOLIDAY_STATE_BAYERN,
+	HOLIDAY_STATE_BERLIN,
+	HOLIDAY_STATE_BRANDENBURG,
+	HOLIDAY_STATE_BREMEN,
+	HOLIDAY_STATE_HAMBURG,
+	HOLIDAY_STATE_HESSEN,
+	HOLIDAY_STATE_MECKLENBURG_VORPOMMERN,
+	HOLIDAY_STATE_NIEDERSACHSEN,
+	HOLIDAY_STATE_NORDRHEIN_WESTFALEN,
+	HOLIDAY_STATE_RHEINLAND_PFALZ,
+	HOLIDAY_STATE_SAARLAND,
+	HOLIDAY_STATE_SACHSEN,
+	HOLIDAY_STATE_SACHSEN_ANHALT,
+	HOLIDAY_STATE_SCHLESWIG_HOLSTEIN,
+	HOLIDAY_STATE_THUERINGEN,
+}
+
+func (s HolidayState) IsValid() bool {
+	return slices.Contains(HolidayStates, s)
+}
+
+func (s HolidayState) isOneOf(states ...HolidayState) bool {
+	return slices.Contains(states, s)
+}
+
+// GetEasterSunday calculates easter sunday of the gregorian calendar with the
+// anonymous gregorian algorithm (Meeus/Jones/Butcher).
+func GetEasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// getRepentanceDay returns Buß- und Bettag, the wednesday before the 23rd of
+// november.
+func getRepentanceDay(year int) time.Time {
+	date := time.Date(year, time.November, 22, 0, 0, 0, 0, time.UTC)
+	for date.Weekday() != time.Wednesday {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+// CalculateHolidays returns the public holidays of the german state in the
+// given year. Holidays which are only observed in some communities of a state
+// (e.g. Fronleichnam in parts of Sachsen, Mariä Himmelfahrt in the catholic
+// communities of Bayern) are not included.
+func CalculateHolidays(year int, state HolidayState) (Holidays, error) {
+	if !state.IsValid() {
+		return Holidays{}, ErrHolidayStateUnknown
+	}
+
+	easter := GetEasterSunday(year)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	holidays := Holidays{}
+	add := func(name string, date time.Time) {
+		holidays = append(holidays, Holiday{
+			Name:   name,
+			Date:   date,
+			State:  string(state),
+			Source: HOLIDAY_SOURCE_IMPORTED,
+		})
+	}
+
+	add("Neujahrstag", date(time.January, 1))
+	add("Karfreitag", easter.AddDate(0, 0, -2))
+	add("Ostermontag", easter.AddDate(0, 0, 1))
+	add("Tag der Arbeit", date(time.May, 1))
+	add("Christi Himmelfahrt", easter.AddDate(0, 0, 39))
+	add("Pfingstmontag", easter.AddDate(0, 0, 50))
+	add("Tag der Deutschen Einheit", date(time.October, 3))
+	add("1. Weihnachtstag", date(time.December, 25))
+	add("2. Weihnachtstag", date(time.December, 26))
+
+	if state.isOneOf(HOLIDAY_STATE_BADEN_WUERTTEMBERG, HOLIDAY_STATE_BAYERN, HOLIDAY_STATE_SACHSEN_ANHALT) {
+		add("Heilige Drei Könige", date(time.January, 6))
+	}
+
+	if (state == HOLIDAY_STATE_BERLIN && year >= 2019) || (state == HOLIDAY_STATE_MECKLENBURG_VORPOMMERN && year >= 2023) {
+		add("Frauentag", date(time.March, 8))
+	}
+
+	if state == HOLIDAY_STATE_BRANDENBURG {
+		add("Ostersonntag", easter)
+		add("Pfingstsonntag", easter.AddDate(0, 0, 49))
+	}
+
+	if state == HOLIDAY_STATE_BERLIN && (year == 2020 || year == 2025) {
+		add("Tag der Befreiung", date(time.May, 8))
+	}
+
+	if state.isOneOf(HOLIDAY_STATE_BADEN_WUERTTEMBERG, HOLIDAY_STATE_BAYERN, HOLIDAY_STATE_HESSEN,
+		HOLIDAY_STATE_NORDRHEIN_WESTFALEN, HOLIDAY_STATE_RHEINLAND_PFALZ, HOLIDAY_STATE_SAARLAND) {
+		add("Fronleichnam", easter.AddDate(0, 0, 60))
+	}
+
+	if state == HOLIDAY_STATE_SAARLAND {
+		add("Mariä Himmelfahrt", date(time.August, 15))
+	}
+
+	if state == HOLIDAY_STATE_THUERINGEN && year >= 2019 {
+		add("Weltkindertag", date(time.September, 20))
+	}
+
+	reformationStates := []HolidayState{HOLIDAY_STATE_BRANDENBURG, HOLIDAY_STATE_MECKLENBURG_VORPOMMERN,
+		HOLIDAY_STATE_SACHSEN, HOLIDAY_STATE_SACHSEN_ANHALT, HOLIDAY_STATE_THUERINGEN}
+	if year >= 2018 {
+		reformationStates = append(reformationStates, HOLIDAY_STATE_BREMEN, HOLIDAY_STATE_HAMBURG,
+			HOLIDAY_STATE_NIEDERSACHSEN, HOLIDAY_STATE_SCHLESWIG_HOLSTEIN)
+	}
+	// the 500th anniversary of the reformation was a holiday in every state
+	if year == 2017 || state.isOneOf(reformationStates...) {
+		add("Reformationstag", date(time.October, 31))
+	}
+
+	if state.isOneOf(HOLIDAY_STATE_BADEN_WUERTTEMBERG, HOLIDAY_STATE_BAYERN, HOLIDAY_STATE_NORDRHEIN_WESTFALEN,
+		HOLIDAY_STATE_RHEINLAND_PFALZ, HOLIDAY_STATE_SAARLAND) {
+		add("Allerheiligen", date(time.November, 1))
+	}
+
+	if state == HOLIDAY_STATE_SACHSEN {
+		add("Buß- und Bettag", getRepentanceDay(year))
+	}
+
+	slices.SortFunc(holidays, func(a, b Holiday) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return holidays, nil
+}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEasterSunday(t *testing.T) {
+	tests := map[int]string{
+		2000: "2000-04-23",
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+
+	for year, want := range tests {
+		got := GetEasterSunday(year).Format("2006-01-02")
+		if got != want {
+			t.Errorf("%d: want %s, got %s", year, want, got)
+		}
+	}
+}
+
+func TestCalculateHolidays(t *testing.T) {
+	tests := []struct {
+		name      string
+		year      int
+		state     HolidayState
+		wantCount int
+		want      map[string]string
+		notWant   []string
+	}{
+		{
+			name:      "Niedersachsen 2024",
+			year:      2024,
+			state:     HOLIDAY_STATE_NIEDERSACHSEN,
+			wantCount: 10,
+			want: map[string]string{
+				"Karfreitag":          "2024-03-29",
+				"Ostermontag":         "2024-04-01",
+				"Christi Himmelfahrt": "2024-05-09",
+				"Pfingstmontag":       "2024-05-20",
+				"Reformationstag":     "2024-10-31",
+			},
+			notWant: []string{"Fronleichnam", "Buß- und Bettag"},
+		},
+		{
+			name:      "Niedersachsen 2016 without Reformationstag",
+			year:      2016,
+			state:     HOLIDAY_STATE_NIEDERSACHSEN,
+			wantCount: 9,
+			notWant:   []string{"Reformationstag"},
+		},
+		{
+			name:      "Reformation anniversary 2017",
+			year:      2017,
+			state:     HOLIDAY_STATE_BAYERN,
+			wantCount: 13,
+			want: map[string]string{
+				"Reformationstag": "2017-10-31",
+			},
+		},
+		{
+			name:      "Bayern 2025",
+			year:      2025,
+			state:     HOLIDAY_STATE_BAYERN,
+			wantCount: 12,
+			want: map[string]string{
+				"Heilige Drei Könige": "2025-01-06",
+				"Fronleichnam":        "2025-06-19",
+				"Allerheiligen":       "2025-11-01",
+			},
+			notWant: []string{"Reformationstag", "Mariä Himmelfahrt"},
+		},
+		{
+			name:      "Saarland 2025",
+			year:      2025,
+			state:     HOLIDAY_STATE_SAARLAND,
+			wantCount: 12,
+			want: map[string]string{
+				"Fronleichnam":      "2025-06-19",
+				"Mariä Himmelfahrt": "2025-08-15",
+				"Allerheiligen":     "2025-11-01",
+			},
+		},
+		{
+			name:      "Sachsen 2024",
+			year:      2024,
+			state:     HOLIDAY_STATE_SACHSEN,
+			wantCount: 11,
+			want: map[string]string{
+				"Buß- und Bettag": "2024-11-20",
+			},
+		},
+		{
+			name:      "Sachsen 2023",
+			year:      2023,
+			state:     HOLIDAY_STATE_SACHSEN,
+			wantCount: 11,
+			want: map[string]string{
+				"Buß- und Bettag": "2023-11-22",
+			},
+		},
+		{
+			name:      "Berlin 2025",
+			year:      2025,
+			state:     HOLIDAY_STATE_BERLIN,
+			wantCount: 11,
+			want: map[string]string{
+				"Frauentag":         "2025-03-08",
+				"Tag der Befreiung": "2025-05-08",
+			},
+		},
+		{
+			name:      "Brandenburg 2024",
+			year:      2024,
+			state:     HOLIDAY_STATE_BRANDENBURG,
+			wantCount: 12,
+			want: map[string]string{
+				"Ostersonntag":   "2024-03-31",
+				"Pfingstsonntag": "2024-05-19",
+			},
+		},
+		{
+			name:      "Thüringen 2024",
+			year:      2024,
+			state:     HOLIDAY_STATE_THUERINGEN,
+			wantCount: 11,
+			want: map[string]string{
+				"Weltkindertag": "2024-09-20",
+			},
+		},
+		{
+			name:      "Mecklenburg-Vorpommern 2022 without Frauentag",
+			year:      2022,
+			state:     HOLIDAY_STATE_MECKLENBURG_VORPOMMERN,
+			wantCount: 10,
+			notWant:   []string{"Frauentag"},
+		},
+	}
+
+	for _, test := range tests {
+		holidays, err := CalculateHolidays(test.year, test.state)
+		if err != nil {
+			t.Fatalf("%s: want holidays, got %s", test.name, err)
+		}
+
+		if len(holidays) != test.wantCount {
+			t.Errorf("%s: want %d holidays, got %d", test.name, test.wantCount, len(holidays))
+		}
+
+		got := map[string]time.Time{}
+		for _, holiday := range holidays {
+			got[holiday.Name] = holiday.Date
+			if holiday.State != string(test.state) {
+				t.Errorf("%s: want state %s, got %s", test.name, test.state, holiday.State)
+			}
+		}
+
+		for name, want := range test.want {
+			date, ok := got[name]
+			if !ok {
+				t.Errorf("%s: want %s, got none", test.name, name)
+				continue
+			}
+			if date.Format("2006-01-02") != want {
+				t.Errorf("%s: want %s on %s, got %s", test.name, name, want, date.Format("2006-01-02"))
+			}
+		}
+
+		for _, name := range test.notWant {
+			if _, ok := got[name]; ok {
+				t.Errorf("%s: want no %s, got %s", test.name, name, got[name].Format("2006-01-02"))
+			}
+		}
+	}
+
+	_, err := CalculateHolidays(2024, HolidayState("XX"))
+	if err != ErrHolidayStateUnknown {
+		t.Errorf("unknown state: want %s, got %v", ErrHolidayStateUnknown, err)
+	}
+}
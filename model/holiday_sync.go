@@ -0,0 +1,144 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+)
+
+const HOLIDAY_SYNC_MAX_YEARS = 10
+
+type HolidaySyncRequest struct {
+	FromYear int `binding:"required,min=1990"`
+	TillYear int `binding:"required,gtefield=FromYear"`
+}
+
+// HolidaySyncResult reports the holidays changed by a sync and the
+// recalculations caused by them.
+type HolidaySyncResult struct {
+	FromYear                   int
+	TillYear                   int
+	Added                      Holidays
+	Updated                    Holidays
+	Removed                    Holidays
+	RecalculatedAbsences       int
+	RecalculatedOvertimeMonths int
+}
+
+func NewHolidaySyncResult(fromYear int, tillYear int) HolidaySyncResult {
+	return HolidaySyncResult{
+		FromYear: fromYear,
+		TillYear: tillYear,
+		Added:    Holidays{},
+		Updated:  Holidays{},
+		Removed:  Holidays{},
+	}
+}
+
+func (r *HolidaySyncResult) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0 || len(r.Removed) > 0
+}
+
+// GetChangedMonths returns the first day of every month with a changed
+// holiday, sorted ascending.
+func (r *HolidaySyncResult) GetChangedMonths() []time.Time {
+	months := []time.Time{}
+
+	for _, changes := range []Holidays{r.Added, r.Updated, r.Removed} {
+		for _, holiday := range changes {
+			year, month, _ := holiday.Date.Date()
+			firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+			if !slices.ContainsFunc(months, firstOfMonth.Equal) {
+				months = append(months, firstOfMonth)
+			}
+		}
+	}
+
+	slices.SortFunc(months, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	return months
+}
+
+// GetCustomHolidays returns the holidays generated by the custom holidays for
+// the location in the given year. Custom holidays without location apply to
+// every location.
+func GetCustomHolidays(customHolidays []HolidayCustom, locationId uint, year int) Holidays {
+	holidays := Holidays{}
+
+	for _, custom := range customHolidays {
+		if custom.LocationID != nil && *custom.LocationID != locationId {
+			continue
+		}
+
+		var date *time.Time
+		if custom.Date != nil && custom.Date.Year() == year {
+			date = custom.Date
+		}
+
+		if custom.Day != nil && custom.Month != nil {
+			generatedDate := time.Date(year, time.Month(*custom.Month), *custom.Day, 0, 0, 0, 0, time.UTC)
+			date = &generatedDate
+		}
+
+		if date == nil {
+			continue
+		}
+
+		holidays = append(holidays, Holiday{
+			Name:                    custom.Name,
+			Date:                    helper.GetDayDate(*date),
+			Source:                  HOLIDAY_SOURCE_CUSTOM,
+			EmployeeDaySubstraction: custom.EmployeeDaySubstraction,
+		})
+	}
+
+	return holidays
+}
+
+// DiffHolidays compares the stored holidays of a location with the wanted
+// ones by day. Updated holidays keep their id with the wanted values, if
+// several wanted holidays share a day the first one wins.
+func DiffHolidays(existing Holidays, wanted Holidays) (Holidays, Holidays, Holidays) {
+	added := Holidays{}
+	updated := Holidays{}
+	removed := Holidays{}
+
+	wantedByDay := map[time.Time]Holiday{}
+	for _, holiday := range wanted {
+		day := helper.GetDayDate(holiday.Date)
+		if _, exists := wantedByDay[day]; exists {
+			continue
+		}
+
+		wantedByDay[day] = holiday
+		if !slices.ContainsFunc(existing, func(item Holiday) bool {
+			return helper.GetDayDate(item.Date).Equal(day)
+		}) {
+			added = append(added, holiday)
+		}
+	}
+
+	for _, holiday := range existing {
+		target, exists := wantedByDay[helper.GetDayDate(holiday.Date)]
+		if !exists {
+			removed = append(removed, holiday)
+			continue
+		}
+
+		if holiday.Name == target.Name && holiday.State == target.State &&
+			holiday.Source == target.Source && holiday.EmployeeDaySubstraction == target.EmployeeDaySubstraction {
+			continue
+		}
+
+		holiday.Name = target.Name
+		holiday.State = target.State
+		holiday.Source = target.Source
+		holiday.EmployeeDaySubstraction = target.EmployeeDaySubstraction
+		updated = append(updated, holiday)
+	}
+
+	return added, updated, removed
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffHolidays(t *testing.T) {
+	locationId := uint(1)
+	otherLocationId := uint(2)
+	day := 24
+	month := 12
+	customs := []HolidayCustom{
+		{Name: "Heiligabend", Day: &day, Month: &month, EmployeeDaySubstraction: 50},
+		{Name: "Betriebsausflug", Date: ptrTime(time.Date(2024, 6, 14, 10, 0, 0, 0, time.UTC)), LocationID: &otherLocationId},
+	}
+
+	custom := GetCustomHolidays(customs, locationId, 2024)
+	if len(custom) != 1 || !custom[0].Date.Equal(time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("custom: want only christmas eve, got %v", custom)
+	}
+
+	wanted := Holidays{
+		{Name: "Neujahrstag", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: HOLIDAY_SOURCE_IMPORTED},
+		{Name: "1. Weihnachtstag", Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), Source: HOLIDAY_SOURCE_IMPORTED},
+	}
+	wanted = append(wanted, custom...)
+
+	existing := Holidays{
+		{Name: "Neujahrstag", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: HOLIDAY_SOURCE_IMPORTED},
+		{Name: "Heiligabend", Date: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC), Source: HOLIDAY_SOURCE_CUSTOM},
+		{Name: "Reformationstag", Date: time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC), Source: HOLIDAY_SOURCE_IMPORTED},
+	}
+
+	added, updated, removed := DiffHolidays(existing, wanted)
+	if len(added) != 1 || added[0].Name != "1. Weihnachtstag" {
+		t.Errorf("added: want 1. Weihnachtstag, got %v", added)
+	}
+	if len(updated) != 1 || updated[0].EmployeeDaySubstraction != 50 {
+		t.Errorf("updated: want half day christmas eve, got %v", updated)
+	}
+	if len(removed) != 1 || removed[0].Name != "Reformationstag" {
+		t.Errorf("removed: want Reformationstag, got %v", removed)
+	}
+
+	result := HolidaySyncResult{Added: added, Updated: updated, Removed: removed}
+	if got := result.GetChangedMonths(); len(got) != 2 || got[0].Month() != time.October {
+		t.Errorf("changed months: want october and december, got %v", got)
+	}
+
+	added, updated, removed = DiffHolidays(wanted, wanted)
+	if len(added)+len(updated)+len(removed) != 0 {
+		t.Errorf("second sync: want no changes, got %d", len(added)+len(updated)+len(removed))
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartialHolidays(t *testing.T) {
+	holidays := Holidays{
+		{Name: "Heiligabend", Date: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC), EmployeeDaySubstraction: 50},
+		{Name: "1. Weihnachtstag", Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)},
+		{Name: "2. Weihnachtstag", Date: time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)},
+		{Name: "Silvester", Date: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), EmployeeDaySubstraction: 50},
+	}
+	christmasEve := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+
+	workTimeModel := DefaultWorkTimeModel()
+	if got := workTimeModel.GetWorkingHoursForDay(christmasEve, holidays); got != 4 {
+		t.Errorf("christmas eve: want 4 working hours, got %f", got)
+	}
+
+	if holidays.ContainsFullDay(christmasEve) || !holidays.Contains(christmasEve) {
+		t.Errorf("christmas eve: want partial holiday")
+	}
+
+	if got := GetNeededHoursForMonth(holidays, nil, 2024, 12); got != 144 {
+		t.Errorf("december: want 144 needed hours, got %f", got)
+	}
+
+	testData := []struct {
+		Name        string
+		Absence     Absence
+		Wanted      float64
+		WantedHours float64
+	}{
+		{
+			Name:        "christmas week",
+			Absence:     Absence{AbsenceFrom: time.Date(2024, 12, 23, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)},
+			Wanted:      2.5,
+			WantedHours: 4,
+		},
+		{
+			Name:        "morning of christmas eve",
+			Absence:     Absence{AbsenceFrom: christmasEve, AbsenceTill: christmasEve, DayPart: ABSENCE_DAY_PART_MORNING},
+			Wanted:      0.5,
+			WantedHours: 4,
+		},
+	}
+
+	for _, item := range testData {
+		item.Absence.CalculateNettoDays(holidays, nil)
+		if *item.Absence.NettoDays != item.Wanted {
+			t.Errorf("%s: want %f days, got %f", item.Name, item.Wanted, *item.Absence.NettoDays)
+		}
+
+		absentHours := item.Absence.GetAbsentHoursForDay(christmasEve, holidays, nil)
+		if absentHours != item.WantedHours {
+			t.Errorf("%s: want %f absent hours, got %f", item.Name, item.WantedHours, absentHours)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package model
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"gorm.io/gorm"
+)
+
+const (
+	DEFAULT_LOCATION_NAME    = "Standard"
+	DEFAULT_LOCATION_COUNTRY = "DE"
+)
+
+// Location is a work location with its own holiday calendar. For german
+// locations the public holidays of the state are imported, all other holidays
+// have to be added as custom holidays.
+type Location struct {
+	gorm.Model
+	Name           string `gorm:"unique"`
+	Country        string `gorm:"default:DE"`
+	State          string
+	IsDefault      bool
+	OfficeIPRanges []LocationOfficeIPRange `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type LocationOfficeIPRange struct {
+	gorm.Model
+	LocationID  uint `gorm:"not null;index"`
+	IPRange     string
+	Description string
+}
+
+type LocationCreateRequest struct {
+	Name           string `binding:"required"`
+	Country        string `binding:"required,len=2"`
+	State          string
+	IsDefault      bool
+	OfficeIPRanges []LocationOfficeIPRangeRequest `binding:"dive"`
+}
+
+type LocationOfficeIPRangeRequest struct {
+	IPRange     string `binding:"required,cidr|ip"`
+	Description string
+}
+
+var ErrLocationStateInvalid = errors.New("locations in germany need a valid state")
+
+// Apply sets the values of the request to the location, the office ip ranges
+// are replaced.
+func (r *LocationCreateRequest) Apply(location *Location) error {
+	country := strings.ToUpper(r.Country)
+	if country == DEFAULT_LOCATION_COUNTRY && !HolidayState(r.State).IsValid() {
+		return ErrLocationStateInvalid
+	}
+
+	location.Name = r.Name
+	location.Country = country
+	location.State = r.State
+	location.OfficeIPRanges = []LocationOfficeIPRange{}
+	for _, officeIPRange := range r.OfficeIPRanges {
+		location.OfficeIPRanges = append(location.OfficeIPRanges, LocationOfficeIPRange{
+			LocationID:  location.ID,
+			IPRange:     officeIPRange.IPRange,
+			Description: officeIPRange.Description,
+		})
+	}
+
+	return nil
+}
+
+func DefaultLocation(state string) Location {
+	return Location{
+		Name:      DEFAULT_LOCATION_NAME,
+		Country:   DEFAULT_LOCATION_COUNTRY,
+		State:     state,
+		IsDefault: true,
+	}
+}
+
+// HasPublicHolidays reports if the public holidays of the location can be
+// imported.
+func (l *Location) HasPublicHolidays() bool {
+	return l.Country == DEFAULT_LOCATION_COUNTRY && HolidayState(l.State).IsValid()
+}
+
+// IsOfficeIP reports if the ip is part of one of the office ranges, a range
+// is either a network in cidr notation or a single address.
+func (l *Location) IsOfficeIP(input string) bool {
+	ip := net.ParseIP(strings.TrimSpace(input))
+	if ip == nil {
+		return false
+	}
+
+	for _, officeIPRange := range l.OfficeIPRanges {
+		if strings.Contains(officeIPRange.IPRange, "/") {
+			_, network, err := net.ParseCIDR(officeIPRange.IPRange)
+			if err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if ip.Equal(net.ParseIP(officeIPRange.IPRange)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type UserLocation struct {
+	gorm.Model
+	UserID     uint  `gorm:"not null;index"`
+	User       *User `json:"-"`
+	LocationID uint  `gorm:"not null"`
+	Location   Location
+	ValidFrom  time.Time
+	ValidTill  *time.Time
+}
+
+type UserLocationCreateRequest struct {
+	LocationID uint      `binding:"required"`
+	ValidFrom  time.Time `binding:"required"`
+	ValidTill  *time.Time
+}
+
+func (u *UserLocation) IsValidAt(date time.Time) bool {
+	day := helper.GetDayDate(date)
+
+	if day.Before(helper.GetDayDate(u.ValidFrom)) {
+		return false
+	}
+
+	return u.ValidTill == nil || !day.After(helper.GetDayDate(*u.ValidTill))
+}
+
+type UserLocations []UserLocation
+
+// getForDay returns the assignment of the given day. If several assignments
+// overlap the one starting last wins.
+func (u UserLocations) getForDay(date time.Time) *UserLocation {
+	var current *UserLocation
+
+	for i := range u {
+		if !u[i].IsValidAt(date) {
+			continue
+		}
+
+		if current == nil || u[i].ValidFrom.After(current.ValidFrom) {
+			current = &u[i]
+		}
+	}
+
+	return current
+}
+
+// GetLocationIdForDay returns the location the user works at on the given day,
+// without any assignment the default location is used.
+func (u UserLocations) GetLocationIdForDay(date time.Time, defaultLocationId uint) uint {
+	current := u.getForDay(date)
+	if current == nil {
+		return defaultLocationId
+	}
+
+	return current.LocationID
+}
+
+// GetLocationForDay returns the assigned location on the given day, false if
+// the default location applies.
+func (u UserLocations) GetLocationForDay(date time.Time) (Location, bool) {
+	current := u.getForDay(date)
+	if current == nil {
+		return Location{}, false
+	}
+
+	return current.Location, true
+}
+
+// ForUserLocations returns the holidays of the location the user is assigned to
+// on the day of each holiday. Holidays without location apply everywhere.
+func (h Holidays) ForUserLocations(userLocations UserLocations, defaultLocationId uint) Holidays {
+	result := Holidays{}
+
+	for _, holiday := range h {
+		if holiday.LocationID != nil && *holiday.LocationID != userLocations.GetLocationIdForDay(holiday.Date, defaultLocationId) {
+			continue
+		}
+
+		result = append(result, holiday)
+	}
+
+	return result
+}
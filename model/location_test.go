@@ -0,0 +1,81 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHolidaysForUserLocations(t *testing.T) {
+	defaultLocationId := uint(1)
+	bavariaId := uint(2)
+	validTill := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	userLocations := UserLocations{
+		{LocationID: bavariaId, ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ValidTill: &validTill},
+	}
+
+	holidays := Holidays{
+		{Name: "Neujahrstag", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), LocationID: &defaultLocationId},
+		{Name: "Neujahrstag", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), LocationID: &bavariaId},
+		{Name: "Heilige Drei Könige", Date: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), LocationID: &bavariaId},
+		{Name: "Allerheiligen", Date: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), LocationID: &bavariaId},
+		{Name: "Reformationstag", Date: time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC), LocationID: &defaultLocationId},
+		{Name: "Betriebsfeier", Date: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	result := holidays.ForUserLocations(userLocations, defaultLocationId)
+
+	want := map[string]uint{
+		"Neujahrstag":         bavariaId,
+		"Heilige Drei Könige": bavariaId,
+		"Reformationstag":     defaultLocationId,
+		"Betriebsfeier":       0,
+	}
+
+	if len(result) != len(want) {
+		t.Fatalf("want %d holidays, got %d: %+v", len(want), len(result), result)
+	}
+
+	for _, holiday := range result {
+		wantLocation, ok := want[holiday.Name]
+		if !ok {
+			t.Errorf("%s: want no holiday, got %s", holiday.Name, holiday.Date)
+			continue
+		}
+
+		gotLocation := uint(0)
+		if holiday.LocationID != nil {
+			gotLocation = *holiday.LocationID
+		}
+		if gotLocation != wantLocation {
+			t.Errorf("%s: want location %d, got %d", holiday.Name, wantLocation, gotLocation)
+		}
+	}
+}
+
+func TestLocationIsOfficeIP(t *testing.T) {
+	location := Location{
+		OfficeIPRanges: []LocationOfficeIPRange{
+			{IPRange: "10.1.0.0/16"},
+			{IPRange: "203.0.113.7"},
+			{IPRange: "2001:db8::/32"},
+		},
+	}
+
+	tests := map[string]bool{
+		"10.1.24.3":    true,
+		"10.2.0.1":     false,
+		"203.0.113.7":  true,
+		" 203.0.113.7": true,
+		"203.0.113.8":  false,
+		"2001:db8::1":  true,
+		"not an ip":    false,
+	}
+
+	for ip, want := range tests {
+		got := location.IsOfficeIP(ip)
+		if got != want {
+			t.Errorf("%s: want %t, got %t", ip, want, got)
+		}
+	}
+}
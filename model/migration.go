@@ -6,11 +6,33 @@ import (
 	"gorm.io/gorm"
 )
 
+// Migration records a finished or failed run of a registered migration, a
+// migration counts as applied once a successful run exists for its title.
 type Migration struct {
 	gorm.Model
 
+	Version    int
 	Title      string
+	Checksum   string
 	Result     string
+	StartedAt  time.Time
 	FinishedAt time.Time
+	DurationMs int64
 	Success    bool
+	DryRun     bool `gorm:"-"`
+}
+
+type MigrationStatus struct {
+	Version          int
+	Title            string
+	Description      string
+	Checksum         string
+	Applied          bool
+	AppliedAt        *time.Time
+	ChecksumMismatch bool
+	LastError        string
+}
+
+type MigrationRunRequest struct {
+	DryRun bool
 }
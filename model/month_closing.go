@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MonthClosing locks a month for a single user or, without UserID, for all
+// users. Reopened closings are kept as audit trail.
+type MonthClosing struct {
+	gorm.Model
+	UserID           *uint `gorm:"index"`
+	User             *User `json:"-"`
+	Year             int   `gorm:"index"`
+	Month            int   `gorm:"index"`
+	ClosedByUserID   uint
+	ClosedByUser     *User `json:"-"`
+	ReopenedByUserID *uint
+	ReopenedByUser   *User `json:"-"`
+	ReopenedAt       *time.Time
+	ReopenReason     *string
+}
+
+type MonthClosingCreateRequest struct {
+	UserID *uint
+	Year   int `binding:"required"`
+	Month  int `binding:"required,min=1,max=12"`
+}
+
+type MonthClosingReopenRequest struct {
+	Reason string `binding:"required"`
+}
+
+func (m *MonthClosing) IsActive() bool {
+	return m.ReopenedAt == nil
+}
+
+func (m *MonthClosing) Reopen(reopeningUser *User, reason string) {
+	now := time.Now()
+
+	m.ReopenedByUserID = &reopeningUser.ID
+	m.ReopenedAt = &now
+	m.ReopenReason = &reason
+}
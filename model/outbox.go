@@ -0,0 +1,178 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxJobType string
+type OutboxJobStatus string
+
+const (
+	OUTBOX_JOB_TYPE_CALENDAR_ABSENCE       OutboxJobType = "calendar_absence"
+	OUTBOX_JOB_TYPE_CALENDAR_EXTERNAL_WORK OutboxJobType = "calendar_external_work"
+	OUTBOX_JOB_TYPE_CALENDAR_DELETE        OutboxJobType = "calendar_delete"
+	OUTBOX_JOB_TYPE_TEAMS_NOTIFICATION     OutboxJobType = "teams_notification"
+	OUTBOX_JOB_TYPE_USER_NOTIFICATION      OutboxJobType = "user_notification"
+	OUTBOX_JOB_TYPE_WEBHOOK                OutboxJobType = "webhook"
+)
+
+const (
+	OUTBOX_JOB_STATUS_PENDING   OutboxJobStatus = "pending"
+	OUTBOX_JOB_STATUS_DELIVERED OutboxJobStatus = "delivered"
+	OUTBOX_JOB_STATUS_DEAD      OutboxJobStatus = "dead"
+)
+
+const (
+	OUTBOX_WEBHOOK_EVENT_ABSENCE_CREATED = "absence.created"
+	OUTBOX_WEBHOOK_EVENT_ABSENCE_SIGNED  = "absence.signed"
+	OUTBOX_WEBHOOK_EVENT_ABSENCE_CHANGED = "absence.changed"
+	OUTBOX_WEBHOOK_EVENT_ABSENCE_DELETED = "absence.deleted"
+)
+
+const (
+	OUTBOX_JOB_MAX_ATTEMPTS = 10
+	OUTBOX_JOB_MAX_BACKOFF  = 2 * time.Hour
+)
+
+// OutboxJob is a side effect of a request, e.g. a calendar entry, which is
+// delivered in the background. Jobs failing too often are dead lettered and
+// can be replayed by an administrator.
+type OutboxJob struct {
+	gorm.Model
+	Type          OutboxJobType   `gorm:"not null;index"`
+	Payload       string          `gorm:"type:text"`
+	Status        OutboxJobStatus `gorm:"default:pending;index"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     *string
+	DeliveredAt   *time.Time
+}
+
+type OutboxCalendarAbsencePayload struct {
+	AbsenceID uint
+}
+
+type OutboxCalendarExternalWorkPayload struct {
+	ExternalWorkID uint
+}
+
+type OutboxCalendarDeletePayload struct {
+	Provider        ExternalEventProvider
+	Username        string
+	ExternalEventID string
+}
+
+type OutboxTeamsNotificationFact struct {
+	Title string
+	Value string
+}
+
+type OutboxTeamsNotificationPayload struct {
+	Title string
+	Facts []OutboxTeamsNotificationFact
+	Text  string
+}
+
+// OutboxUserNotificationPayload is a mail to a single user.
+type OutboxUserNotificationPayload struct {
+	Username string
+	Subject  string
+	Text     string
+}
+
+type OutboxWebhookPayload struct {
+	Url   string
+	Event string
+	Data  json.RawMessage
+}
+
+// OutboxAbsenceEvent is the data of the absence webhook events.
+type OutboxAbsenceEvent struct {
+	AbsenceID       uint
+	UserID          uint
+	AbsenceReasonID uint
+	AbsenceFrom     time.Time
+	AbsenceTill     time.Time
+	NettoDays       *float64
+	SignedStatus    *AbsenceSignedStatus
+}
+
+func NewOutboxAbsenceEvent(absence *Absence) OutboxAbsenceEvent {
+	event := OutboxAbsenceEvent{
+		AbsenceID:    absence.ID,
+		AbsenceFrom:  absence.AbsenceFrom,
+		AbsenceTill:  absence.AbsenceTill,
+		NettoDays:    absence.NettoDays,
+		SignedStatus: absence.SignedStatus,
+	}
+
+	if absence.UserID != nil {
+		event.UserID = *absence.UserID
+	}
+
+	if absence.AbsenceReasonID != nil {
+		event.AbsenceReasonID = *absence.AbsenceReasonID
+	}
+
+	return event
+}
+
+func NewOutboxJob(jobType OutboxJobType, payload any) (OutboxJob, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxJob{}, err
+	}
+
+	return OutboxJob{
+		Type:          jobType,
+		Payload:       string(data),
+		Status:        OUTBOX_JOB_STATUS_PENDING,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func (j *OutboxJob) DecodePayload(payload any) error {
+	return json.Unmarshal([]byte(j.Payload), payload)
+}
+
+func (j *OutboxJob) IsDead() bool {
+	return j.Status == OUTBOX_JOB_STATUS_DEAD
+}
+
+// Failed schedules the next attempt, the job is dead lettered after the
+// maximum number of attempts.
+func (j *OutboxJob) Failed(err error, now time.Time) {
+	message := err.Error()
+
+	j.Attempts++
+	j.LastError = &message
+	j.NextAttemptAt = now.Add(GetRetryBackoff(j.Attempts, OUTBOX_JOB_MAX_BACKOFF))
+
+	if j.Attempts >= OUTBOX_JOB_MAX_ATTEMPTS {
+		j.Status = OUTBOX_JOB_STATUS_DEAD
+	}
+}
+
+// DeadLetter gives up the job without further attempts.
+func (j *OutboxJob) DeadLetter(err error) {
+	message := err.Error()
+
+	j.Attempts++
+	j.LastError = &message
+	j.Status = OUTBOX_JOB_STATUS_DEAD
+}
+
+func (j *OutboxJob) Delivered(now time.Time) {
+	j.Status = OUTBOX_JOB_STATUS_DELIVERED
+	j.DeliveredAt = &now
+	j.LastError = nil
+}
+
+func (j *OutboxJob) Replay(now time.Time) {
+	j.Status = OUTBOX_JOB_STATUS_PENDING
+	j.Attempts = 0
+	j.NextAttemptAt = now
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOutboxJobDeadLetter(t *testing.T) {
+	now := time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)
+
+	job, err := NewOutboxJob(OUTBOX_JOB_TYPE_CALENDAR_ABSENCE, OutboxCalendarAbsencePayload{AbsenceID: 42})
+	if err != nil {
+		t.Fatalf("want job, got %s", err)
+	}
+
+	var payload OutboxCalendarAbsencePayload
+	err = job.DecodePayload(&payload)
+	if err != nil || payload.AbsenceID != 42 {
+		t.Errorf("want payload with absence 42, got %+v (%v)", payload, err)
+	}
+
+	for attempt := 1; attempt < OUTBOX_JOB_MAX_ATTEMPTS; attempt++ {
+		job.Failed(errors.New("graph unavailable"), now)
+		if job.Status != OUTBOX_JOB_STATUS_PENDING {
+			t.Fatalf("attempt %d: want status %s, got %s", attempt, OUTBOX_JOB_STATUS_PENDING, job.Status)
+		}
+	}
+
+	if !job.NextAttemptAt.Equal(now.Add(OUTBOX_JOB_MAX_BACKOFF)) {
+		t.Errorf("want backoff capped at %s, got %s", OUTBOX_JOB_MAX_BACKOFF, job.NextAttemptAt.Sub(now))
+	}
+
+	job.Failed(errors.New("graph unavailable"), now)
+	if !job.IsDead() {
+		t.Errorf("want job dead after %d attempts, got %s", OUTBOX_JOB_MAX_ATTEMPTS, job.Status)
+	}
+
+	job.Replay(now)
+	if job.Status != OUTBOX_JOB_STATUS_PENDING || job.Attempts != 0 || !job.NextAttemptAt.Equal(now) {
+		t.Errorf("want pending job after replay, got %+v", job)
+	}
+
+	job.Delivered(now)
+	if job.Status != OUTBOX_JOB_STATUS_DELIVERED || job.LastError != nil {
+		t.Errorf("want delivered job without error, got %+v", job)
+	}
+}
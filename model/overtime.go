@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 const (
@@ -33,7 +34,7 @@ type OvertimeMonthQuota struct {
 	Year    int `gorm:"index:idx_month_quota,unique"`
 	Month   int `gorm:"index:idx_month_quota,unique"`
 	Hours   *float64
-	Summary OvertimeSummary `gorm:"type:jsonb" sql:"json"`
+	Summary OvertimeSummary `sql:"json"`
 }
 
 func (o *OvertimeMonthQuota) InsertSummary(source string, identifier *uint, value float64, factor float64) {
@@ -54,7 +55,7 @@ func (o *OvertimeMonthQuota) Calculate() {
 }
 
 func (o *OvertimeSummary) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
+	bytes, ok := getJSONBytes(value)
 	if !ok {
 		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
 	}
@@ -65,6 +66,14 @@ func (o *OvertimeSummary) Scan(value interface{}) error {
 	return err
 }
 
+func (o OvertimeSummary) GormDataType() string {
+	return "json"
+}
+
+func (o OvertimeSummary) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return getJSONDBDataType(db)
+}
+
 func (o OvertimeSummary) Value() (driver.Value, error) {
 	if len(o) == 0 {
 		return nil, nil
@@ -1,20 +1,86 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"gorm.io/gorm"
+)
+
+type AutoCheckoutFallback string
+
+const (
+	AUTO_CHECKOUT_FALLBACK_PLANNED_HOURS AutoCheckoutFallback = "planned_hours"
+	AUTO_CHECKOUT_FALLBACK_MAX_HOURS     AutoCheckoutFallback = "max_hours"
+	AUTO_CHECKOUT_FALLBACK_CHECKIN       AutoCheckoutFallback = "checkin"
+)
+
+const (
+	DEFAULT_ABSENCE_MORNING_FROM   = "08:00"
+	DEFAULT_ABSENCE_AFTERNOON_FROM = "12:00"
+	DEFAULT_ABSENCE_AFTERNOON_TILL = "17:00"
+)
 
 type Settings struct {
 	gorm.Model
 
 	CheckinDetectionByIPAddress             *bool                       `gorm:"default:false"`
 	OfficeIPAddresses                       []SettingsOfficeIPAddresses `gorm:"constraint:OnDelete:CASCADE"`
+	BreakRules                              []SettingsBreakRule         `gorm:"constraint:OnDelete:CASCADE"`
 	TimestampChangeReasonMinimumLength      int64                       `gorm:"default:20"`
 	TimestampMaxHoursBetweenCheckInCheckOut int64                       `gorm:"default:12"`
+	TimestampAutoCheckoutFallback           AutoCheckoutFallback        `gorm:"default:planned_hours"`
+	TimestampCorrectionApprovalAfterDays    int64                       `gorm:"default:0"`
+	TimestampCorrectionApprovalMinutes      int64                       `gorm:"default:0"`
+	VacationCarryOverExpiryMonth            int                         `gorm:"default:3"`
+	VacationCarryOverExpiryDay              int                         `gorm:"default:31"`
+	AbsenceMorningFrom                      string                      `gorm:"default:08:00" binding:"omitempty,datetime=15:04"`
+	AbsenceAfternoonFrom                    string                      `gorm:"default:12:00" binding:"omitempty,datetime=15:04"`
+	AbsenceAfternoonTill                    string                      `gorm:"default:17:00" binding:"omitempty,datetime=15:04"`
 }
 
 type SettingsOfficeIPAddresses struct {
 	gorm.Model
 	Settings    Settings
 	SettingsID  uint
-	IPAddress   string `gorm:"uniqueIndex"`
+	IPAddress   string `gorm:"uniqueIndex;size:191"`
 	Description string
 }
+
+// GetHalfDayRange returns the begin and end of a morning or afternoon absence,
+// the morning ends when the afternoon begins.
+func (s *Settings) GetHalfDayRange(dayPart AbsenceDayPart) (string, string) {
+	morningFrom := helper.GetDefault(s.AbsenceMorningFrom, DEFAULT_ABSENCE_MORNING_FROM)
+	afternoonFrom := helper.GetDefault(s.AbsenceAfternoonFrom, DEFAULT_ABSENCE_AFTERNOON_FROM)
+	afternoonTill := helper.GetDefault(s.AbsenceAfternoonTill, DEFAULT_ABSENCE_AFTERNOON_TILL)
+
+	if dayPart == ABSENCE_DAY_PART_AFTERNOON {
+		return afternoonFrom, afternoonTill
+	}
+
+	return morningFrom, afternoonFrom
+}
+
+// GetBreakRulesForTeams returns the break rules of the first team having own
+// rules, otherwise the rules without a team.
+func (s *Settings) GetBreakRulesForTeams(teams []Team) BreakRules {
+	for _, team := range teams {
+		rules := BreakRules{}
+		for _, rule := range s.BreakRules {
+			if rule.TeamID != nil && *rule.TeamID == team.ID {
+				rules = append(rules, rule)
+			}
+		}
+
+		if len(rules) > 0 {
+			return rules
+		}
+	}
+
+	rules := BreakRules{}
+	for _, rule := range s.BreakRules {
+		if rule.TeamID == nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
@@ -4,21 +4,27 @@ import "gorm.io/gorm"
 
 type Team struct {
 	gorm.Model
-	Teamname string `gorm:"unique"`
-	Members  []TeamMember
+	Teamname              string `gorm:"unique"`
+	Members               []TeamMember
+	MinimumPresentMembers uint
+	BlackoutPeriods       []TeamBlackoutPeriod `gorm:"constraint:OnDelete:CASCADE"`
 }
 
 type TeamResponse struct {
 	gorm.Model
-	Teamname string
-	Members  []TeamMemberResponse
+	Teamname              string
+	Members               []TeamMemberResponse
+	MinimumPresentMembers uint
+	BlackoutPeriods       []TeamBlackoutPeriod
 }
 
 func (t *Team) GetTeamResponse() TeamResponse {
 	res := TeamResponse{
-		Model:    t.Model,
-		Teamname: t.Teamname,
-		Members:  []TeamMemberResponse{},
+		Model:                 t.Model,
+		Teamname:              t.Teamname,
+		Members:               []TeamMemberResponse{},
+		MinimumPresentMembers: t.MinimumPresentMembers,
+		BlackoutPeriods:       t.BlackoutPeriods,
 	}
 
 	for _, member := range t.Members {
@@ -62,8 +68,9 @@ func (tm *TeamMember) GetTeamMemberResponse() TeamMemberResponse {
 }
 
 type TeamCreateRequest struct {
-	Teamname   string `binding:"required"`
-	TeamLeadId uint   `binding:"required"`
+	Teamname              string `binding:"required"`
+	TeamLeadId            uint   `binding:"required"`
+	MinimumPresentMembers uint
 }
 
 type TeamMemberCreateRequest struct {
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"gorm.io/gorm"
+)
+
+type TeamBlackoutPeriod struct {
+	gorm.Model
+	TeamID       uint  `gorm:"not null;index"`
+	Team         *Team `json:"-"`
+	BlackoutFrom time.Time
+	BlackoutTill time.Time
+	Description  string
+}
+
+type TeamBlackoutPeriodCreateRequest struct {
+	BlackoutFrom time.Time `binding:"required"`
+	BlackoutTill time.Time `binding:"required"`
+	Description  string    `binding:"required"`
+}
+
+func (b *TeamBlackoutPeriod) Overlaps(from time.Time, till time.Time) bool {
+	return !helper.GetDayDate(from).After(helper.GetDayDate(b.BlackoutTill)) && !helper.GetDayDate(b.BlackoutFrom).After(helper.GetDayDate(till))
+}
+
+// IsSubjectToTeamRules returns true for planned absences. Absences like sick
+// leave which don't need an approval and don't deduct vacation are never
+// rejected by the team rules.
+func (a *Absence) IsSubjectToTeamRules() bool {
+	needsApproval := a.AbsenceReason.NeedsApproval != nil && *a.AbsenceReason.NeedsApproval
+	return needsApproval || a.DeductsVacation()
+}
+
+// CheckTeamRules checks the blackout periods and the minimum number of present
+// members of the team for every working day of the absence. Only accepted full
+// day absences of the other members count as absent.
+func CheckTeamRules(team *Team, absence *Absence, teamAbsences []Absence, holidays Holidays, workTimeModels UserWorkTimeModels) []AbsenceValidationViolation {
+	violations := []AbsenceValidationViolation{}
+
+	if !absence.IsSubjectToTeamRules() {
+		return violations
+	}
+
+	for _, blackoutPeriod := range team.BlackoutPeriods {
+		if blackoutPeriod.Overlaps(absence.AbsenceFrom, absence.AbsenceTill) {
+			violations = append(violations, AbsenceValidationViolation{
+				Reason:               ABSENCE_VALIDATION_REASON_BLACKOUT_PERIOD,
+				TeamID:               &team.ID,
+				TeamBlackoutPeriodID: &blackoutPeriod.ID,
+			})
+		}
+	}
+
+	if team.MinimumPresentMembers == 0 {
+		return violations
+	}
+
+	for currentDay := helper.GetDayDate(absence.AbsenceFrom); !currentDay.After(helper.GetDayDate(absence.AbsenceTill)); currentDay = currentDay.AddDate(0, 0, 1) {
+		if !workTimeModels.IsWorkingDay(currentDay, holidays) {
+			continue
+		}
+
+		absentUsers := map[uint]bool{*absence.UserID: true}
+		for _, teamAbsence := range teamAbsences {
+			if teamAbsence.ID == absence.ID || teamAbsence.UserID == nil || !teamAbsence.IsFullDay() {
+				continue
+			}
+
+			if teamAbsence.SignedStatus == nil || *teamAbsence.SignedStatus != SIGNED_STATUS_ACCEPTED {
+				continue
+			}
+
+			if teamAbsence.IsDateInAbsence(currentDay) {
+				absentUsers[*teamAbsence.UserID] = true
+			}
+		}
+
+		present := len(team.Members) - len(absentUsers)
+		if present < int(team.MinimumPresentMembers) {
+			date := currentDay
+			violations = append(violations, AbsenceValidationViolation{
+				Reason: ABSENCE_VALIDATION_REASON_TEAM_CAPACITY,
+				TeamID: &team.ID,
+				Date:   &date,
+				Value:  float64(present),
+				Limit:  float64(team.MinimumPresentMembers),
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckTeamRules(t *testing.T) {
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)
+	deductsVacation := true
+	accepted := SIGNED_STATUS_ACCEPTED
+	userA, userB, userC := uint(1), uint(2), uint(3)
+
+	team := Team{
+		Model:                 gorm.Model{ID: 1},
+		MinimumPresentMembers: 2,
+		Members:               []TeamMember{{UserID: userA}, {UserID: userB}, {UserID: userC}},
+		BlackoutPeriods: []TeamBlackoutPeriod{
+			{Model: gorm.Model{ID: 1}, BlackoutFrom: saturday, BlackoutTill: saturday.AddDate(0, 0, 7)},
+		},
+	}
+
+	teamAbsences := []Absence{
+		{Model: gorm.Model{ID: 1}, UserID: &userB, AbsenceFrom: monday, AbsenceTill: monday, SignedStatus: &accepted},
+		{Model: gorm.Model{ID: 2}, UserID: &userC, AbsenceFrom: tuesday, AbsenceTill: tuesday},
+	}
+
+	vacation := AbsenceReason{DeductsVacation: &deductsVacation}
+
+	testData := []struct {
+		Name    string
+		Absence Absence
+		Wanted  []AbsenceValidationReason
+	}{
+		{
+			Name:    "other member accepted on monday",
+			Absence: Absence{UserID: &userA, AbsenceFrom: monday, AbsenceTill: monday, AbsenceReason: vacation},
+			Wanted:  []AbsenceValidationReason{ABSENCE_VALIDATION_REASON_TEAM_CAPACITY},
+		},
+		{
+			Name:    "other member not yet accepted",
+			Absence: Absence{UserID: &userA, AbsenceFrom: tuesday, AbsenceTill: tuesday, AbsenceReason: vacation},
+			Wanted:  []AbsenceValidationReason{},
+		},
+		{
+			Name:    "blackout period",
+			Absence: Absence{UserID: &userA, AbsenceFrom: saturday, AbsenceTill: saturday.AddDate(0, 0, 1), AbsenceReason: vacation},
+			Wanted:  []AbsenceValidationReason{ABSENCE_VALIDATION_REASON_BLACKOUT_PERIOD},
+		},
+		{
+			Name:    "not subject to team rules",
+			Absence: Absence{UserID: &userA, AbsenceFrom: monday, AbsenceTill: saturday},
+			Wanted:  []AbsenceValidationReason{},
+		},
+	}
+
+	for _, item := range testData {
+		violations := CheckTeamRules(&team, &item.Absence, teamAbsences, nil, nil)
+		if len(violations) != len(item.Wanted) {
+			t.Fatalf("%s: want %v, got %+v", item.Name, item.Wanted, violations)
+		}
+
+		for i, violation := range violations {
+			if violation.Reason != item.Wanted[i] {
+				t.Errorf("%s: want %s, got %s", item.Name, item.Wanted[i], violation.Reason)
+			}
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package model
 
 import (
+	"math"
+	"slices"
 	"time"
 
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
 	"gorm.io/gorm"
 )
 
+const TIMESTAMP_CORRECTION_REASON_AUTO_CHECKOUT = "auto_checkout"
+
 type Timestamp struct {
 	gorm.Model
 
@@ -16,11 +21,20 @@ type Timestamp struct {
 	IsHomeoffice      bool
 	IsHomeofficeGoing bool
 	Corrections       []TimestampCorrection
+	Breaks            []TimestampBreak
 	OvertimeReason    *string
 	NeedsCorrection   bool
 	CorrectionReason  *string
 }
 
+type TimestampCorrectionStatus string
+
+const (
+	TIMESTAMP_CORRECTION_STATUS_PENDING  TimestampCorrectionStatus = "pending"
+	TIMESTAMP_CORRECTION_STATUS_APPROVED TimestampCorrectionStatus = "approved"
+	TIMESTAMP_CORRECTION_STATUS_DECLINED TimestampCorrectionStatus = "declined"
+)
+
 type TimestampCorrection struct {
 	gorm.Model
 	TimestampID        uint `gorm:"not null"`
@@ -31,6 +45,81 @@ type TimestampCorrection struct {
 	NeededCorrection   bool
 	CorrectionReason   *string
 	OvertimeReason     *string
+	NewComingTimestamp time.Time
+	NewGoingTimestamp  time.Time
+	NewIsHomeoffice    bool
+	Status             TimestampCorrectionStatus `gorm:"default:approved"`
+	SignedUserID       *uint
+	SignedUser         *User `json:"-"`
+	SignedMessage      *string
+	SignedTimestamp    *time.Time
+}
+
+func (t *TimestampCorrection) IsPending() bool {
+	return t.Status == TIMESTAMP_CORRECTION_STATUS_PENDING
+}
+
+// NeedsApproval returns true if the correction has to be signed by a team lead,
+// because the timestamp is older than the allowed days or the correction
+// changes more than the allowed minutes. Both settings default to 0, which
+// disables the check, so the approval workflow is opt-in.
+func (t *TimestampCorrection) NeedsApproval(settings Settings, now time.Time) bool {
+	if settings.TimestampCorrectionApprovalAfterDays > 0 {
+		ageDays := helper.GetDayDate(now).Sub(helper.GetDayDate(t.OldComingTimestamp)).Hours() / 24
+		if ageDays > float64(settings.TimestampCorrectionApprovalAfterDays) {
+			return true
+		}
+	}
+
+	if settings.TimestampCorrectionApprovalMinutes > 0 {
+		changedMinutes := math.Abs(t.NewComingTimestamp.Sub(t.OldComingTimestamp).Minutes())
+		if !t.OldGoingTimestamp.IsZero() {
+			changedMinutes += math.Abs(t.NewGoingTimestamp.Sub(t.OldGoingTimestamp).Minutes())
+		}
+
+		if changedMinutes > float64(settings.TimestampCorrectionApprovalMinutes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *TimestampCorrection) Apply(timestamp *Timestamp) {
+	timestamp.ComingTimestamp = t.NewComingTimestamp
+	timestamp.GoingTimestamp = t.NewGoingTimestamp
+	timestamp.IsHomeoffice = t.NewIsHomeoffice
+}
+
+func (t *TimestampCorrection) Sign(signingUser *User, status TimestampCorrectionStatus, message *string) {
+	now := time.Now()
+
+	t.SignedUser = signingUser
+	t.SignedUserID = &signingUser.ID
+	t.SignedTimestamp = &now
+	t.Status = status
+	t.SignedMessage = message
+}
+
+type TimestampBreak struct {
+	gorm.Model
+	TimestampID uint       `gorm:"not null;index"`
+	Timestamp   *Timestamp `json:"-"`
+	BreakStart  time.Time
+	BreakEnd    time.Time
+}
+
+func (b *TimestampBreak) IsComplete() bool {
+	return !b.BreakEnd.IsZero()
+}
+
+func (b *TimestampBreak) CalculateHours() float64 {
+	breakEnd := b.BreakEnd
+	if breakEnd.IsZero() {
+		breakEnd = time.Now()
+	}
+
+	return breakEnd.Sub(b.BreakStart).Hours()
 }
 
 type TimestampCreateRequest struct {
@@ -77,18 +166,18 @@ type TimestampYearMonthGrouped struct {
 	UserID uint
 }
 
-func (t *Timestamp) SuspiciousReason(maxTimestampDuration float64) string {
+func (t *Timestamp) SuspiciousReason(maxTimestampDuration float64, breakRules BreakRules) ComplianceReason {
 	if t.ComingTimestamp.Year() < 1999 || t.GoingTimestamp.Year() < 1999 {
-		return "broken_timestamp"
+		return COMPLIANCE_REASON_BROKEN_TIMESTAMP
 	}
 
 	if t.NeedsCorrection {
-		return "correction"
+		return COMPLIANCE_REASON_CORRECTION
 	}
 
-	netto, _ := t.CalculateWorkingHours()
+	netto, _ := t.CalculateWorkingHours(breakRules)
 	if netto > maxTimestampDuration && t.OvertimeReason == nil {
-		return "overtime"
+		return COMPLIANCE_REASON_OVERTIME
 	}
 
 	return ""
@@ -106,60 +195,91 @@ func (t *Timestamp) IsToday() bool {
 		t.ComingTimestamp.Year() == now.Year()
 }
 
-func (t *Timestamp) CalculateWorkingHours() (float64, float64) {
-	goingTimestamp := t.GoingTimestamp
-
-	if goingTimestamp.IsZero() {
-		goingTimestamp = time.Now()
+func (t *Timestamp) GetOpenBreak() *TimestampBreak {
+	for i := range t.Breaks {
+		if !t.Breaks[i].IsComplete() {
+			return &t.Breaks[i]
+		}
 	}
 
-	completeTime := goingTimestamp.Sub(t.ComingTimestamp).Hours()
-	calculatedTime := completeTime
+	return nil
+}
 
-	if completeTime > 6.0 {
-		calculatedTime = calculatedTime - 0.5
-		if calculatedTime < 6.0 {
-			calculatedTime = calculatedTime + (6.0 - calculatedTime)
-		}
+func (t *Timestamp) CalculateBreakHours() float64 {
+	breakHours := 0.0
+	for _, timestampBreak := range t.Breaks {
+		breakHours += timestampBreak.CalculateHours()
 	}
 
-	if calculatedTime > 9.0 {
-		calculatedTime = calculatedTime - 0.25
-		if calculatedTime < 9.0 {
-			calculatedTime = calculatedTime + (9.0 - calculatedTime)
-		}
-	}
-	return calculatedTime, completeTime - calculatedTime
+	return breakHours
 }
 
-type WorkTimeModel struct {
-	DefaultHoursPerWeekday   float64
-	HoursPerWeekdayException map[time.Weekday]float64
+// getBreakSegments returns the hours of every recorded break.
+func (t *Timestamp) getBreakSegments() []float64 {
+	segments := []float64{}
+	for _, timestampBreak := range t.Breaks {
+		segments = append(segments, timestampBreak.CalculateHours())
+	}
+
+	return segments
 }
 
-func (w *WorkTimeModel) GetWorkingHoursForDay(input time.Time, holidays Holidays) float64 {
-	if holidays.Contains(input) {
-		return 0.0
+func (t *Timestamp) getGoingTimestamp() time.Time {
+	if t.GoingTimestamp.IsZero() {
+		return time.Now()
 	}
 
-	neededHours := w.DefaultHoursPerWeekday
+	return t.GoingTimestamp
+}
 
-	if hours, exists := w.HoursPerWeekdayException[input.Weekday()]; exists {
-		neededHours = hours
-	}
+// CalculateWorkingHours returns the netto working hours and the subtracted
+// hours. Recorded breaks are subtracted, the break rules only deduct the part
+// of the statutory break which was not recorded.
+func (t *Timestamp) CalculateWorkingHours(breakRules BreakRules) (float64, float64) {
+	completeTime := t.getGoingTimestamp().Sub(t.ComingTimestamp).Hours()
+	breakHours := t.CalculateBreakHours()
 
-	return neededHours
+	calculatedTime, _ := breakRules.Apply(completeTime-breakHours, t.getBreakSegments())
+	return calculatedTime, completeTime - calculatedTime
 }
 
-func DefaultWorkTimeModel() WorkTimeModel {
-	return WorkTimeModel{
-		DefaultHoursPerWeekday: 8.0,
-		HoursPerWeekdayException: map[time.Weekday]float64{
-			time.Friday:   6.0,
-			time.Saturday: 0.0,
-			time.Sunday:   0.0,
-		},
+// Calculate sets the working and subtracted hours of the day. The break rules
+// are applied to the whole day, recorded breaks and gaps between the
+// timestamps count as breaks if they are long enough.
+func (g *TimestampGroup) Calculate(breakRules BreakRules) {
+	timestamps := slices.Clone(g.Timestamps)
+	slices.SortFunc(timestamps, func(a, b Timestamp) int {
+		return a.ComingTimestamp.Compare(b.ComingTimestamp)
+	})
+
+	completeTime := 0.0
+	recordedBreaks := 0.0
+	takenBreaks := []float64{}
+	for i, timestamp := range timestamps {
+		completeTime += timestamp.getGoingTimestamp().Sub(timestamp.ComingTimestamp).Hours()
+		recordedBreaks += timestamp.CalculateBreakHours()
+		takenBreaks = append(takenBreaks, timestamp.getBreakSegments()...)
+
+		if i > 0 {
+			gap := timestamp.ComingTimestamp.Sub(timestamps[i-1].getGoingTimestamp()).Hours()
+			if gap > 0 {
+				takenBreaks = append(takenBreaks, gap)
+			}
+		}
 	}
+
+	g.WorkingHours, _ = breakRules.Apply(completeTime-recordedBreaks, takenBreaks)
+	g.SubtractedHours = completeTime - g.WorkingHours
+}
+
+type TimestampCorrectionSignRequest struct {
+	Status  TimestampCorrectionStatus `binding:"required,oneof=approved declined"`
+	Message *string
+}
+
+type TimestampCorrectionOpenResponse struct {
+	TimestampCorrection
+	User UserResponse
 }
 
 type TimestampCorrectionRequest struct {
@@ -168,7 +288,7 @@ type TimestampCorrectionRequest struct {
 
 type TimestampSuspiciousResponse struct {
 	Timestamp
-	SuspiciousReason string
+	SuspiciousReason ComplianceReason
 }
 
 type TimestampOvertimeReasonUpdateRequest struct {
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestampGroup_Calculate(t *testing.T) {
+	stamp := func(fromHour, fromMinute, tillHour, tillMinute int) Timestamp {
+		return Timestamp{
+			ComingTimestamp: time.Date(2025, 1, 6, fromHour, fromMinute, 0, 0, time.UTC),
+			GoingTimestamp:  time.Date(2025, 1, 6, tillHour, tillMinute, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name           string // description of this test case
+		timestamps     []Timestamp
+		wantWorking    float64
+		wantSubtracted float64
+	}{
+		{
+			name:           "Single 8h stamp",
+			timestamps:     []Timestamp{stamp(8, 0, 16, 0)},
+			wantWorking:    7.5,
+			wantSubtracted: 0.5,
+		},
+		{
+			name:           "Split 4h + 4h with 30 minutes break",
+			timestamps:     []Timestamp{stamp(12, 30, 16, 30), stamp(8, 0, 12, 0)},
+			wantWorking:    8,
+			wantSubtracted: 0,
+		},
+		{
+			name:           "Split 4h + 4h with 15 minutes break",
+			timestamps:     []Timestamp{stamp(8, 0, 12, 0), stamp(12, 15, 16, 15)},
+			wantWorking:    7.75,
+			wantSubtracted: 0.25,
+		},
+		{
+			name:           "Split 5h + 5h with 30 minutes break",
+			timestamps:     []Timestamp{stamp(7, 0, 12, 0), stamp(12, 30, 17, 30)},
+			wantWorking:    9.75,
+			wantSubtracted: 0.25,
+		},
+		{
+			name: "Single 9h stamp with recorded 45 minutes break",
+			timestamps: []Timestamp{
+				func() Timestamp {
+					timestamp := stamp(8, 0, 17, 0)
+					timestamp.Breaks = []TimestampBreak{
+						{
+							BreakStart: time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC),
+							BreakEnd:   time.Date(2025, 1, 6, 12, 45, 0, 0, time.UTC),
+						},
+					}
+					return timestamp
+				}(),
+			},
+			wantWorking:    8.25,
+			wantSubtracted: 0.75,
+		},
+		{
+			name: "Single 9h stamp with recorded 10 and 5 minutes breaks",
+			timestamps: []Timestamp{
+				func() Timestamp {
+					timestamp := stamp(8, 0, 17, 0)
+					timestamp.Breaks = []TimestampBreak{
+						{
+							BreakStart: time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC),
+							BreakEnd:   time.Date(2025, 1, 6, 12, 10, 0, 0, time.UTC),
+						},
+						{
+							BreakStart: time.Date(2025, 1, 6, 15, 0, 0, 0, time.UTC),
+							BreakEnd:   time.Date(2025, 1, 6, 15, 5, 0, 0, time.UTC),
+						},
+					}
+					return timestamp
+				}(),
+			},
+			wantWorking:    8.25,
+			wantSubtracted: 0.75,
+		},
+		{
+			name:           "Split 4h + 4h with 5 minutes gap",
+			timestamps:     []Timestamp{stamp(8, 0, 12, 0), stamp(12, 5, 16, 5)},
+			wantWorking:    7.5,
+			wantSubtracted: 0.5,
+		},
+		{
+			name:           "Single 6h15m stamp is not reduced below 6h",
+			timestamps:     []Timestamp{stamp(8, 0, 14, 15)},
+			wantWorking:    6,
+			wantSubtracted: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := TimestampGroup{
+				Timestamps: tt.timestamps,
+			}
+			group.Calculate(DefaultBreakRules())
+
+			if group.WorkingHours != tt.wantWorking {
+				t.Errorf("WorkingHours = %v, want %v", group.WorkingHours, tt.wantWorking)
+			}
+			if group.SubtractedHours != tt.wantSubtracted {
+				t.Errorf("SubtractedHours = %v, want %v", group.SubtractedHours, tt.wantSubtracted)
+			}
+		})
+	}
+}
+
+func TestTimestampCorrectionNeedsApproval(t *testing.T) {
+	now := time.Date(2025, 1, 20, 15, 0, 0, 0, time.UTC)
+	coming := func(day int, minute int) time.Time {
+		return time.Date(2025, 1, day, 8, minute, 0, 0, time.UTC)
+	}
+	going := func(day int, minute int) time.Time {
+		return time.Date(2025, 1, day, 16, minute, 0, 0, time.UTC)
+	}
+
+	testData := []struct {
+		Name      string
+		Settings  Settings
+		OldComing time.Time
+		OldGoing  time.Time
+		NewComing time.Time
+		NewGoing  time.Time
+		Want      bool
+	}{
+		{Name: "disabled", OldComing: coming(1, 0), OldGoing: going(1, 0), NewComing: coming(1, 0), NewGoing: going(1, 300), Want: false},
+		{Name: "age at the limit", Settings: Settings{TimestampCorrectionApprovalAfterDays: 7}, OldComing: coming(13, 0), OldGoing: going(13, 0), NewComing: coming(13, 0), NewGoing: going(13, 0), Want: false},
+		{Name: "age over the limit", Settings: Settings{TimestampCorrectionApprovalAfterDays: 7}, OldComing: coming(12, 0), OldGoing: going(12, 0), NewComing: coming(12, 0), NewGoing: going(12, 0), Want: true},
+		{Name: "minutes at the limit", Settings: Settings{TimestampCorrectionApprovalMinutes: 30}, OldComing: coming(20, 30), OldGoing: going(20, 0), NewComing: coming(20, 0), NewGoing: going(20, 0), Want: false},
+		{Name: "minutes over the limit", Settings: Settings{TimestampCorrectionApprovalMinutes: 30}, OldComing: coming(20, 31), OldGoing: going(20, 0), NewComing: coming(20, 0), NewGoing: going(20, 0), Want: true},
+		{Name: "coming and going summed", Settings: Settings{TimestampCorrectionApprovalMinutes: 30}, OldComing: coming(20, 20), OldGoing: going(20, 0), NewComing: coming(20, 0), NewGoing: going(20, 20), Want: true},
+		{Name: "zero old going", Settings: Settings{TimestampCorrectionApprovalMinutes: 30}, OldComing: coming(20, 10), NewComing: coming(20, 0), NewGoing: going(20, 0), Want: false},
+		{Name: "zero old going over the limit", Settings: Settings{TimestampCorrectionApprovalMinutes: 30}, OldComing: coming(20, 40), NewComing: coming(20, 0), NewGoing: going(20, 0), Want: true},
+	}
+
+	for _, test := range testData {
+		correction := TimestampCorrection{
+			OldComingTimestamp: test.OldComing,
+			OldGoingTimestamp:  test.OldGoing,
+			NewComingTimestamp: test.NewComing,
+			NewGoingTimestamp:  test.NewGoing,
+		}
+		if got := correction.NeedsApproval(test.Settings, now); got != test.Want {
+			t.Errorf("%s: want %t, got %t", test.Name, test.Want, got)
+		}
+	}
+}
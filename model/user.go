@@ -30,6 +30,8 @@ type User struct {
 	OvertimeSubtractionModel  OvertimeSubtractionModel
 	OvertimeSubtractionAmount float64
 	StaffNumber               int64
+	EntryDate                 *time.Time
+	ExitDate                  *time.Time
 }
 
 func NewUser(username string) User {
@@ -67,6 +69,8 @@ type UserUpdateRequest struct {
 	OvertimeSubtractionAmount float64
 	OvertimeSubtractionModel  OvertimeSubtractionModel
 	StaffNumber               int64
+	EntryDate                 *time.Time
+	ExitDate                  *time.Time
 }
 
 type UserResponse struct {
@@ -78,6 +82,9 @@ type UserResponse struct {
 	OvertimeSubtractionModel  OvertimeSubtractionModel
 	OvertimeSubtractionAmount float64
 	StaffNumber               int64
+	HolidayDaysPerYear        uint
+	EntryDate                 *time.Time
+	ExitDate                  *time.Time
 }
 
 type UserApikey struct {
@@ -110,6 +117,9 @@ func (u *User) GetUserResponse() UserResponse {
 		OvertimeSubtractionModel:  u.OvertimeSubtractionModel,
 		OvertimeSubtractionAmount: u.OvertimeSubtractionAmount,
 		StaffNumber:               u.StaffNumber,
+		HolidayDaysPerYear:        u.HolidayDaysPerYear,
+		EntryDate:                 u.EntryDate,
+		ExitDate:                  u.ExitDate,
 	}
 }
 
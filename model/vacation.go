@@ -0,0 +1,326 @@
+package model
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"gorm.io/gorm"
+)
+
+type VacationBucketType string
+
+const (
+	VACATION_BUCKET_TYPE_CARRY_OVER  VacationBucketType = "carry_over"
+	VACATION_BUCKET_TYPE_ENTITLEMENT VacationBucketType = "entitlement"
+	VACATION_BUCKET_TYPE_ADJUSTMENT  VacationBucketType = "adjustment"
+)
+
+// VacationAdjustment is a manual booking of an administrator on the vacation
+// account of a user, negative days reduce the account.
+type VacationAdjustment struct {
+	gorm.Model
+	UserID          uint  `gorm:"not null;index"`
+	User            *User `json:"-"`
+	Year            int   `gorm:"not null"`
+	Days            float64
+	Comment         string
+	CreatedByUserID uint
+	CreatedByUser   *User `json:"-"`
+}
+
+type VacationAdjustmentCreateRequest struct {
+	Year    int     `binding:"required"`
+	Days    float64 `binding:"required"`
+	Comment string  `binding:"required"`
+}
+
+type VacationBucket struct {
+	Type       VacationBucketType
+	OriginYear int
+	Days       float64
+	Used       float64
+	Forfeited  float64
+	ExpiresAt  *time.Time
+	Comment    *string
+}
+
+func (b *VacationBucket) Remaining() float64 {
+	return b.Days - b.Used - b.Forfeited
+}
+
+func (b *VacationBucket) IsValidAt(date time.Time) bool {
+	return b.ExpiresAt == nil || !date.After(*b.ExpiresAt)
+}
+
+// VacationBooking books a working day of an absence, Overdrawn bookings were
+// not covered by the account.
+type VacationBooking struct {
+	AbsenceID  uint
+	Date       time.Time
+	BucketType VacationBucketType
+	OriginYear int
+	Days       float64
+	Overdrawn  bool
+}
+
+type VacationLedgerYear struct {
+	Year      int
+	Buckets   []VacationBucket
+	Bookings  []VacationBooking
+	Used      float64
+	Forfeited float64
+	Remaining float64
+}
+
+type VacationLedger struct {
+	Years []VacationLedgerYear
+}
+
+func (l *VacationLedger) GetYear(year int) *VacationLedgerYear {
+	for i := range l.Years {
+		if l.Years[i].Year == year {
+			return &l.Years[i]
+		}
+	}
+
+	return nil
+}
+
+// GetOverdrawnDays returns the days of the absence which were not covered by
+// the account.
+func (l *VacationLedger) GetOverdrawnDays(absenceID uint) float64 {
+	overdrawn := 0.0
+	for _, year := range l.Years {
+		for _, booking := range year.Bookings {
+			if booking.AbsenceID == absenceID && booking.Overdrawn {
+				overdrawn += booking.Days
+			}
+		}
+	}
+
+	return overdrawn
+}
+
+// VacationLedgerInput contains the vacation data of the user, the holidays and
+// work time models are needed to split the absences into their working days.
+type VacationLedgerInput struct {
+	User                 User
+	Absences             []Absence
+	Adjustments          []VacationAdjustment
+	Holidays             Holidays
+	WorkTimeModels       UserWorkTimeModels
+	CarryOverExpiryMonth int
+	CarryOverExpiryDay   int
+	Now                  time.Time
+}
+
+// GetVacationEntitlement returns the entitlement of the user for the year. For
+// joiners and leavers only the fully employed months count, the result is
+// rounded to half days.
+func GetVacationEntitlement(user User, year int) float64 {
+	months := 0
+	for month := time.January; month <= time.December; month++ {
+		firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		lastOfMonth := firstOfMonth.AddDate(0, 1, -1)
+
+		if user.EntryDate != nil && firstOfMonth.Before(helper.GetDayDate(*user.EntryDate)) {
+			continue
+		}
+
+		if user.ExitDate != nil && lastOfMonth.After(helper.GetDayDate(*user.ExitDate)) {
+			continue
+		}
+
+		months++
+	}
+
+	entitlement := float64(user.HolidayDaysPerYear) * float64(months) / 12.0
+	return math.Round(entitlement*2) / 2
+}
+
+// CalculateVacationLedger builds the vacation account of the user from the first
+// year with vacation data till the given year. Every working day of an absence
+// is booked on its date against the oldest bucket valid on that day, so an
+// absence over the turn of the year is booked in both years. The remaining
+// days of a year are carried over and forfeited after the carry over expiry
+// date.
+func CalculateVacationLedger(input VacationLedgerInput, tillYear int) VacationLedger {
+	ledger := VacationLedger{
+		Years: []VacationLedgerYear{},
+	}
+
+	fromYear := tillYear
+	if input.User.EntryDate != nil && input.User.EntryDate.Year() < fromYear {
+		fromYear = input.User.EntryDate.Year()
+	}
+	for _, absence := range input.Absences {
+		if absence.AbsenceFrom.Year() < fromYear {
+			fromYear = absence.AbsenceFrom.Year()
+		}
+	}
+	for _, adjustment := range input.Adjustments {
+		if adjustment.Year < fromYear {
+			fromYear = adjustment.Year
+		}
+	}
+
+	days := getVacationDays(input.Absences, input.Holidays, input.WorkTimeModels)
+
+	carryOver := 0.0
+	for year := fromYear; year <= tillYear; year++ {
+		ledgerYear := VacationLedgerYear{
+			Year:     year,
+			Buckets:  []VacationBucket{},
+			Bookings: []VacationBooking{},
+		}
+
+		if carryOver != 0 {
+			bucket := VacationBucket{
+				Type:       VACATION_BUCKET_TYPE_CARRY_OVER,
+				OriginYear: year - 1,
+				Days:       carryOver,
+			}
+
+			// only remaining days expire, a negative balance has to be compensated
+			if carryOver > 0 {
+				expiresAt := time.Date(year, time.Month(input.CarryOverExpiryMonth), input.CarryOverExpiryDay, 23, 59, 59, 0, time.UTC)
+				bucket.ExpiresAt = &expiresAt
+			}
+
+			ledgerYear.Buckets = append(ledgerYear.Buckets, bucket)
+		}
+
+		ledgerYear.Buckets = append(ledgerYear.Buckets, VacationBucket{
+			Type:       VACATION_BUCKET_TYPE_ENTITLEMENT,
+			OriginYear: year,
+			Days:       GetVacationEntitlement(input.User, year),
+		})
+
+		for _, adjustment := range input.Adjustments {
+			if adjustment.Year != year {
+				continue
+			}
+
+			ledgerYear.Buckets = append(ledgerYear.Buckets, VacationBucket{
+				Type:       VACATION_BUCKET_TYPE_ADJUSTMENT,
+				OriginYear: year,
+				Days:       adjustment.Days,
+				Comment:    &adjustment.Comment,
+			})
+		}
+
+		for _, day := range days {
+			if day.Date.Year() != year {
+				continue
+			}
+
+			ledgerYear.Bookings = append(ledgerYear.Bookings, ledgerYear.book(day.AbsenceID, day.Date, day.Days)...)
+		}
+
+		endOfYear := time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC)
+		forfeitDate := endOfYear
+		if input.Now.Before(forfeitDate) {
+			forfeitDate = input.Now
+		}
+
+		carryOver = 0
+		for i := range ledgerYear.Buckets {
+			bucket := &ledgerYear.Buckets[i]
+			if !bucket.IsValidAt(forfeitDate) && bucket.Remaining() > 0 {
+				bucket.Forfeited = bucket.Remaining()
+			}
+
+			ledgerYear.Used += bucket.Used
+			ledgerYear.Forfeited += bucket.Forfeited
+			ledgerYear.Remaining += bucket.Remaining()
+
+			if bucket.IsValidAt(endOfYear) {
+				carryOver += bucket.Remaining()
+			}
+		}
+
+		ledger.Years = append(ledger.Years, ledgerYear)
+	}
+
+	return ledger
+}
+
+// getVacationDays splits the absences into their working days ordered by date,
+// the days are the share of the working day like in the netto days. Absences
+// without netto days are not calculated yet and skipped.
+func getVacationDays(absences []Absence, holidays Holidays, workTimeModels UserWorkTimeModels) []VacationBooking {
+	days := []VacationBooking{}
+
+	for _, absence := range absences {
+		if absence.NettoDays == nil {
+			continue
+		}
+
+		lastDay := helper.GetDayDate(absence.AbsenceTill)
+		for day := helper.GetDayDate(absence.AbsenceFrom); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			share, _ := absence.GetDayShare(day, holidays, workTimeModels)
+			if share <= 0 {
+				continue
+			}
+
+			days = append(days, VacationBooking{
+				AbsenceID: absence.ID,
+				Date:      day,
+				Days:      share,
+			})
+		}
+	}
+
+	slices.SortStableFunc(days, func(a, b VacationBooking) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return days
+}
+
+func (y *VacationLedgerYear) book(absenceID uint, date time.Time, days float64) []VacationBooking {
+	bookings := []VacationBooking{}
+
+	var lastValid *VacationBucket
+	for i := range y.Buckets {
+		bucket := &y.Buckets[i]
+		if !bucket.IsValidAt(date) {
+			continue
+		}
+		lastValid = bucket
+
+		if days <= 0 || bucket.Remaining() <= 0 {
+			continue
+		}
+
+		booked := math.Min(days, bucket.Remaining())
+		bucket.Used += booked
+		days -= booked
+
+		bookings = append(bookings, VacationBooking{
+			AbsenceID:  absenceID,
+			Date:       date,
+			BucketType: bucket.Type,
+			OriginYear: bucket.OriginYear,
+			Days:       booked,
+		})
+	}
+
+	// more days than available overdraw the newest bucket
+	if days > 0 && lastValid != nil {
+		lastValid.Used += days
+
+		bookings = append(bookings, VacationBooking{
+			AbsenceID:  absenceID,
+			Date:       date,
+			BucketType: lastValid.Type,
+			OriginYear: lastValid.OriginYear,
+			Days:       days,
+			Overdrawn:  true,
+		})
+	}
+
+	return bookings
+}
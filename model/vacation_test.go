@@ -0,0 +1,181 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCalculateVacationLedger(t *testing.T) {
+	entryDate := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	days := func(value float64) *float64 {
+		return &value
+	}
+
+	input := VacationLedgerInput{
+		User: User{
+			HolidayDaysPerYear: 30,
+			EntryDate:          &entryDate,
+		},
+		Absences: []Absence{
+			{AbsenceFrom: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), NettoDays: days(5)},
+			{AbsenceFrom: time.Date(2023, 8, 7, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2023, 8, 18, 0, 0, 0, 0, time.UTC), NettoDays: days(10)},
+			{AbsenceFrom: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2024, 2, 7, 0, 0, 0, 0, time.UTC), NettoDays: days(3)},
+		},
+		Adjustments: []VacationAdjustment{
+			{Year: 2024, Days: 2, Comment: "bonus"},
+		},
+		CarryOverExpiryMonth: 3,
+		CarryOverExpiryDay:   31,
+		Now:                  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	ledger := CalculateVacationLedger(input, 2024)
+	if len(ledger.Years) != 2 {
+		t.Fatalf("want 2 years, got %d", len(ledger.Years))
+	}
+
+	first := ledger.GetYear(2023)
+	if first.Buckets[0].Days != 15 || first.Remaining != 5 {
+		t.Fatalf("2023: want entitlement 15 and remaining 5, got %f and %f", first.Buckets[0].Days, first.Remaining)
+	}
+
+	second := ledger.GetYear(2024)
+	if second.Buckets[0].Type != VACATION_BUCKET_TYPE_CARRY_OVER || second.Buckets[0].Used != 3 || second.Buckets[0].Forfeited != 2 {
+		t.Fatalf("2024: carry over not booked first or not forfeited: %+v", second.Buckets[0])
+	}
+
+	if second.Used != 8 || second.Forfeited != 2 || second.Remaining != 27 {
+		t.Fatalf("2024: want used 8, forfeited 2, remaining 27, got %f, %f, %f", second.Used, second.Forfeited, second.Remaining)
+	}
+}
+
+func TestCalculateVacationLedgerYearBoundary(t *testing.T) {
+	entryDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := 5.0
+
+	input := VacationLedgerInput{
+		User: User{
+			HolidayDaysPerYear: 12,
+			EntryDate:          &entryDate,
+		},
+		Absences: []Absence{
+			{
+				Model:       gorm.Model{ID: 1},
+				AbsenceFrom: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+				AbsenceTill: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+				NettoDays:   &days,
+			},
+		},
+		Holidays: Holidays{
+			{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		CarryOverExpiryMonth: 3,
+		CarryOverExpiryDay:   31,
+		Now:                  time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	ledger := CalculateVacationLedger(input, 2025)
+
+	first := ledger.GetYear(2024)
+	if len(first.Bookings) != 2 || first.Used != 2 || first.Remaining != 10 {
+		t.Fatalf("2024: want 2 bookings, used 2 and remaining 10, got %d, %f and %f", len(first.Bookings), first.Used, first.Remaining)
+	}
+
+	second := ledger.GetYear(2025)
+	if len(second.Bookings) != 2 || second.Buckets[0].Type != VACATION_BUCKET_TYPE_CARRY_OVER || second.Buckets[0].Used != 2 {
+		t.Fatalf("2025: want the 2 days after the holiday booked on the carry over, got %+v", second.Bookings)
+	}
+
+	if second.Remaining != 20 {
+		t.Fatalf("2025: want remaining 20, got %f", second.Remaining)
+	}
+}
+
+func TestCalculateVacationLedgerExpiry(t *testing.T) {
+	entryDate := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := func(value float64) *float64 {
+		return &value
+	}
+
+	input := VacationLedgerInput{
+		User: User{
+			HolidayDaysPerYear: 12,
+			EntryDate:          &entryDate,
+		},
+		Absences: []Absence{
+			{AbsenceFrom: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2023, 1, 12, 0, 0, 0, 0, time.UTC), NettoDays: days(9)},
+			// thursday till tuesday, the days after the 31st of march can't use the carry over
+			{AbsenceFrom: time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), NettoDays: days(4)},
+		},
+		CarryOverExpiryMonth: 3,
+		CarryOverExpiryDay:   31,
+		Now:                  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	ledger := CalculateVacationLedger(input, 2024)
+	year := ledger.GetYear(2024)
+
+	testData := []struct {
+		Type      VacationBucketType
+		Used      float64
+		Forfeited float64
+	}{
+		{Type: VACATION_BUCKET_TYPE_CARRY_OVER, Used: 2, Forfeited: 1},
+		{Type: VACATION_BUCKET_TYPE_ENTITLEMENT, Used: 2},
+	}
+
+	for i, test := range testData {
+		bucket := year.Buckets[i]
+		if bucket.Type != test.Type || bucket.Used != test.Used || bucket.Forfeited != test.Forfeited {
+			t.Errorf("%s: want used %f and forfeited %f, got %+v", test.Type, test.Used, test.Forfeited, bucket)
+		}
+	}
+
+	if year.Remaining != 10 {
+		t.Errorf("want remaining 10, got %f", year.Remaining)
+	}
+}
+
+func TestVacationLedgerGetOverdrawnDays(t *testing.T) {
+	entryDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := 15.0
+
+	input := VacationLedgerInput{
+		User: User{
+			HolidayDaysPerYear: 12,
+			EntryDate:          &entryDate,
+		},
+		Absences: []Absence{
+			{
+				Model:       gorm.Model{ID: 7},
+				AbsenceFrom: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+				AbsenceTill: time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC),
+				NettoDays:   &days,
+			},
+		},
+		CarryOverExpiryMonth: 3,
+		CarryOverExpiryDay:   31,
+		Now:                  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	ledger := CalculateVacationLedger(input, 2024)
+	overdrawn := ledger.GetOverdrawnDays(7)
+	if overdrawn != 3 {
+		t.Fatalf("want 3 overdrawn days, got %f", overdrawn)
+	}
+}
+
+func TestGetVacationEntitlementLeaver(t *testing.T) {
+	exitDate := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	user := User{
+		HolidayDaysPerYear: 30,
+		ExitDate:           &exitDate,
+	}
+
+	entitlement := GetVacationEntitlement(user, 2024)
+	if entitlement != 10 {
+		t.Fatalf("want 10, got %f", entitlement)
+	}
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"gorm.io/gorm"
+)
+
+const DEFAULT_WORK_TIME_MODEL_NAME = "Standard"
+
+type WorkTimeModel struct {
+	gorm.Model
+	Name           string `gorm:"unique"`
+	HoursMonday    float64
+	HoursTuesday   float64
+	HoursWednesday float64
+	HoursThursday  float64
+	HoursFriday    float64
+	HoursSaturday  float64
+	HoursSunday    float64
+}
+
+func DefaultWorkTimeModel() WorkTimeModel {
+	return WorkTimeModel{
+		Name:           DEFAULT_WORK_TIME_MODEL_NAME,
+		HoursMonday:    8.0,
+		HoursTuesday:   8.0,
+		HoursWednesday: 8.0,
+		HoursThursday:  8.0,
+		HoursFriday:    6.0,
+		HoursSaturday:  0.0,
+		HoursSunday:    0.0,
+	}
+}
+
+func (w *WorkTimeModel) GetHoursForWeekday(weekday time.Weekday) float64 {
+	switch weekday {
+	case time.Monday:
+		return w.HoursMonday
+	case time.Tuesday:
+		return w.HoursTuesday
+	case time.Wednesday:
+		return w.HoursWednesday
+	case time.Thursday:
+		return w.HoursThursday
+	case time.Friday:
+		return w.HoursFriday
+	case time.Saturday:
+		return w.HoursSaturday
+	case time.Sunday:
+		return w.HoursSunday
+	}
+
+	return 0.0
+}
+
+// GetWorkingHoursForDay returns the planned hours of the day reduced by the
+// share of a holiday.
+func (w *WorkTimeModel) GetWorkingHoursForDay(input time.Time, holidays Holidays) float64 {
+	return w.GetHoursForWeekday(input.Weekday()) * (1 - holidays.GetDayOffFactor(input))
+}
+
+func (w *WorkTimeModel) HoursPerWeek() float64 {
+	return w.HoursMonday + w.HoursTuesday + w.HoursWednesday + w.HoursThursday +
+		w.HoursFriday + w.HoursSaturday + w.HoursSunday
+}
+
+type WorkTimeModelCreateRequest struct {
+	Name           string `binding:"required"`
+	HoursMonday    float64
+	HoursTuesday   float64
+	HoursWednesday float64
+	HoursThursday  float64
+	HoursFriday    float64
+	HoursSaturday  float64
+	HoursSunday    float64
+}
+
+type UserWorkTimeModel struct {
+	gorm.Model
+	UserID          uint  `gorm:"not null;index"`
+	User            *User `json:"-"`
+	WorkTimeModelID uint  `gorm:"not null"`
+	WorkTimeModel   WorkTimeModel
+	ValidFrom       time.Time
+	ValidTill       *time.Time
+}
+
+type UserWorkTimeModelCreateRequest struct {
+	WorkTimeModelID uint      `binding:"required"`
+	ValidFrom       time.Time `binding:"required"`
+	ValidTill       *time.Time
+}
+
+func (u *UserWorkTimeModel) IsValidAt(date time.Time) bool {
+	day := helper.GetDayDate(date)
+
+	if day.Before(helper.GetDayDate(u.ValidFrom)) {
+		return false
+	}
+
+	return u.ValidTill == nil || !day.After(helper.GetDayDate(*u.ValidTill))
+}
+
+// Overlaps reports if both assignments are valid on a common day.
+func (u *UserWorkTimeModel) Overlaps(other *UserWorkTimeModel) bool {
+	if u.ValidTill != nil && helper.GetDayDate(*u.ValidTill).Before(helper.GetDayDate(other.ValidFrom)) {
+		return false
+	}
+
+	return other.ValidTill == nil || !helper.GetDayDate(*other.ValidTill).Before(helper.GetDayDate(u.ValidFrom))
+}
+
+type UserWorkTimeModels []UserWorkTimeModel
+
+// GetWorkTimeModelForDay returns the model assigned to the user on the given day.
+// If several assignments overlap the one starting last wins, without any
+// assignment the default model is used.
+func (u UserWorkTimeModels) GetWorkTimeModelForDay(date time.Time) WorkTimeModel {
+	var current *UserWorkTimeModel
+
+	for i := range u {
+		if !u[i].IsValidAt(date) {
+			continue
+		}
+
+		if current == nil || u[i].ValidFrom.After(current.ValidFrom) {
+			current = &u[i]
+		}
+	}
+
+	if current == nil {
+		return DefaultWorkTimeModel()
+	}
+
+	return current.WorkTimeModel
+}
+
+func (u UserWorkTimeModels) GetWorkingHoursForDay(date time.Time, holidays Holidays) float64 {
+	workTimeModel := u.GetWorkTimeModelForDay(date)
+	return workTimeModel.GetWorkingHoursForDay(date, holidays)
+}
+
+func (u UserWorkTimeModels) IsWorkingDay(date time.Time, holidays Holidays) bool {
+	return u.GetWorkingHoursForDay(date, holidays) > 0
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserWorkTimeModelIsValidAt(t *testing.T) {
+	validTill := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	assignment := UserWorkTimeModel{
+		ValidFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		ValidTill: &validTill,
+	}
+
+	testData := []struct {
+		Name string
+		Date time.Time
+		Want bool
+	}{
+		{Name: "day before", Date: time.Date(2024, 5, 31, 23, 59, 0, 0, time.UTC), Want: false},
+		{Name: "first day", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Want: true},
+		{Name: "first day evening", Date: time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC), Want: true},
+		{Name: "last day evening", Date: time.Date(2024, 6, 30, 23, 59, 0, 0, time.UTC), Want: true},
+		{Name: "day after", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Want: false},
+	}
+
+	for _, test := range testData {
+		got := assignment.IsValidAt(test.Date)
+		if got != test.Want {
+			t.Errorf("%s: want %t, got %t", test.Name, test.Want, got)
+		}
+	}
+}
+
+func TestUserWorkTimeModelsGetWorkTimeModelForDay(t *testing.T) {
+	firstTill := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	workTimeModels := UserWorkTimeModels{
+		{
+			WorkTimeModel: WorkTimeModel{Name: "first"},
+			ValidFrom:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidTill:     &firstTill,
+		},
+		{
+			WorkTimeModel: WorkTimeModel{Name: "second"},
+			ValidFrom:     time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	testData := []struct {
+		Name string
+		Date time.Time
+		Want string
+	}{
+		{Name: "before all", Date: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), Want: DEFAULT_WORK_TIME_MODEL_NAME},
+		{Name: "first day of first", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Want: "first"},
+		{Name: "last day of first", Date: time.Date(2024, 5, 31, 18, 0, 0, 0, time.UTC), Want: "first"},
+		{Name: "first day of second", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Want: "second"},
+		{Name: "open end", Date: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Want: "second"},
+	}
+
+	for _, test := range testData {
+		got := workTimeModels.GetWorkTimeModelForDay(test.Date)
+		if got.Name != test.Want {
+			t.Errorf("%s: want %s, got %s", test.Name, test.Want, got.Name)
+		}
+	}
+}
+
+func TestUserWorkTimeModelOverlaps(t *testing.T) {
+	date := func(month int, day int) *time.Time {
+		value := time.Date(2024, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		return &value
+	}
+
+	existing := UserWorkTimeModel{ValidFrom: *date(3, 1), ValidTill: date(3, 31)}
+
+	testData := []struct {
+		Name      string
+		ValidFrom time.Time
+		ValidTill *time.Time
+		Want      bool
+	}{
+		{Name: "ends the day before", ValidFrom: *date(2, 1), ValidTill: date(2, 29), Want: false},
+		{Name: "ends on the first day", ValidFrom: *date(2, 1), ValidTill: date(3, 1), Want: true},
+		{Name: "inside", ValidFrom: *date(3, 10), ValidTill: date(3, 20), Want: true},
+		{Name: "starts on the last day", ValidFrom: *date(3, 31), Want: true},
+		{Name: "starts the day after", ValidFrom: *date(4, 1), Want: false},
+		{Name: "open before", ValidFrom: *date(1, 1), Want: true},
+	}
+
+	for _, test := range testData {
+		other := UserWorkTimeModel{ValidFrom: test.ValidFrom, ValidTill: test.ValidTill}
+		if got := existing.Overlaps(&other); got != test.Want {
+			t.Errorf("%s: want %t, got %t", test.Name, test.Want, got)
+		}
+		if got := other.Overlaps(&existing); got != test.Want {
+			t.Errorf("%s (reversed): want %t, got %t", test.Name, test.Want, got)
+		}
+	}
+}
@@ -6,10 +6,58 @@ import (
 	"time"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+type AbsenceRepository interface {
+	FindAll(withRelations bool) ([]model.Absence, error)
+	FindByAbsenceTillBetween(from time.Time, till time.Time) ([]model.Absence, error)
+	FindSince(since time.Time) ([]model.Absence, error)
+	FindByUserIdsSince(userIds []uint, since time.Time) ([]model.Absence, error)
+	FindOverlapping(from time.Time, till time.Time) ([]model.Absence, error)
+	FindByUserIdsOverlapping(userIds []uint, from time.Time, till time.Time) ([]model.Absence, error)
+	FindUnsigned() ([]model.Absence, error)
+	FindUnsignedByUserIdsAndReasonIds(userIds []uint, reasonIds []uint) ([]model.Absence, error)
+	FindByID(id uint) (model.Absence, error)
+	FindByUserID(userID uint) ([]model.Absence, error)
+	FindVacationByUserID(userID uint) ([]model.Absence, error)
+	FindByUserIDAndYear(userID uint, year int) ([]model.Absence, error)
+	Insert(tx *gorm.DB, absence *model.Absence) error
+	Update(tx *gorm.DB, absence *model.Absence) error
+	Delete(tx *gorm.DB, absence *model.Absence) error
+	AbsenceApprovalInsert(tx *gorm.DB, item *model.AbsenceApproval) error
+	FindAllAbsenceReasons() ([]model.AbsenceReason, error)
+	InsertAbsenceReason(absenceReason *model.AbsenceReason) error
+	FindAbsenceReasonByID(id uint) (model.AbsenceReason, error)
+	UpdateAbsenceReason(tx *gorm.DB, item *model.AbsenceReason) error
+	ReplaceAbsenceReasonApprovalSteps(tx *gorm.DB, item *model.AbsenceReason, steps []model.AbsenceApprovalStep) error
+	DeleteAbsenceReason(item *model.AbsenceReason) error
+	FindYearsWithAbsencesByUserId(userID uint) ([]int, error)
+	AbsenceExternalEventFindAll() ([]model.AbsenceExternalEvent, error)
+	AbsenceExternalEventFindById(id uint) (model.AbsenceExternalEvent, error)
+	AbsenceExternalEventInsert(item *model.AbsenceExternalEvent) error
+	AbsenceExternalEventUpdate(item *model.AbsenceExternalEvent) error
+	AbsenceExternalEventDelete(tx *gorm.DB, item *model.AbsenceExternalEvent) error
+	AbsenceExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.AbsenceExternalEvent, error)
+	AbsenceExternalEventFindByProviderAndAbsenceId(provider model.ExternalEventProvider, absenceId uint) ([]model.AbsenceExternalEvent, error)
+	FindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.Absence, error)
+	AbsenceExternalEventFindByAbsenceId(absenceId uint) ([]model.AbsenceExternalEvent, error)
+	AbsenceFindByUserIDAndBetweenDates(userID uint, start time.Time, end time.Time) ([]model.Absence, error)
+	AbsenceChangeRequestFindByUserId(userId uint) ([]model.AbsenceChangeRequest, error)
+	AbsenceChangeRequestFindPendingByUserIds(userIds []uint) ([]model.AbsenceChangeRequest, error)
+	AbsenceChangeRequestFindPendingByAbsenceId(absenceId uint) ([]model.AbsenceChangeRequest, error)
+	AbsenceChangeRequestFindById(id uint) (model.AbsenceChangeRequest, error)
+	AbsenceChangeRequestInsert(item *model.AbsenceChangeRequest) error
+	AbsenceChangeRequestUpdate(tx *gorm.DB, item *model.AbsenceChangeRequest) error
+}
+
+var _ AbsenceRepository = (*Absence)(nil)
+
+var ErrAbsenceNotFound = errors.New("Absence not found")
+
 type Absence struct {
 	env *core.Environment
 }
@@ -42,15 +90,37 @@ func (r *Absence) Migrate() error {
 		return err
 	}
 
+	err = db.AutoMigrate(&model.AbsenceApprovalStep{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&model.AbsenceApproval{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&model.AbsenceChangeRequest{})
+	if err != nil {
+		return err
+	}
+
+	vacationReason := "Urlaub"
 	absenceReasons := []string{
 		"Krank (mit AU)",
 		"Krank (ohne AU)",
 		"Sonderurlaub",
-		"Urlaub",
+		vacationReason,
 		"Berufsschule",
 		"Aussendienst",
 	}
 
+	// reasons created before vacation tracking
+	err = db.Model(&model.AbsenceReason{}).Where("description = ? AND deducts_vacation IS NULL", vacationReason).Update("deducts_vacation", true).Error
+	if err != nil {
+		return err
+	}
+
 	existingReasons, err := r.FindAllAbsenceReasons()
 	if err != nil {
 		return err
@@ -66,8 +136,10 @@ func (r *Absence) Migrate() error {
 		}
 
 		if !reasonExists {
+			deductsVacation := reason == vacationReason
 			err = r.InsertAbsenceReason(&model.AbsenceReason{
-				Description: reason,
+				Description:     reason,
+				DeductsVacation: &deductsVacation,
 			})
 
 			if err != nil {
@@ -96,7 +168,9 @@ func (r *Absence) FindAll(withRelations bool) ([]model.Absence, error) {
 	return items, result.Error
 }
 
-func (r *Absence) FindByQuery(withUser bool, query string, args ...interface{}) ([]model.Absence, error) {
+// findByQuery returns the absences matching the query, withUser preloads all
+// associations.
+func (r *Absence) findByQuery(withUser bool, query string, args ...interface{}) ([]model.Absence, error) {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
 		return nil, err
@@ -107,12 +181,49 @@ func (r *Absence) FindByQuery(withUser bool, query string, args ...interface{})
 
 	result := db
 	if withUser {
-		result = result.Debug().Preload(clause.Associations)
+		result = result.Preload("AbsenceReason.ApprovalSteps").Preload(clause.Associations)
 	}
 	result = result.Where(query, args...).Find(&items)
 	return items, result.Error
 }
 
+// FindByAbsenceTillBetween returns the absences ending between from and till.
+func (r *Absence) FindByAbsenceTillBetween(from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.findByQuery(true, "absence_till between ? and ?", from, till)
+}
+
+// FindSince returns the absences ending on or after since.
+func (r *Absence) FindSince(since time.Time) ([]model.Absence, error) {
+	return r.findByQuery(true, "absence_till >= ?", since)
+}
+
+// FindByUserIdsSince returns the absences of the users ending on or after since.
+func (r *Absence) FindByUserIdsSince(userIds []uint, since time.Time) ([]model.Absence, error) {
+	return r.findByQuery(true, "user_id in ? and absence_till >= ?", userIds, since)
+}
+
+// FindOverlapping returns the absences overlapping the period from till.
+func (r *Absence) FindOverlapping(from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.findByQuery(false, "absence_from <= ? and absence_till >= ?", till, from)
+}
+
+// FindByUserIdsOverlapping returns the absences of the users overlapping the
+// period from till.
+func (r *Absence) FindByUserIdsOverlapping(userIds []uint, from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.findByQuery(false, "user_id in ? and absence_from <= ? and absence_till >= ?", userIds, till, from)
+}
+
+// FindUnsigned returns the absences not signed yet.
+func (r *Absence) FindUnsigned() ([]model.Absence, error) {
+	return r.findByQuery(true, "signed_user_id is null")
+}
+
+// FindUnsignedByUserIdsAndReasonIds returns the not signed absences of the
+// users with one of the reasons.
+func (r *Absence) FindUnsignedByUserIdsAndReasonIds(userIds []uint, reasonIds []uint) ([]model.Absence, error) {
+	return r.findByQuery(true, "user_id in ? and absence_reason_id in ? and signed_user_id is null", userIds, reasonIds)
+}
+
 func (r *Absence) FindByID(id uint) (model.Absence, error) {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -121,10 +232,10 @@ func (r *Absence) FindByID(id uint) (model.Absence, error) {
 	defer r.env.DatabaseManager.CloseConnection(db)
 
 	var item model.Absence
-	result := db.Preload(clause.Associations).Find(&item, "id = ?", id)
+	result := db.Preload("AbsenceReason.ApprovalSteps").Preload(clause.Associations).Find(&item, "id = ?", id)
 
 	if result.RowsAffected == 0 {
-		return model.Absence{}, fmt.Errorf("no absence with id %d found", id)
+		return model.Absence{}, ErrAbsenceNotFound
 	}
 
 	return item, result.Error
@@ -143,6 +254,23 @@ func (r *Absence) FindByUserID(userID uint) ([]model.Absence, error) {
 	return items, result.Error
 }
 
+// FindVacationByUserID returns the absences of the user with a reason deducting
+// vacation days.
+func (r *Absence) FindVacationByUserID(userID uint) ([]model.Absence, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	vacationReasons := db.Table("beetc_absence_reason").Select("id").Where("deducts_vacation = ?", true)
+
+	var items []model.Absence
+	result := db.Preload("AbsenceReason").Where("user_id = ? AND absence_reason_id IN (?)", userID, vacationReasons).Find(&items)
+
+	return items, result.Error
+}
+
 func (r *Absence) FindByUserIDAndYear(userID uint, year int) ([]model.Absence, error) {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -158,36 +286,50 @@ func (r *Absence) FindByUserIDAndYear(userID uint, year int) ([]model.Absence, e
 	return items, result.Error
 }
 
-func (r *Absence) Insert(absence *model.Absence) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r *Absence) Insert(tx *gorm.DB, absence *model.Absence) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Create(&absence)
 	return result.Error
 }
 
-func (r *Absence) Update(absence *model.Absence) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+// Update saves the absence without its associations, new approvals are
+// stored with AbsenceApprovalInsert.
+func (r *Absence) Update(tx *gorm.DB, absence *model.Absence) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
-	result := db.Updates(&absence)
+	result := db.Omit(clause.Associations).Updates(absence)
 	return result.Error
 }
 
-func (r *Absence) Delete(absence *model.Absence) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+// Delete soft deletes the absence, it is kept as history of its change requests.
+func (r *Absence) Delete(tx *gorm.DB, absence *model.Absence) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
-	result := db.Unscoped().Delete(&absence)
+	result := db.Delete(absence)
+	return result.Error
+}
+
+func (r *Absence) AbsenceApprovalInsert(tx *gorm.DB, item *model.AbsenceApproval) error {
+	db, closeConnection, err := getConnection(r.env, tx)
+	if err != nil {
+		return err
+	}
+	defer closeConnection()
+
+	result := db.Omit(clause.Associations).Create(item)
 	return result.Error
 }
 
@@ -199,7 +341,7 @@ func (r *Absence) FindAllAbsenceReasons() ([]model.AbsenceReason, error) {
 	defer r.env.DatabaseManager.CloseConnection(db)
 
 	var items []model.AbsenceReason
-	result := db.Find(&items)
+	result := db.Preload("ApprovalSteps").Find(&items)
 
 	return items, result.Error
 }
@@ -223,7 +365,7 @@ func (r *Absence) FindAbsenceReasonByID(id uint) (model.AbsenceReason, error) {
 	defer r.env.DatabaseManager.CloseConnection(db)
 
 	var item model.AbsenceReason
-	result := db.Find(&item, "id = ?", id)
+	result := db.Preload("ApprovalSteps").Find(&item, "id = ?", id)
 
 	if result.RowsAffected == 0 {
 		return model.AbsenceReason{}, fmt.Errorf("no absence with id %d found", id)
@@ -232,17 +374,44 @@ func (r *Absence) FindAbsenceReasonByID(id uint) (model.AbsenceReason, error) {
 	return item, result.Error
 }
 
-func (r *Absence) UpdateAbsenceReason(item *model.AbsenceReason) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+// UpdateAbsenceReason writes all fields of the reason, including zero values.
+// The approval steps are replaced with ReplaceAbsenceReasonApprovalSteps.
+func (r *Absence) UpdateAbsenceReason(tx *gorm.DB, item *model.AbsenceReason) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
-	result := db.Updates(&item)
+	result := db.Select("*").Omit(clause.Associations).Updates(item)
 	return result.Error
 }
 
+func (r *Absence) ReplaceAbsenceReasonApprovalSteps(tx *gorm.DB, item *model.AbsenceReason, steps []model.AbsenceApprovalStep) error {
+	db, closeConnection, err := getConnection(r.env, tx)
+	if err != nil {
+		return err
+	}
+	defer closeConnection()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("absence_reason_id = ?", item.ID).Delete(&model.AbsenceApprovalStep{}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range steps {
+			steps[i].AbsenceReasonID = item.ID
+		}
+
+		if len(steps) == 0 {
+			return nil
+		}
+
+		return tx.Create(&steps).Error
+	})
+}
+
 func (r *Absence) DeleteAbsenceReason(item *model.AbsenceReason) error {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -264,7 +433,7 @@ func (r Absence) FindYearsWithAbsencesByUserId(userID uint) ([]int, error) {
 	var items []int
 
 	result := db.Model(&model.Absence{}).
-		Select("distinct extract(year from absence_from) as year").
+		Select(fmt.Sprintf("distinct %s as year", database.YearOf(db, "absence_from"))).
 		Where("user_id = ?", userID).
 		Scan(&items)
 
@@ -325,21 +494,67 @@ func (r Absence) AbsenceExternalEventUpdate(item *model.AbsenceExternalEvent) er
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Updates(item)
+	result := db.Select("*").Omit("created_at", clause.Associations).Updates(item)
 	return result.Error
 }
 
-func (r Absence) AbsenceExternalEventDelete(item *model.AbsenceExternalEvent) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r Absence) AbsenceExternalEventDelete(tx *gorm.DB, item *model.AbsenceExternalEvent) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Unscoped().Delete(item)
 	return result.Error
 }
 
+// AbsenceExternalEventFindByProvider returns the events with their absences,
+// cancelled absences are included.
+func (r Absence) AbsenceExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.AbsenceExternalEvent, error) {
+	var items []model.AbsenceExternalEvent
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload("Absence", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Absence.User").Preload("Absence.AbsenceReason").Find(&items, "external_event_provider = ?", provider)
+	return items, result.Error
+}
+
+func (r Absence) AbsenceExternalEventFindByProviderAndAbsenceId(provider model.ExternalEventProvider, absenceId uint) ([]model.AbsenceExternalEvent, error) {
+	var items []model.AbsenceExternalEvent
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload("Absence", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Absence.User").Preload("Absence.AbsenceReason").Find(&items, "external_event_provider = ? and absence_id = ?", provider, absenceId)
+	return items, result.Error
+}
+
+// FindWithoutExternalEvent returns the not declined absences ending after since
+// which have no event of the provider.
+func (r *Absence) FindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.Absence, error) {
+	var items []model.Absence
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Where("absence_till >= ? and (signed_status is null or signed_status != ?)", since, model.SIGNED_STATUS_DECLINED).
+		Where("id not in (?)", db.Table("beetc_absence_external_event").Select("absence_id").Where("external_event_provider = ? and deleted_at is null", provider)).
+		Find(&items)
+	return items, result.Error
+}
+
 func (r Absence) AbsenceExternalEventFindByAbsenceId(absenceId uint) ([]model.AbsenceExternalEvent, error) {
 	var items []model.AbsenceExternalEvent
 	db, err := r.env.DatabaseManager.GetConnection()
@@ -369,3 +584,88 @@ func (r Absence) AbsenceFindByUserIDAndBetweenDates(userID uint, start time.Time
 	}
 	return items, result.Error
 }
+
+var ErrAbsenceChangeRequestNotFound = errors.New("AbsenceChangeRequest not found")
+
+func preloadAbsenceChangeRequest(db *gorm.DB) *gorm.DB {
+	return db.Preload("Absence", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Absence.AbsenceReason").Preload("User")
+}
+
+func (r Absence) AbsenceChangeRequestFindByUserId(userId uint) ([]model.AbsenceChangeRequest, error) {
+	var items []model.AbsenceChangeRequest
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := preloadAbsenceChangeRequest(db).Order("created_at desc").Find(&items, "user_id = ?", userId)
+	return items, result.Error
+}
+
+func (r Absence) AbsenceChangeRequestFindPendingByUserIds(userIds []uint) ([]model.AbsenceChangeRequest, error) {
+	var items []model.AbsenceChangeRequest
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := preloadAbsenceChangeRequest(db).Find(&items, "user_id in ? and status = ?", userIds, model.ABSENCE_CHANGE_REQUEST_STATUS_PENDING)
+	return items, result.Error
+}
+
+func (r Absence) AbsenceChangeRequestFindPendingByAbsenceId(absenceId uint) ([]model.AbsenceChangeRequest, error) {
+	var items []model.AbsenceChangeRequest
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Find(&items, "absence_id = ? and status = ?", absenceId, model.ABSENCE_CHANGE_REQUEST_STATUS_PENDING)
+	return items, result.Error
+}
+
+func (r Absence) AbsenceChangeRequestFindById(id uint) (model.AbsenceChangeRequest, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.AbsenceChangeRequest{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.AbsenceChangeRequest
+	result := preloadAbsenceChangeRequest(db).Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.AbsenceChangeRequest{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.AbsenceChangeRequest{}, ErrAbsenceChangeRequestNotFound
+	}
+	return item, result.Error
+}
+
+func (r Absence) AbsenceChangeRequestInsert(item *model.AbsenceChangeRequest) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r Absence) AbsenceChangeRequestUpdate(tx *gorm.DB, item *model.AbsenceChangeRequest) error {
+	db, closeConnection, err := getConnection(r.env, tx)
+	if err != nil {
+		return err
+	}
+	defer closeConnection()
+
+	result := db.Omit("Absence", "User", "SignedUser").Updates(item)
+	return result.Error
+}
@@ -0,0 +1,84 @@
+//go:build cgo
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+func TestUpdateAbsenceReason(t *testing.T) {
+	t.Setenv("DB_TYPE", database.DB_TYPE_SQLITE)
+	t.Setenv("DATABASE", filepath.Join(t.TempDir(), "beetimeclock.db"))
+
+	env := core.NewEnvironment()
+	env.DatabaseManager = database.NewDatabaseManager("beetc")
+
+	userRepo := NewUser(env)
+	repo := NewAbsence(env)
+	for _, migrate := range []func() error{userRepo.Migrate, repo.Migrate} {
+		err := migrate()
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	needsApproval := true
+	reason := model.AbsenceReason{
+		Description:    "Fortbildung",
+		OvertimeImpact: model.ABESENCE_REASON_OVERTIME_IMPACT_HOURS,
+		ImpactHours:    4,
+		NeedsApproval:  &needsApproval,
+	}
+	err := repo.InsertAbsenceReason(&reason)
+	if err == nil {
+		err = repo.ReplaceAbsenceReasonApprovalSteps(nil, &reason, []model.AbsenceApprovalStep{
+			{Position: 1, ApproverType: model.ABSENCE_APPROVER_TYPE_TEAM_LEAD},
+			{Position: 2, ApproverType: model.ABSENCE_APPROVER_TYPE_ADMINISTRATOR},
+		})
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	reason, err = repo.FindAbsenceReasonByID(reason.ID)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	needsApproval = false
+	reason.NeedsApproval = &needsApproval
+	reason.OvertimeImpact = model.ABESENCE_REASON_OVERTIME_IMPACT_NONE
+	reason.ImpactHours = 0
+	err = repo.UpdateAbsenceReason(nil, &reason)
+	if err == nil {
+		err = repo.ReplaceAbsenceReasonApprovalSteps(nil, &reason, []model.AbsenceApprovalStep{
+			{Position: 1, ApproverType: model.ABSENCE_APPROVER_TYPE_ADMINISTRATOR},
+		})
+	}
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	reason, err = repo.FindAbsenceReasonByID(reason.ID)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if reason.ImpactHours != 0 {
+		t.Errorf("want impact hours 0, got %f", reason.ImpactHours)
+	}
+	if reason.OvertimeImpact != model.ABESENCE_REASON_OVERTIME_IMPACT_NONE {
+		t.Errorf("want overtime impact %s, got %s", model.ABESENCE_REASON_OVERTIME_IMPACT_NONE, reason.OvertimeImpact)
+	}
+	if reason.NeedsApproval == nil || *reason.NeedsApproval {
+		t.Errorf("want no approval needed, got %v", reason.NeedsApproval)
+	}
+	if len(reason.ApprovalSteps) != 1 || reason.ApprovalSteps[0].ApproverType != model.ABSENCE_APPROVER_TYPE_ADMINISTRATOR {
+		t.Errorf("want only the administrator approval step, got %+v", reason.ApprovalSteps)
+	}
+}
@@ -1,6 +1,25 @@
 package repository
 
-import "reflect"
+import (
+	"reflect"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"gorm.io/gorm"
+)
+
+// getConnection returns the transaction if one is given, otherwise a new
+// connection which is closed by the returned func.
+func getConnection(env *core.Environment, tx *gorm.DB) (*gorm.DB, func(), error) {
+	if tx != nil {
+		return tx, func() {}, nil
+	}
+
+	db, err := env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { env.DatabaseManager.CloseConnection(db) }, nil
+}
 
 func ToMap(data interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
@@ -7,9 +7,44 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+type ExternalWorkRepository interface {
+	ExternalWorkFindAll() ([]model.ExternalWork, error)
+	ExternalWorkFindById(id uint, with_associations bool) (model.ExternalWork, error)
+	ExternalWorkFindByUserID(userId uint) ([]model.ExternalWork, error)
+	ExternalWorkFindByUserIDAndInvoiceIdentifier(userId uint, invoiceIdentifier uuid.UUID) ([]model.ExternalWork, error)
+	ExternalWorkInsert(tx *gorm.DB, item *model.ExternalWork) error
+	ExternalWorkUpdate(item *model.ExternalWork) error
+	ExternalWorkDelete(tx *gorm.DB, item *model.ExternalWork) error
+	ExternalWorkExpenseFindAll() ([]model.ExternalWorkExpense, error)
+	ExternalWorkExpenseFindById(id uint) (model.ExternalWorkExpense, error)
+	ExternalWorkExpenseFindByExternalWorkId(externalWorkId uint) ([]model.ExternalWorkExpense, error)
+	ExternalWorkExpenseInsert(item *model.ExternalWorkExpense) error
+	ExternalWorkExpenseUpdate(item *model.ExternalWorkExpense) error
+	ExternalWorkExpenseDelete(tx *gorm.DB, item *model.ExternalWorkExpense) error
+	ExternalWorkExternalEventFindAll() ([]model.ExternalWorkExternalEvent, error)
+	ExternalWorkExternalEventFindById(id uint) (model.ExternalWorkExternalEvent, error)
+	ExternalWorkExternalEventInsert(item *model.ExternalWorkExternalEvent) error
+	ExternalWorkExternalEventUpdate(item *model.ExternalWorkExternalEvent) error
+	ExternalWorkExternalEventDelete(item *model.ExternalWorkExternalEvent) error
+	ExternalWorkExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.ExternalWorkExternalEvent, error)
+	ExternalWorkExternalEventFindByProviderAndExternalWorkId(provider model.ExternalEventProvider, externalWorkId uint) ([]model.ExternalWorkExternalEvent, error)
+	ExternalWorkFindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.ExternalWork, error)
+	ExternalWorkFindByUserIDAndEndBetween(userId uint, start time.Time, end time.Time) ([]model.ExternalWork, error)
+	ExternalWorkFindByUserIDAndStatus(userId uint, status model.ExternalWorkStatus) ([]model.ExternalWork, error)
+	ExternalWorkCompensationFindAll() ([]model.ExternalWorkCompensation, error)
+	ExternalWorkCompensationFindById(id uint) (model.ExternalWorkCompensation, error)
+	ExternalWorkCompensationInsert(item *model.ExternalWorkCompensation) error
+	ExternalWorkCompensationUpdate(item *model.ExternalWorkCompensation) error
+	ExternalWorkCompensationDelete(item *model.ExternalWorkCompensation) error
+	ExternalWorkCompensationFindByCountryCode(countryCode string) (model.ExternalWorkCompensation, error)
+}
+
+var _ ExternalWorkRepository = (*ExternalWork)(nil)
+
 type ExternalWork struct {
 	env *core.Environment
 }
@@ -171,12 +206,12 @@ func (r ExternalWork) ExternalWorkFindByUserIDAndInvoiceIdentifier(userId uint,
 	return items, result.Error
 }
 
-func (r ExternalWork) ExternalWorkInsert(item *model.ExternalWork) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r ExternalWork) ExternalWorkInsert(tx *gorm.DB, item *model.ExternalWork) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Create(item)
 	return result.Error
@@ -193,12 +228,12 @@ func (r ExternalWork) ExternalWorkUpdate(item *model.ExternalWork) error {
 	return result.Error
 }
 
-func (r ExternalWork) ExternalWorkDelete(item *model.ExternalWork) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r ExternalWork) ExternalWorkDelete(tx *gorm.DB, item *model.ExternalWork) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Delete(item)
 	return result.Error
@@ -296,12 +331,12 @@ func (r ExternalWork) ExternalWorkExpenseUpdate(item *model.ExternalWorkExpense)
 	return result.Error
 }
 
-func (r ExternalWork) ExternalWorkExpenseDelete(item *model.ExternalWorkExpense) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r ExternalWork) ExternalWorkExpenseDelete(tx *gorm.DB, item *model.ExternalWorkExpense) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Delete(item)
 	return result.Error
@@ -361,7 +396,7 @@ func (r ExternalWork) ExternalWorkExternalEventUpdate(item *model.ExternalWorkEx
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Updates(item)
+	result := db.Select("*").Omit("created_at", clause.Associations).Updates(item)
 	return result.Error
 }
 
@@ -376,6 +411,52 @@ func (r ExternalWork) ExternalWorkExternalEventDelete(item *model.ExternalWorkEx
 	return result.Error
 }
 
+// ExternalWorkExternalEventFindByProvider returns the events with their external
+// works, deleted external works are included.
+func (r ExternalWork) ExternalWorkExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.ExternalWorkExternalEvent, error) {
+	var items []model.ExternalWorkExternalEvent
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload("ExternalWork", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("ExternalWork.User").Find(&items, "external_event_provider = ?", provider)
+	return items, result.Error
+}
+
+func (r ExternalWork) ExternalWorkExternalEventFindByProviderAndExternalWorkId(provider model.ExternalEventProvider, externalWorkId uint) ([]model.ExternalWorkExternalEvent, error) {
+	var items []model.ExternalWorkExternalEvent
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload("ExternalWork", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("ExternalWork.User").Find(&items, "external_event_provider = ? and external_work_id = ?", provider, externalWorkId)
+	return items, result.Error
+}
+
+// ExternalWorkFindWithoutExternalEvent returns the external works ending after
+// since which have no event of the provider.
+func (r ExternalWork) ExternalWorkFindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.ExternalWork, error) {
+	var items []model.ExternalWork
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Where("till >= ?", since).
+		Where("id not in (?)", db.Table("beetc_external_work_external_event").Select("external_work_id").Where("external_event_provider = ? and deleted_at is null", provider)).
+		Find(&items)
+	return items, result.Error
+}
+
 func (r ExternalWork) ExternalWorkFindByUserIDAndEndBetween(userId uint, start time.Time, end time.Time) ([]model.ExternalWork, error) {
 	var items []model.ExternalWork
 	db, err := r.env.DatabaseManager.GetConnection()
@@ -8,6 +8,21 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 )
 
+type FuelRepository interface {
+	FindAll() ([]model.Fuel, error)
+	FindByID(id uint) (model.Fuel, error)
+	FindByUserID(userID uint) ([]model.Fuel, error)
+	FindByUserIDAndState(userID uint, state model.FuelState) ([]model.Fuel, error)
+	FindLastByUserID(userID uint) (model.Fuel, error)
+	FindByUserIDAndDate(userID uint, from, till time.Time) ([]model.Fuel, error)
+	CountByUserID(userID uint) (int64, error)
+	Insert(fuel *model.Fuel) error
+	Update(fuel *model.Fuel) error
+	Delete(fuel *model.Fuel) error
+}
+
+var _ FuelRepository = (*Fuel)(nil)
+
 type Fuel struct {
 	env *core.Environment
 }
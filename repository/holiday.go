@@ -6,8 +6,36 @@ import (
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+type HolidayRepository interface {
+	HolidayFindAll() ([]model.Holiday, error)
+	HolidayFindById(id uint) (model.Holiday, error)
+	HolidayInsert(item *model.Holiday) error
+	HolidayUpdate(item *model.Holiday) error
+	HolidayDelete(item *model.Holiday) error
+	HolidayFindByDate(date time.Time) (model.Holiday, error)
+	HolidayIsByDate(date time.Time) (bool, error)
+	HolidayFindByDateRange(start time.Time, end time.Time) ([]model.Holiday, error)
+	HolidayFindByUserIdAndDateRange(userId uint, start time.Time, end time.Time) (model.Holidays, error)
+	HolidayFindByUserIdAndYear(userId uint, year int) (model.Holidays, error)
+	HolidayFindByLocationIdAndYear(locationId uint, year int) (model.Holidays, error)
+	HolidayDeleteByLocationId(locationId uint) error
+	HolidayCustomFindAll() ([]model.HolidayCustom, error)
+	HolidayCustomFindById(id uint) (model.HolidayCustom, error)
+	HolidayCustomInsert(item *model.HolidayCustom) error
+	HolidayCustomUpdate(item *model.HolidayCustom) error
+	HolidayCustomDelete(item *model.HolidayCustom) error
+}
+
+var _ HolidayRepository = (*Holiday)(nil)
+
+// HOLIDAY_WITHOUT_LOCATION_INDEX keeps the holidays without location unique
+// by date.
+const HOLIDAY_WITHOUT_LOCATION_INDEX = "idx_holiday_date_without_location"
+
 type Holiday struct {
 	env *core.Environment
 }
@@ -25,11 +53,49 @@ func (r *Holiday) Migrate() error {
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
+	// holidays were unique by date before they were stored per location
+	if db.Migrator().HasIndex(&model.Holiday{}, "idx_beetc_holiday_date") {
+		err = db.Migrator().DropIndex(&model.Holiday{}, "idx_beetc_holiday_date")
+		if err != nil {
+			return err
+		}
+	}
+
 	err = db.AutoMigrate(&model.Holiday{}, &model.HolidayCustom{})
 	if err != nil {
 		return err
 	}
 
+	// NULLs are distinct in idx_holiday_location_date, holidays without location
+	// need their own index. MySQL has no partial indexes, there only the
+	// assignment to the default location below prevents duplicates.
+	if db.Dialector.Name() != "mysql" && !db.Migrator().HasIndex(&model.Holiday{}, HOLIDAY_WITHOUT_LOCATION_INDEX) {
+		statement := &gorm.Statement{DB: db}
+		err = statement.Parse(&model.Holiday{})
+		if err != nil {
+			return err
+		}
+
+		err = db.Exec("CREATE UNIQUE INDEX ? ON ? (date) WHERE location_id IS NULL",
+			clause.Column{Name: HOLIDAY_WITHOUT_LOCATION_INDEX}, clause.Table{Name: statement.Schema.Table}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var defaultLocation model.Location
+	result := db.Order("id").Limit(1).Find(&defaultLocation, "is_default = ?", true)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		result = db.Model(&model.Holiday{}).Where("location_id IS NULL").Update("location_id", defaultLocation.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
 	return nil
 }
 
@@ -87,10 +153,11 @@ func (r Holiday) HolidayUpdate(item *model.Holiday) error {
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Updates(item)
+	result := db.Select("*").Omit("created_at").Updates(item)
 	return result.Error
 }
 
+// HolidayDelete removes the holiday permanently, the day is unique per location.
 func (r Holiday) HolidayDelete(item *model.Holiday) error {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -98,7 +165,7 @@ func (r Holiday) HolidayDelete(item *model.Holiday) error {
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Delete(item)
+	result := db.Unscoped().Delete(item)
 	return result.Error
 }
 
@@ -140,37 +207,86 @@ func (r Holiday) HolidayIsByDate(date time.Time) (bool, error) {
 	return true, result.Error
 }
 
-func (r Holiday) HolidayFindByYear(year int) (model.Holidays, error) {
+func (r Holiday) HolidayFindByDateRange(start time.Time, end time.Time) ([]model.Holiday, error) {
+	var items []model.Holiday
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
-		return nil, err
+		return items, err
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	var items model.Holidays
-	result := db.Find(&items, "extract(year from date) = ?", year)
+	result := db.Find(&items, "date between ? and ?", start, end)
 	if result.Error != nil {
-		return nil, result.Error
+		return items, result.Error
 	}
-
 	return items, result.Error
 }
 
-func (r Holiday) HolidayFindByDateRange(start time.Time, end time.Time) ([]model.Holiday, error) {
-	var items []model.Holiday
+// HolidayFindByUserIdAndDateRange returns the holidays of the locations the
+// user is assigned to on each day of the range.
+func (r Holiday) HolidayFindByUserIdAndDateRange(userId uint, start time.Time, end time.Time) (model.Holidays, error) {
+	items := model.Holidays{}
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
 		return items, err
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Find(&items, "date between ? and ?", start, end)
+	var userLocations model.UserLocations
+	result := db.Find(&userLocations, "user_id = ?", userId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+
+	var defaultLocation model.Location
+	result = db.Order("id").Limit(1).Find(&defaultLocation, "is_default = ?", true)
 	if result.Error != nil {
 		return items, result.Error
 	}
+
+	result = db.Find(&items, "date between ? and ?", start, end)
+	if result.Error != nil {
+		return items, result.Error
+	}
+
+	return items.ForUserLocations(userLocations, defaultLocation.ID), nil
+}
+
+func (r Holiday) HolidayFindByUserIdAndYear(userId uint, year int) (model.Holidays, error) {
+	firstOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lastOfYear := time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+	return r.HolidayFindByUserIdAndDateRange(userId, firstOfYear, lastOfYear)
+}
+
+func (r Holiday) HolidayFindByLocationIdAndYear(locationId uint, year int) (model.Holidays, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var items model.Holidays
+	firstOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result := db.Find(&items, "location_id = ? and date >= ? and date < ?", locationId, firstOfYear, firstOfYear.AddDate(1, 0, 0))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
 	return items, result.Error
 }
 
+func (r Holiday) HolidayDeleteByLocationId(locationId uint) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Where("location_id = ?", locationId).Delete(&model.Holiday{})
+	return result.Error
+}
+
 var ErrHolidayCustomNotFound = errors.New("HolidayCustom not found")
 
 func (r Holiday) HolidayCustomFindAll() ([]model.HolidayCustom, error) {
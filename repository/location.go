@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm/clause"
+)
+
+type LocationRepository interface {
+	LocationFindAll() ([]model.Location, error)
+	LocationFindById(id uint) (model.Location, error)
+	LocationFindDefault() (model.Location, error)
+	LocationInsert(item *model.Location) error
+	LocationUpdate(item *model.Location) error
+	LocationSetDefault(item *model.Location) error
+	LocationDelete(item *model.Location) error
+	LocationIsAssigned(locationId uint) (bool, error)
+	UserLocationFindById(id uint) (model.UserLocation, error)
+	UserLocationFindByUserId(userId uint) (model.UserLocations, error)
+	UserLocationInsert(item *model.UserLocation) error
+	UserLocationUpdate(item *model.UserLocation) error
+	UserLocationDelete(item *model.UserLocation) error
+}
+
+var _ LocationRepository = (*Location)(nil)
+
+type Location struct {
+	env *core.Environment
+}
+
+func NewLocation(env *core.Environment) *Location {
+	return &Location{
+		env: env,
+	}
+}
+
+func (r *Location) Migrate() error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	err = db.AutoMigrate(&model.Location{}, &model.LocationOfficeIPRange{}, &model.UserLocation{})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.LocationFindDefault()
+	if err != nil {
+		if err != ErrLocationNotFound {
+			return err
+		}
+
+		defaultLocation := model.DefaultLocation(r.env.Holiday.State)
+		err = r.LocationInsert(&defaultLocation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var ErrLocationNotFound = errors.New("Location not found")
+
+func (r Location) LocationFindAll() ([]model.Location, error) {
+	var items []model.Location
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload(clause.Associations).Order("name").Find(&items)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r Location) LocationFindById(id uint) (model.Location, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.Location{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.Location
+	result := db.Preload(clause.Associations).Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.Location{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.Location{}, ErrLocationNotFound
+	}
+	return item, result.Error
+}
+
+func (r Location) LocationFindDefault() (model.Location, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.Location{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.Location
+	result := db.Preload(clause.Associations).Order("id").Limit(1).Find(&item, "is_default = ?", true)
+	if result.Error != nil {
+		return model.Location{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.Location{}, ErrLocationNotFound
+	}
+	return item, result.Error
+}
+
+func (r Location) LocationInsert(item *model.Location) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+// LocationUpdate saves the location and replaces its office ip ranges.
+func (r Location) LocationUpdate(item *model.Location) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Where("location_id = ?", item.ID).Delete(&model.LocationOfficeIPRange{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = db.Select("*").Omit("created_at", clause.Associations).Updates(item)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if len(item.OfficeIPRanges) == 0 {
+		return nil
+	}
+
+	for i := range item.OfficeIPRanges {
+		item.OfficeIPRanges[i].ID = 0
+		item.OfficeIPRanges[i].LocationID = item.ID
+	}
+
+	result = db.Create(&item.OfficeIPRanges)
+	return result.Error
+}
+
+// LocationSetDefault marks the location as default and removes the mark from
+// all other locations.
+func (r Location) LocationSetDefault(item *model.Location) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Model(&model.Location{}).Where("id <> ?", item.ID).Update("is_default", false)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	item.IsDefault = true
+	result = db.Model(item).Update("is_default", true)
+	return result.Error
+}
+
+func (r Location) LocationDelete(item *model.Location) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Where("location_id = ?", item.ID).Delete(&model.LocationOfficeIPRange{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = db.Delete(item)
+	return result.Error
+}
+
+func (r Location) LocationIsAssigned(locationId uint) (bool, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return false, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var count int64
+	result := db.Model(&model.UserLocation{}).Where("location_id = ?", locationId).Count(&count)
+	return count > 0, result.Error
+}
+
+var ErrUserLocationNotFound = errors.New("UserLocation not found")
+
+func (r Location) UserLocationFindById(id uint) (model.UserLocation, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.UserLocation{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.UserLocation
+	result := db.Preload("Location").Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.UserLocation{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.UserLocation{}, ErrUserLocationNotFound
+	}
+	return item, result.Error
+}
+
+func (r Location) UserLocationFindByUserId(userId uint) (model.UserLocations, error) {
+	var items model.UserLocations
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload("Location.OfficeIPRanges").Order("valid_from").Find(&items, "user_id = ?", userId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r Location) UserLocationInsert(item *model.UserLocation) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Omit("Location").Create(item)
+	return result.Error
+}
+
+func (r Location) UserLocationUpdate(item *model.UserLocation) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Omit(clause.Associations).Updates(item)
+	return result.Error
+}
+
+func (r Location) UserLocationDelete(item *model.UserLocation) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Delete(item)
+	return result.Error
+}
@@ -0,0 +1,406 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.AbsenceRepository = (*Absence)(nil)
+
+type Absence struct {
+	db *Database
+}
+
+func NewAbsence(db *Database) *Absence {
+	return &Absence{
+		db: db,
+	}
+}
+
+// withAssociations sets the associations like Preload(clause.Associations) and
+// Preload("AbsenceReason.ApprovalSteps").
+func (r *Absence) withAssociations(item model.Absence) model.Absence {
+	item.User = optionalUser(r.db, item.UserID)
+	item.SignedUser = optionalUser(r.db, item.SignedUserID)
+	if item.AbsenceReasonID != nil {
+		item.AbsenceReason, _ = r.db.absenceReasons.get(*item.AbsenceReasonID)
+		item.AbsenceReason.ApprovalSteps = r.approvalSteps(item.AbsenceReason.ID)
+	}
+	item.ExternalEvents = r.db.absenceExternalEvents.find(func(event model.AbsenceExternalEvent) bool {
+		return event.AbsenceID == item.ID
+	})
+	item.Approvals = r.db.absenceApprovals.find(func(approval model.AbsenceApproval) bool {
+		return approval.AbsenceID == item.ID
+	})
+	item.ChangeRequests = r.db.absenceChangeRequests.find(func(changeRequest model.AbsenceChangeRequest) bool {
+		return changeRequest.AbsenceID == item.ID
+	})
+	return item
+}
+
+func (r *Absence) withAllAssociations(items []model.Absence) []model.Absence {
+	for i := range items {
+		items[i] = r.withAssociations(items[i])
+	}
+	return items
+}
+
+func (r *Absence) approvalSteps(absenceReasonId uint) []model.AbsenceApprovalStep {
+	return r.db.absenceApprovalSteps.find(func(step model.AbsenceApprovalStep) bool {
+		return step.AbsenceReasonID == absenceReasonId
+	})
+}
+
+func isAbsenceOfUsers(item model.Absence, userIds []uint) bool {
+	return item.UserID != nil && slices.Contains(userIds, *item.UserID)
+}
+
+func (r *Absence) FindAll(withRelations bool) ([]model.Absence, error) {
+	items := r.db.absences.find(nil)
+	if withRelations {
+		items = r.withAllAssociations(items)
+	}
+	return items, nil
+}
+
+func (r *Absence) FindByAbsenceTillBetween(from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.withAllAssociations(r.db.absences.find(func(item model.Absence) bool {
+		return isBetween(item.AbsenceTill, from, till)
+	})), nil
+}
+
+func (r *Absence) FindSince(since time.Time) ([]model.Absence, error) {
+	return r.withAllAssociations(r.db.absences.find(func(item model.Absence) bool {
+		return !item.AbsenceTill.Before(since)
+	})), nil
+}
+
+func (r *Absence) FindByUserIdsSince(userIds []uint, since time.Time) ([]model.Absence, error) {
+	return r.withAllAssociations(r.db.absences.find(func(item model.Absence) bool {
+		return isAbsenceOfUsers(item, userIds) && !item.AbsenceTill.Before(since)
+	})), nil
+}
+
+func (r *Absence) FindOverlapping(from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.db.absences.find(func(item model.Absence) bool {
+		return !item.AbsenceFrom.After(till) && !item.AbsenceTill.Before(from)
+	}), nil
+}
+
+func (r *Absence) FindByUserIdsOverlapping(userIds []uint, from time.Time, till time.Time) ([]model.Absence, error) {
+	return r.db.absences.find(func(item model.Absence) bool {
+		return isAbsenceOfUsers(item, userIds) && !item.AbsenceFrom.After(till) && !item.AbsenceTill.Before(from)
+	}), nil
+}
+
+func (r *Absence) FindUnsigned() ([]model.Absence, error) {
+	return r.withAllAssociations(r.db.absences.find(func(item model.Absence) bool {
+		return item.SignedUserID == nil
+	})), nil
+}
+
+func (r *Absence) FindUnsignedByUserIdsAndReasonIds(userIds []uint, reasonIds []uint) ([]model.Absence, error) {
+	return r.withAllAssociations(r.db.absences.find(func(item model.Absence) bool {
+		return isAbsenceOfUsers(item, userIds) && item.AbsenceReasonID != nil &&
+			slices.Contains(reasonIds, *item.AbsenceReasonID) && item.SignedUserID == nil
+	})), nil
+}
+
+func (r *Absence) FindByID(id uint) (model.Absence, error) {
+	item, ok := r.db.absences.get(id)
+	if !ok {
+		return model.Absence{}, repository.ErrAbsenceNotFound
+	}
+	return r.withAssociations(item), nil
+}
+
+func (r *Absence) FindByUserID(userID uint) ([]model.Absence, error) {
+	return r.findByUserID(userID), nil
+}
+
+func (r *Absence) findByUserID(userID uint) []model.Absence {
+	return r.db.absences.find(func(item model.Absence) bool {
+		return item.UserID != nil && *item.UserID == userID
+	})
+}
+
+func (r *Absence) FindVacationByUserID(userID uint) ([]model.Absence, error) {
+	items := []model.Absence{}
+	for _, item := range r.findByUserID(userID) {
+		if item.AbsenceReasonID == nil {
+			continue
+		}
+
+		reason, ok := r.db.absenceReasons.get(*item.AbsenceReasonID)
+		if ok && reason.DeductsVacation != nil && *reason.DeductsVacation {
+			item.AbsenceReason = reason
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *Absence) FindByUserIDAndYear(userID uint, year int) ([]model.Absence, error) {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, 12, 31, 23, 59, 0, 0, time.UTC)
+	return r.db.absences.find(func(item model.Absence) bool {
+		return item.UserID != nil && *item.UserID == userID && isBetween(item.AbsenceFrom, start, end)
+	}), nil
+}
+
+// Insert saves the absence with its approvals.
+func (r *Absence) Insert(tx *gorm.DB, absence *model.Absence) error {
+	if absence.AbsenceReason.ID != 0 {
+		absence.AbsenceReasonID = &absence.AbsenceReason.ID
+	}
+	if absence.DayPart == "" {
+		absence.DayPart = model.ABSENCE_DAY_PART_FULL
+	}
+
+	err := r.db.absences.insert(absence)
+	if err != nil {
+		return err
+	}
+
+	for i := range absence.Approvals {
+		absence.Approvals[i].AbsenceID = absence.ID
+		err := r.db.absenceApprovals.insert(&absence.Approvals[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update saves the absence without its associations.
+func (r *Absence) Update(tx *gorm.DB, absence *model.Absence) error {
+	return r.db.absences.update(absence)
+}
+
+func (r *Absence) AbsenceApprovalInsert(tx *gorm.DB, item *model.AbsenceApproval) error {
+	return r.db.absenceApprovals.insert(item)
+}
+
+func (r *Absence) Delete(tx *gorm.DB, absence *model.Absence) error {
+	return r.db.absences.softDelete(absence.ID)
+}
+
+func (r *Absence) FindAllAbsenceReasons() ([]model.AbsenceReason, error) {
+	items := r.db.absenceReasons.find(nil)
+	for i := range items {
+		items[i].ApprovalSteps = r.approvalSteps(items[i].ID)
+	}
+	return items, nil
+}
+
+// InsertAbsenceReason saves the reason with its approval steps.
+func (r *Absence) InsertAbsenceReason(absenceReason *model.AbsenceReason) error {
+	if absenceReason.OvertimeImpact == "" {
+		absenceReason.OvertimeImpact = model.ABESENCE_REASON_OVERTIME_IMPACT_NONE
+	}
+
+	err := r.db.absenceReasons.insert(absenceReason)
+	if err != nil {
+		return err
+	}
+
+	for i := range absenceReason.ApprovalSteps {
+		absenceReason.ApprovalSteps[i].AbsenceReasonID = absenceReason.ID
+		err := r.db.absenceApprovalSteps.insert(&absenceReason.ApprovalSteps[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Absence) FindAbsenceReasonByID(id uint) (model.AbsenceReason, error) {
+	item, ok := r.db.absenceReasons.get(id)
+	if !ok {
+		return model.AbsenceReason{}, fmt.Errorf("no absence with id %d found", id)
+	}
+
+	item.ApprovalSteps = r.approvalSteps(item.ID)
+	return item, nil
+}
+
+func (r *Absence) UpdateAbsenceReason(tx *gorm.DB, item *model.AbsenceReason) error {
+	return r.db.absenceReasons.update(item)
+}
+
+func (r *Absence) ReplaceAbsenceReasonApprovalSteps(tx *gorm.DB, item *model.AbsenceReason, steps []model.AbsenceApprovalStep) error {
+	r.db.absenceApprovalSteps.deleteWhere(func(step model.AbsenceApprovalStep) bool {
+		return step.AbsenceReasonID == item.ID
+	})
+
+	for i := range steps {
+		steps[i].AbsenceReasonID = item.ID
+		err := r.db.absenceApprovalSteps.insert(&steps[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Absence) DeleteAbsenceReason(item *model.AbsenceReason) error {
+	return r.db.absenceReasons.softDelete(item.ID)
+}
+
+func (r *Absence) FindYearsWithAbsencesByUserId(userID uint) ([]int, error) {
+	years := []int{}
+	for _, absence := range r.findByUserID(userID) {
+		if !slices.Contains(years, absence.AbsenceFrom.Year()) {
+			years = append(years, absence.AbsenceFrom.Year())
+		}
+	}
+	sort.Ints(years)
+	return years, nil
+}
+
+// withAbsence sets the absence of the event including cancelled absences, like
+// the preload of the events by provider.
+func (r *Absence) withAbsence(items []model.AbsenceExternalEvent) []model.AbsenceExternalEvent {
+	for i := range items {
+		absence, _ := r.db.absences.getUnscoped(items[i].AbsenceID)
+		absence.User = optionalUser(r.db, absence.UserID)
+		if absence.AbsenceReasonID != nil {
+			absence.AbsenceReason, _ = r.db.absenceReasons.get(*absence.AbsenceReasonID)
+		}
+		items[i].Absence = absence
+	}
+	return items
+}
+
+func (r *Absence) AbsenceExternalEventFindAll() ([]model.AbsenceExternalEvent, error) {
+	return r.db.absenceExternalEvents.find(nil), nil
+}
+
+func (r *Absence) AbsenceExternalEventFindById(id uint) (model.AbsenceExternalEvent, error) {
+	item, ok := r.db.absenceExternalEvents.get(id)
+	if !ok {
+		return model.AbsenceExternalEvent{}, repository.ErrAbsenceExternalEventNotFound
+	}
+	return item, nil
+}
+
+func (r *Absence) AbsenceExternalEventInsert(item *model.AbsenceExternalEvent) error {
+	return r.db.absenceExternalEvents.insert(item)
+}
+
+func (r *Absence) AbsenceExternalEventUpdate(item *model.AbsenceExternalEvent) error {
+	return r.db.absenceExternalEvents.update(item)
+}
+
+func (r *Absence) AbsenceExternalEventDelete(tx *gorm.DB, item *model.AbsenceExternalEvent) error {
+	return r.db.absenceExternalEvents.delete(item.ID)
+}
+
+func (r *Absence) AbsenceExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.AbsenceExternalEvent, error) {
+	return r.withAbsence(r.db.absenceExternalEvents.find(func(item model.AbsenceExternalEvent) bool {
+		return item.ExternalEventProvider == provider
+	})), nil
+}
+
+func (r *Absence) AbsenceExternalEventFindByProviderAndAbsenceId(provider model.ExternalEventProvider, absenceId uint) ([]model.AbsenceExternalEvent, error) {
+	return r.withAbsence(r.db.absenceExternalEvents.find(func(item model.AbsenceExternalEvent) bool {
+		return item.ExternalEventProvider == provider && item.AbsenceID == absenceId
+	})), nil
+}
+
+func (r *Absence) FindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.Absence, error) {
+	events := r.db.absenceExternalEvents.find(func(item model.AbsenceExternalEvent) bool {
+		return item.ExternalEventProvider == provider
+	})
+
+	return r.db.absences.find(func(item model.Absence) bool {
+		if item.AbsenceTill.Before(since) || (item.SignedStatus != nil && *item.SignedStatus == model.SIGNED_STATUS_DECLINED) {
+			return false
+		}
+		return !slices.ContainsFunc(events, func(event model.AbsenceExternalEvent) bool {
+			return event.AbsenceID == item.ID
+		})
+	}), nil
+}
+
+func (r *Absence) AbsenceExternalEventFindByAbsenceId(absenceId uint) ([]model.AbsenceExternalEvent, error) {
+	return r.db.absenceExternalEvents.find(func(item model.AbsenceExternalEvent) bool {
+		return item.AbsenceID == absenceId
+	}), nil
+}
+
+func (r *Absence) AbsenceFindByUserIDAndBetweenDates(userID uint, start time.Time, end time.Time) ([]model.Absence, error) {
+	items := r.db.absences.find(func(item model.Absence) bool {
+		return item.UserID != nil && *item.UserID == userID &&
+			(isBetween(item.AbsenceFrom, start, end) || isBetween(item.AbsenceTill, start, end))
+	})
+	for i := range items {
+		items[i] = r.withAssociations(items[i])
+		items[i].AbsenceReason.ApprovalSteps = nil
+	}
+	return items, nil
+}
+
+// withChangeRequestAssociations sets the associations like
+// preloadAbsenceChangeRequest.
+func (r *Absence) withChangeRequestAssociations(items []model.AbsenceChangeRequest) []model.AbsenceChangeRequest {
+	for i := range items {
+		absence, ok := r.db.absences.getUnscoped(items[i].AbsenceID)
+		if ok {
+			if absence.AbsenceReasonID != nil {
+				absence.AbsenceReason, _ = r.db.absenceReasons.get(*absence.AbsenceReasonID)
+			}
+			items[i].Absence = &absence
+		}
+		items[i].User = optionalUser(r.db, &items[i].UserID)
+	}
+	return items
+}
+
+func (r *Absence) AbsenceChangeRequestFindByUserId(userId uint) ([]model.AbsenceChangeRequest, error) {
+	items := r.db.absenceChangeRequests.find(func(item model.AbsenceChangeRequest) bool {
+		return item.UserID == userId
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return r.withChangeRequestAssociations(items), nil
+}
+
+func (r *Absence) AbsenceChangeRequestFindPendingByUserIds(userIds []uint) ([]model.AbsenceChangeRequest, error) {
+	return r.withChangeRequestAssociations(r.db.absenceChangeRequests.find(func(item model.AbsenceChangeRequest) bool {
+		return slices.Contains(userIds, item.UserID) && item.Status == model.ABSENCE_CHANGE_REQUEST_STATUS_PENDING
+	})), nil
+}
+
+func (r *Absence) AbsenceChangeRequestFindPendingByAbsenceId(absenceId uint) ([]model.AbsenceChangeRequest, error) {
+	return r.db.absenceChangeRequests.find(func(item model.AbsenceChangeRequest) bool {
+		return item.AbsenceID == absenceId && item.Status == model.ABSENCE_CHANGE_REQUEST_STATUS_PENDING
+	}), nil
+}
+
+func (r *Absence) AbsenceChangeRequestFindById(id uint) (model.AbsenceChangeRequest, error) {
+	item, ok := r.db.absenceChangeRequests.get(id)
+	if !ok {
+		return model.AbsenceChangeRequest{}, repository.ErrAbsenceChangeRequestNotFound
+	}
+	return r.withChangeRequestAssociations([]model.AbsenceChangeRequest{item})[0], nil
+}
+
+func (r *Absence) AbsenceChangeRequestInsert(item *model.AbsenceChangeRequest) error {
+	if item.Status == "" {
+		item.Status = model.ABSENCE_CHANGE_REQUEST_STATUS_PENDING
+	}
+	return r.db.absenceChangeRequests.insert(item)
+}
+
+func (r *Absence) AbsenceChangeRequestUpdate(tx *gorm.DB, item *model.AbsenceChangeRequest) error {
+	return r.db.absenceChangeRequests.update(item)
+}
@@ -0,0 +1,440 @@
+// Package memory implements the repository interfaces without a database so
+// handlers and workers can be tested quickly.
+package memory
+
+import (
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
+)
+
+// Database holds the rows of all repositories sharing it. The rows are stored
+// without their associations, every repository sets the associations its
+// database counterpart preloads.
+type Database struct {
+	// txMu runs the transactions one after another.
+	txMu sync.Mutex
+
+	users                      *table[model.User]
+	userApikeys                *table[model.UserApikey]
+	userCalendarFeeds          *table[model.UserCalendarFeed]
+	teams                      *table[model.Team]
+	teamMembers                *table[model.TeamMember]
+	teamBlackoutPeriods        *table[model.TeamBlackoutPeriod]
+	timestamps                 *table[model.Timestamp]
+	timestampCorrections       *table[model.TimestampCorrection]
+	timestampBreaks            *table[model.TimestampBreak]
+	absences                   *table[model.Absence]
+	absenceReasons             *table[model.AbsenceReason]
+	absenceApprovalSteps       *table[model.AbsenceApprovalStep]
+	absenceApprovals           *table[model.AbsenceApproval]
+	absenceChangeRequests      *table[model.AbsenceChangeRequest]
+	absenceExternalEvents      *table[model.AbsenceExternalEvent]
+	externalWorks              *table[model.ExternalWork]
+	externalWorkExpenses       *table[model.ExternalWorkExpense]
+	externalWorkExternalEvents *table[model.ExternalWorkExternalEvent]
+	externalWorkCompensations  *table[model.ExternalWorkCompensation]
+	fuels                      *table[model.Fuel]
+	holidays                   *table[model.Holiday]
+	holidayCustoms             *table[model.HolidayCustom]
+	locations                  *table[model.Location]
+	locationOfficeIPRanges     *table[model.LocationOfficeIPRange]
+	userLocations              *table[model.UserLocation]
+	migrations                 *table[model.Migration]
+	monthClosings              *table[model.MonthClosing]
+	outboxJobs                 *table[model.OutboxJob]
+	overtimeMonthQuotas        *table[model.OvertimeMonthQuota]
+	settings                   *table[model.Settings]
+	settingsOfficeIPAddresses  *table[model.SettingsOfficeIPAddresses]
+	settingsBreakRules         *table[model.SettingsBreakRule]
+	vacationAdjustments        *table[model.VacationAdjustment]
+	workTimeModels             *table[model.WorkTimeModel]
+	userWorkTimeModels         *table[model.UserWorkTimeModel]
+}
+
+func NewDatabase() *Database {
+	return &Database{
+		users: newTable(func(item *model.User) *gorm.Model { return &item.Model }, nil),
+		userApikeys: newTable(func(item *model.UserApikey) *gorm.Model { return &item.Model }, func(item *model.UserApikey) {
+			item.User = model.User{}
+		}),
+		userCalendarFeeds: newTable(func(item *model.UserCalendarFeed) *gorm.Model { return &item.Model }, func(item *model.UserCalendarFeed) {
+			item.User = model.User{}
+		}),
+		teams: newTable(func(item *model.Team) *gorm.Model { return &item.Model }, func(item *model.Team) {
+			item.Members = nil
+			item.BlackoutPeriods = nil
+		}),
+		teamMembers: newTable(func(item *model.TeamMember) *gorm.Model { return &item.Model }, func(item *model.TeamMember) {
+			item.Team = model.Team{}
+			item.User = model.User{}
+		}),
+		teamBlackoutPeriods: newTable(func(item *model.TeamBlackoutPeriod) *gorm.Model { return &item.Model }, func(item *model.TeamBlackoutPeriod) {
+			item.Team = nil
+		}),
+		timestamps: newTable(func(item *model.Timestamp) *gorm.Model { return &item.Model }, func(item *model.Timestamp) {
+			item.User = nil
+			item.Corrections = nil
+			item.Breaks = nil
+		}),
+		timestampCorrections: newTable(func(item *model.TimestampCorrection) *gorm.Model { return &item.Model }, func(item *model.TimestampCorrection) {
+			item.Timestamp = model.Timestamp{}
+			item.SignedUser = nil
+		}),
+		timestampBreaks: newTable(func(item *model.TimestampBreak) *gorm.Model { return &item.Model }, func(item *model.TimestampBreak) {
+			item.Timestamp = nil
+		}),
+		absences: newTable(func(item *model.Absence) *gorm.Model { return &item.Model }, func(item *model.Absence) {
+			item.User = nil
+			item.AbsenceReason = model.AbsenceReason{}
+			item.SignedUser = nil
+			item.ExternalEvents = nil
+			item.Approvals = nil
+			item.ChangeRequests = nil
+		}),
+		absenceReasons: newTable(func(item *model.AbsenceReason) *gorm.Model { return &item.Model }, func(item *model.AbsenceReason) {
+			item.ApprovalSteps = nil
+		}),
+		absenceApprovalSteps: newTable(func(item *model.AbsenceApprovalStep) *gorm.Model { return &item.Model }, func(item *model.AbsenceApprovalStep) {
+			item.AbsenceReason = nil
+			item.ApproverUser = nil
+		}),
+		absenceApprovals: newTable(func(item *model.AbsenceApproval) *gorm.Model { return &item.Model }, func(item *model.AbsenceApproval) {
+			item.Absence = nil
+			item.SignedUser = nil
+		}),
+		absenceChangeRequests: newTable(func(item *model.AbsenceChangeRequest) *gorm.Model { return &item.Model }, func(item *model.AbsenceChangeRequest) {
+			item.Absence = nil
+			item.User = nil
+			item.SignedUser = nil
+		}),
+		absenceExternalEvents: newTable(func(item *model.AbsenceExternalEvent) *gorm.Model { return &item.Model }, func(item *model.AbsenceExternalEvent) {
+			item.Absence = model.Absence{}
+		}),
+		externalWorks: newTable(func(item *model.ExternalWork) *gorm.Model { return &item.Model }, func(item *model.ExternalWork) {
+			item.User = model.User{}
+			item.WorkExpanses = nil
+			item.ExternalWorkCompensation = model.ExternalWorkCompensation{}
+			item.ReviewedBy = nil
+		}),
+		externalWorkExpenses: newTable(func(item *model.ExternalWorkExpense) *gorm.Model { return &item.Model }, func(item *model.ExternalWorkExpense) {
+			item.ExternalWork = model.ExternalWork{}
+		}),
+		externalWorkExternalEvents: newTable(func(item *model.ExternalWorkExternalEvent) *gorm.Model { return &item.Model }, func(item *model.ExternalWorkExternalEvent) {
+			item.ExternalWork = model.ExternalWork{}
+		}),
+		externalWorkCompensations: newTable(func(item *model.ExternalWorkCompensation) *gorm.Model { return &item.Model }, nil),
+		fuels: newTable(func(item *model.Fuel) *gorm.Model { return &item.Model }, func(item *model.Fuel) {
+			item.User = nil
+		}),
+		holidays:       newTable(func(item *model.Holiday) *gorm.Model { return &item.Model }, nil),
+		holidayCustoms: newTable(func(item *model.HolidayCustom) *gorm.Model { return &item.Model }, nil),
+		locations: newTable(func(item *model.Location) *gorm.Model { return &item.Model }, func(item *model.Location) {
+			item.OfficeIPRanges = nil
+		}),
+		locationOfficeIPRanges: newTable(func(item *model.LocationOfficeIPRange) *gorm.Model { return &item.Model }, nil),
+		userLocations: newTable(func(item *model.UserLocation) *gorm.Model { return &item.Model }, func(item *model.UserLocation) {
+			item.User = nil
+			item.Location = model.Location{}
+		}),
+		migrations: newTable(func(item *model.Migration) *gorm.Model { return &item.Model }, nil),
+		monthClosings: newTable(func(item *model.MonthClosing) *gorm.Model { return &item.Model }, func(item *model.MonthClosing) {
+			item.User = nil
+			item.ClosedByUser = nil
+			item.ReopenedByUser = nil
+		}),
+		outboxJobs: newTable(func(item *model.OutboxJob) *gorm.Model { return &item.Model }, nil),
+		overtimeMonthQuotas: newTable(func(item *model.OvertimeMonthQuota) *gorm.Model { return &item.Model }, func(item *model.OvertimeMonthQuota) {
+			item.User = model.User{}
+		}),
+		settings: newTable(func(item *model.Settings) *gorm.Model { return &item.Model }, func(item *model.Settings) {
+			item.OfficeIPAddresses = nil
+			item.BreakRules = nil
+		}),
+		settingsOfficeIPAddresses: newTable(func(item *model.SettingsOfficeIPAddresses) *gorm.Model { return &item.Model }, func(item *model.SettingsOfficeIPAddresses) {
+			item.Settings = model.Settings{}
+		}),
+		settingsBreakRules: newTable(func(item *model.SettingsBreakRule) *gorm.Model { return &item.Model }, func(item *model.SettingsBreakRule) {
+			item.Team = nil
+		}),
+		vacationAdjustments: newTable(func(item *model.VacationAdjustment) *gorm.Model { return &item.Model }, func(item *model.VacationAdjustment) {
+			item.User = nil
+			item.CreatedByUser = nil
+		}),
+		workTimeModels: newTable(func(item *model.WorkTimeModel) *gorm.Model { return &item.Model }, nil),
+		userWorkTimeModels: newTable(func(item *model.UserWorkTimeModel) *gorm.Model { return &item.Model }, func(item *model.UserWorkTimeModel) {
+			item.User = nil
+			item.WorkTimeModel = model.WorkTimeModel{}
+		}),
+	}
+}
+
+// transaction runs fn and restores all tables if it fails. Writes outside of
+// fn are not isolated from it.
+func (d *Database) transaction(fn func() error) error {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	var restores []func()
+	for _, t := range d.tables() {
+		restores = append(restores, t.snapshot())
+	}
+
+	err := fn()
+	if err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+	return err
+}
+
+type snapshotter interface {
+	snapshot() func()
+}
+
+func (d *Database) tables() []snapshotter {
+	return []snapshotter{
+		d.users,
+		d.userApikeys,
+		d.userCalendarFeeds,
+		d.teams,
+		d.teamMembers,
+		d.teamBlackoutPeriods,
+		d.timestamps,
+		d.timestampCorrections,
+		d.timestampBreaks,
+		d.absences,
+		d.absenceReasons,
+		d.absenceApprovalSteps,
+		d.absenceApprovals,
+		d.absenceChangeRequests,
+		d.absenceExternalEvents,
+		d.externalWorks,
+		d.externalWorkExpenses,
+		d.externalWorkExternalEvents,
+		d.externalWorkCompensations,
+		d.fuels,
+		d.holidays,
+		d.holidayCustoms,
+		d.locations,
+		d.locationOfficeIPRanges,
+		d.userLocations,
+		d.migrations,
+		d.monthClosings,
+		d.outboxJobs,
+		d.overtimeMonthQuotas,
+		d.settings,
+		d.settingsOfficeIPAddresses,
+		d.settingsBreakRules,
+		d.vacationAdjustments,
+		d.workTimeModels,
+		d.userWorkTimeModels,
+	}
+}
+
+// table stores the rows of one model by id.
+type table[T any] struct {
+	mu     sync.Mutex
+	lastID uint
+	rows   map[uint]T
+	// modelOf returns the embedded gorm.Model of the row.
+	modelOf func(item *T) *gorm.Model
+	// clearAssociations resets the association fields before a row is stored.
+	clearAssociations func(item *T)
+}
+
+func newTable[T any](modelOf func(item *T) *gorm.Model, clearAssociations func(item *T)) *table[T] {
+	return &table[T]{
+		rows:              map[uint]T{},
+		modelOf:           modelOf,
+		clearAssociations: clearAssociations,
+	}
+}
+
+// insert stores the row and sets its id and timestamps.
+func (t *table[T]) insert(item *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m := t.modelOf(item)
+	if _, ok := t.rows[m.ID]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	if m.ID == 0 {
+		t.lastID++
+		m.ID = t.lastID
+	} else if m.ID > t.lastID {
+		t.lastID = m.ID
+	}
+
+	now := time.Now()
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.UpdatedAt = now
+
+	t.store(*item)
+	return nil
+}
+
+// update replaces the stored row, unknown and deleted rows are ignored.
+func (t *table[T]) update(item *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m := t.modelOf(item)
+	if m.ID == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+
+	stored, ok := t.rows[m.ID]
+	if !ok || t.modelOf(&stored).DeletedAt.Valid {
+		return nil
+	}
+
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = t.modelOf(&stored).CreatedAt
+	}
+	m.UpdatedAt = time.Now()
+
+	t.store(*item)
+	return nil
+}
+
+func (t *table[T]) store(item T) {
+	if t.clearAssociations != nil {
+		t.clearAssociations(&item)
+	}
+	t.rows[t.modelOf(&item).ID] = item
+}
+
+// delete removes the row permanently.
+func (t *table[T]) delete(id uint) error {
+	if id == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.rows, id)
+	return nil
+}
+
+// softDelete sets the DeletedAt of the row, it is only returned unscoped.
+func (t *table[T]) softDelete(id uint) error {
+	if id == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+	t.modelOf(&item).DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	t.rows[id] = item
+	return nil
+}
+
+// deleteWhere removes all rows matching the filter permanently.
+func (t *table[T]) deleteWhere(filter func(item T) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, item := range t.rows {
+		if filter(item) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+// get returns the not deleted row with the id.
+func (t *table[T]) get(id uint) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.rows[id]
+	if !ok || t.modelOf(&item).DeletedAt.Valid {
+		var empty T
+		return empty, false
+	}
+	return item, true
+}
+
+// getUnscoped returns the row with the id even if it is deleted.
+func (t *table[T]) getUnscoped(id uint) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.rows[id]
+	return item, ok
+}
+
+// find returns the not deleted rows matching the filter ordered by id, a nil
+// filter matches all rows.
+func (t *table[T]) find(filter func(item T) bool) []T {
+	return t.findRows(false, filter)
+}
+
+// findUnscoped is find including the deleted rows.
+func (t *table[T]) findUnscoped(filter func(item T) bool) []T {
+	return t.findRows(true, filter)
+}
+
+func (t *table[T]) findRows(unscoped bool, filter func(item T) bool) []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	items := []T{}
+	for _, item := range t.rows {
+		if !unscoped && t.modelOf(&item).DeletedAt.Valid {
+			continue
+		}
+		if filter == nil || filter(item) {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return t.modelOf(&items[i]).ID < t.modelOf(&items[j]).ID
+	})
+	return items
+}
+
+// snapshot copies the rows, the returned func restores them.
+func (t *table[T]) snapshot() func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lastID := t.lastID
+	rows := maps.Clone(t.rows)
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.lastID = lastID
+		t.rows = rows
+	}
+}
+
+// first returns the first row matching the filter.
+func (t *table[T]) first(filter func(item T) bool) (T, bool) {
+	items := t.find(filter)
+	if len(items) == 0 {
+		var empty T
+		return empty, false
+	}
+	return items[0], true
+}
+
+func isBetween(date time.Time, start time.Time, end time.Time) bool {
+	return !date.Before(start) && !date.After(end)
+}
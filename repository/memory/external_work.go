@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ repository.ExternalWorkRepository = (*ExternalWork)(nil)
+
+type ExternalWork struct {
+	db *Database
+}
+
+func NewExternalWork(db *Database) *ExternalWork {
+	return &ExternalWork{
+		db: db,
+	}
+}
+
+// withAssociations sets the associations like Preload(clause.Associations).
+func (r *ExternalWork) withAssociations(item model.ExternalWork) model.ExternalWork {
+	item.User, _ = r.db.users.get(item.UserID)
+	item.WorkExpanses = r.db.externalWorkExpenses.find(func(expense model.ExternalWorkExpense) bool {
+		return expense.ExternalWorkID == item.ID
+	})
+	item.ExternalWorkCompensation, _ = r.db.externalWorkCompensations.get(item.ExternalWorkCompensationID)
+	item.ReviewedBy = optionalUser(r.db, item.ReviewedByID)
+	return item
+}
+
+func (r *ExternalWork) withAllAssociations(items []model.ExternalWork) []model.ExternalWork {
+	for i := range items {
+		items[i] = r.withAssociations(items[i])
+	}
+	return items
+}
+
+func (r *ExternalWork) ExternalWorkFindAll() ([]model.ExternalWork, error) {
+	return r.db.externalWorks.find(nil), nil
+}
+
+func (r *ExternalWork) ExternalWorkFindById(id uint, with_associations bool) (model.ExternalWork, error) {
+	item, ok := r.db.externalWorks.get(id)
+	if !ok {
+		return model.ExternalWork{}, repository.ErrExternalWorkNotFound
+	}
+
+	if with_associations {
+		item = r.withAssociations(item)
+	}
+	return item, nil
+}
+
+func (r *ExternalWork) ExternalWorkFindByUserID(userId uint) ([]model.ExternalWork, error) {
+	return r.db.externalWorks.find(func(item model.ExternalWork) bool {
+		return item.UserID == userId
+	}), nil
+}
+
+func (r *ExternalWork) ExternalWorkFindByUserIDAndInvoiceIdentifier(userId uint, invoiceIdentifier uuid.UUID) ([]model.ExternalWork, error) {
+	return r.db.externalWorks.find(func(item model.ExternalWork) bool {
+		return item.UserID == userId && item.InvoiceIdentifier != nil && *item.InvoiceIdentifier == invoiceIdentifier
+	}), nil
+}
+
+// ExternalWorkInsert saves the external work with its expenses.
+func (r *ExternalWork) ExternalWorkInsert(tx *gorm.DB, item *model.ExternalWork) error {
+	if item.User.ID != 0 {
+		item.UserID = item.User.ID
+	}
+	if item.Status == "" {
+		item.Status = model.EXTERNAL_WORK_STATUS_PLANNED
+	}
+
+	err := r.db.externalWorks.insert(item)
+	if err != nil {
+		return err
+	}
+
+	for i := range item.WorkExpanses {
+		item.WorkExpanses[i].ExternalWorkID = item.ID
+		err := r.db.externalWorkExpenses.insert(&item.WorkExpanses[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ExternalWork) ExternalWorkUpdate(item *model.ExternalWork) error {
+	return r.db.externalWorks.update(item)
+}
+
+func (r *ExternalWork) ExternalWorkDelete(tx *gorm.DB, item *model.ExternalWork) error {
+	return r.db.externalWorks.softDelete(item.ID)
+}
+
+func (r *ExternalWork) ExternalWorkExpenseFindAll() ([]model.ExternalWorkExpense, error) {
+	return r.db.externalWorkExpenses.find(nil), nil
+}
+
+func (r *ExternalWork) ExternalWorkExpenseFindById(id uint) (model.ExternalWorkExpense, error) {
+	item, ok := r.db.externalWorkExpenses.get(id)
+	if !ok {
+		return model.ExternalWorkExpense{}, repository.ErrExternalWorkExpenseNotFound
+	}
+	return item, nil
+}
+
+func (r *ExternalWork) ExternalWorkExpenseFindByExternalWorkId(externalWorkId uint) ([]model.ExternalWorkExpense, error) {
+	return r.db.externalWorkExpenses.find(func(item model.ExternalWorkExpense) bool {
+		return item.ExternalWorkID == externalWorkId
+	}), nil
+}
+
+func (r *ExternalWork) ExternalWorkExpenseInsert(item *model.ExternalWorkExpense) error {
+	if item.ExternalWork.ID != 0 {
+		item.ExternalWorkID = item.ExternalWork.ID
+	}
+	return r.db.externalWorkExpenses.insert(item)
+}
+
+// ExternalWorkExpenseUpdate only changes the fields editable by the user.
+func (r *ExternalWork) ExternalWorkExpenseUpdate(item *model.ExternalWorkExpense) error {
+	stored, ok := r.db.externalWorkExpenses.get(item.ID)
+	if !ok {
+		return nil
+	}
+
+	stored.DepartureTime = item.DepartureTime
+	stored.ArrivalTime = item.ArrivalTime
+	stored.TravelDurationHours = item.TravelDurationHours
+	stored.PauseDurationHours = item.PauseDurationHours
+	stored.OnSiteFrom = item.OnSiteFrom
+	stored.OnSiteTill = item.OnSiteTill
+	stored.Place = item.Place
+	stored.AdditionalOptions = item.AdditionalOptions
+	return r.db.externalWorkExpenses.update(&stored)
+}
+
+func (r *ExternalWork) ExternalWorkExpenseDelete(tx *gorm.DB, item *model.ExternalWorkExpense) error {
+	return r.db.externalWorkExpenses.softDelete(item.ID)
+}
+
+// withExternalWork sets the external work of the events including deleted
+// ones, like the preload of the events by provider.
+func (r *ExternalWork) withExternalWork(items []model.ExternalWorkExternalEvent) []model.ExternalWorkExternalEvent {
+	for i := range items {
+		externalWork, _ := r.db.externalWorks.getUnscoped(items[i].ExternalWorkID)
+		externalWork.User, _ = r.db.users.get(externalWork.UserID)
+		items[i].ExternalWork = externalWork
+	}
+	return items
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventFindAll() ([]model.ExternalWorkExternalEvent, error) {
+	return r.db.externalWorkExternalEvents.find(nil), nil
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventFindById(id uint) (model.ExternalWorkExternalEvent, error) {
+	item, ok := r.db.externalWorkExternalEvents.get(id)
+	if !ok {
+		return model.ExternalWorkExternalEvent{}, repository.ErrExternalWorkExternalEventNotFound
+	}
+	return item, nil
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventInsert(item *model.ExternalWorkExternalEvent) error {
+	return r.db.externalWorkExternalEvents.insert(item)
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventUpdate(item *model.ExternalWorkExternalEvent) error {
+	return r.db.externalWorkExternalEvents.update(item)
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventDelete(item *model.ExternalWorkExternalEvent) error {
+	return r.db.externalWorkExternalEvents.softDelete(item.ID)
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventFindByProvider(provider model.ExternalEventProvider) ([]model.ExternalWorkExternalEvent, error) {
+	return r.withExternalWork(r.db.externalWorkExternalEvents.find(func(item model.ExternalWorkExternalEvent) bool {
+		return item.ExternalEventProvider == provider
+	})), nil
+}
+
+func (r *ExternalWork) ExternalWorkExternalEventFindByProviderAndExternalWorkId(provider model.ExternalEventProvider, externalWorkId uint) ([]model.ExternalWorkExternalEvent, error) {
+	return r.withExternalWork(r.db.externalWorkExternalEvents.find(func(item model.ExternalWorkExternalEvent) bool {
+		return item.ExternalEventProvider == provider && item.ExternalWorkID == externalWorkId
+	})), nil
+}
+
+func (r *ExternalWork) ExternalWorkFindWithoutExternalEvent(provider model.ExternalEventProvider, since time.Time) ([]model.ExternalWork, error) {
+	events := r.db.externalWorkExternalEvents.find(func(item model.ExternalWorkExternalEvent) bool {
+		return item.ExternalEventProvider == provider
+	})
+
+	return r.db.externalWorks.find(func(item model.ExternalWork) bool {
+		return !item.Till.Before(since) && !slices.ContainsFunc(events, func(event model.ExternalWorkExternalEvent) bool {
+			return event.ExternalWorkID == item.ID
+		})
+	}), nil
+}
+
+func (r *ExternalWork) ExternalWorkFindByUserIDAndEndBetween(userId uint, start time.Time, end time.Time) ([]model.ExternalWork, error) {
+	return r.withAllAssociations(r.db.externalWorks.find(func(item model.ExternalWork) bool {
+		return item.UserID == userId && isBetween(item.Till, start, end)
+	})), nil
+}
+
+func (r *ExternalWork) ExternalWorkFindByUserIDAndStatus(userId uint, status model.ExternalWorkStatus) ([]model.ExternalWork, error) {
+	return r.withAllAssociations(r.db.externalWorks.find(func(item model.ExternalWork) bool {
+		return item.UserID == userId && item.Status == status
+	})), nil
+}
+
+func (r *ExternalWork) ExternalWorkCompensationFindAll() ([]model.ExternalWorkCompensation, error) {
+	return r.db.externalWorkCompensations.find(nil), nil
+}
+
+func (r *ExternalWork) ExternalWorkCompensationFindById(id uint) (model.ExternalWorkCompensation, error) {
+	item, ok := r.db.externalWorkCompensations.get(id)
+	if !ok {
+		return model.ExternalWorkCompensation{}, repository.ErrExternalWorkCompensationNotFound
+	}
+	return item, nil
+}
+
+func (r *ExternalWork) ExternalWorkCompensationInsert(item *model.ExternalWorkCompensation) error {
+	return r.db.externalWorkCompensations.insert(item)
+}
+
+func (r *ExternalWork) ExternalWorkCompensationUpdate(item *model.ExternalWorkCompensation) error {
+	return r.db.externalWorkCompensations.update(item)
+}
+
+func (r *ExternalWork) ExternalWorkCompensationDelete(item *model.ExternalWorkCompensation) error {
+	return r.db.externalWorkCompensations.softDelete(item.ID)
+}
+
+func (r *ExternalWork) ExternalWorkCompensationFindByCountryCode(countryCode string) (model.ExternalWorkCompensation, error) {
+	item, ok := r.db.externalWorkCompensations.first(func(item model.ExternalWorkCompensation) bool {
+		return item.IsoCountryCodeA2 == countryCode
+	})
+	if !ok {
+		return model.ExternalWorkCompensation{}, repository.ErrExternalWorkCompensationNotFound
+	}
+	return item, nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.FuelRepository = (*Fuel)(nil)
+
+type Fuel struct {
+	db *Database
+}
+
+func NewFuel(db *Database) *Fuel {
+	return &Fuel{
+		db: db,
+	}
+}
+
+func (r *Fuel) FindAll() ([]model.Fuel, error) {
+	return r.db.fuels.find(nil), nil
+}
+
+func (r *Fuel) FindByID(id uint) (model.Fuel, error) {
+	item, ok := r.db.fuels.get(id)
+	if !ok {
+		return model.Fuel{}, fmt.Errorf("no fuel with id %d found", id)
+	}
+	return item, nil
+}
+
+func (r *Fuel) FindByUserID(userID uint) ([]model.Fuel, error) {
+	return r.db.fuels.find(func(item model.Fuel) bool {
+		return item.UserID != nil && *item.UserID == userID
+	}), nil
+}
+
+func (r *Fuel) FindByUserIDAndState(userID uint, state model.FuelState) ([]model.Fuel, error) {
+	return r.db.fuels.find(func(item model.Fuel) bool {
+		return item.UserID != nil && *item.UserID == userID && item.State == state
+	}), nil
+}
+
+func (r *Fuel) FindLastByUserID(userID uint) (model.Fuel, error) {
+	items, err := r.FindByUserID(userID)
+	if err != nil {
+		return model.Fuel{}, err
+	}
+	if len(items) == 0 {
+		return model.Fuel{}, gorm.ErrRecordNotFound
+	}
+	return items[len(items)-1], nil
+}
+
+func (r *Fuel) FindByUserIDAndDate(userID uint, from, till time.Time) ([]model.Fuel, error) {
+	return r.db.fuels.find(func(item model.Fuel) bool {
+		return item.UserID != nil && *item.UserID == userID && isBetween(item.ReceiptDate, from, till)
+	}), nil
+}
+
+func (r *Fuel) CountByUserID(userID uint) (int64, error) {
+	items, err := r.FindByUserID(userID)
+	return int64(len(items)), err
+}
+
+func (r *Fuel) Insert(fuel *model.Fuel) error {
+	if fuel.User != nil {
+		fuel.UserID = &fuel.User.ID
+	}
+	return r.db.fuels.insert(fuel)
+}
+
+func (r *Fuel) Update(fuel *model.Fuel) error {
+	return r.db.fuels.update(fuel)
+}
+
+func (r *Fuel) Delete(fuel *model.Fuel) error {
+	return r.db.fuels.delete(fuel.ID)
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.HolidayRepository = (*Holiday)(nil)
+
+type Holiday struct {
+	db *Database
+}
+
+func NewHoliday(db *Database) *Holiday {
+	return &Holiday{
+		db: db,
+	}
+}
+
+func (r *Holiday) HolidayFindAll() ([]model.Holiday, error) {
+	return r.db.holidays.find(nil), nil
+}
+
+func (r *Holiday) HolidayFindById(id uint) (model.Holiday, error) {
+	item, ok := r.db.holidays.get(id)
+	if !ok {
+		return model.Holiday{}, repository.ErrHolidayNotFound
+	}
+	return item, nil
+}
+
+func (r *Holiday) HolidayInsert(item *model.Holiday) error {
+	if item.Source == "" {
+		item.Source = model.HOLIDAY_SOURCE_IMPORTED
+	}
+	return r.db.holidays.insert(item)
+}
+
+func (r *Holiday) HolidayUpdate(item *model.Holiday) error {
+	return r.db.holidays.update(item)
+}
+
+func (r *Holiday) HolidayDelete(item *model.Holiday) error {
+	return r.db.holidays.delete(item.ID)
+}
+
+func (r *Holiday) HolidayFindByDate(date time.Time) (model.Holiday, error) {
+	item, ok := r.db.holidays.first(func(item model.Holiday) bool {
+		return item.Date.Equal(date)
+	})
+	if !ok {
+		return model.Holiday{}, repository.ErrHolidayNotFound
+	}
+	return item, nil
+}
+
+func (r *Holiday) HolidayIsByDate(date time.Time) (bool, error) {
+	_, ok := r.db.holidays.first(func(item model.Holiday) bool {
+		return item.Date.Equal(date)
+	})
+	return ok, nil
+}
+
+func (r *Holiday) HolidayFindByDateRange(start time.Time, end time.Time) ([]model.Holiday, error) {
+	return r.db.holidays.find(func(item model.Holiday) bool {
+		return isBetween(item.Date, start, end)
+	}), nil
+}
+
+func (r *Holiday) HolidayFindByUserIdAndDateRange(userId uint, start time.Time, end time.Time) (model.Holidays, error) {
+	userLocations := r.db.userLocations.find(func(item model.UserLocation) bool {
+		return item.UserID == userId
+	})
+
+	var defaultLocationId uint
+	defaultLocation, ok := r.db.locations.first(func(item model.Location) bool {
+		return item.IsDefault
+	})
+	if ok {
+		defaultLocationId = defaultLocation.ID
+	}
+
+	items, err := r.HolidayFindByDateRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return model.Holidays(items).ForUserLocations(userLocations, defaultLocationId), nil
+}
+
+func (r *Holiday) HolidayFindByUserIdAndYear(userId uint, year int) (model.Holidays, error) {
+	firstOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lastOfYear := time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC)
+	return r.HolidayFindByUserIdAndDateRange(userId, firstOfYear, lastOfYear)
+}
+
+func (r *Holiday) HolidayFindByLocationIdAndYear(locationId uint, year int) (model.Holidays, error) {
+	return r.db.holidays.find(func(item model.Holiday) bool {
+		return item.LocationID != nil && *item.LocationID == locationId && item.Date.Year() == year
+	}), nil
+}
+
+func (r *Holiday) HolidayDeleteByLocationId(locationId uint) error {
+	r.db.holidays.deleteWhere(func(item model.Holiday) bool {
+		return item.LocationID != nil && *item.LocationID == locationId
+	})
+	return nil
+}
+
+func (r *Holiday) HolidayCustomFindAll() ([]model.HolidayCustom, error) {
+	return r.db.holidayCustoms.find(nil), nil
+}
+
+func (r *Holiday) HolidayCustomFindById(id uint) (model.HolidayCustom, error) {
+	item, ok := r.db.holidayCustoms.get(id)
+	if !ok {
+		return model.HolidayCustom{}, repository.ErrHolidayCustomNotFound
+	}
+	return item, nil
+}
+
+func (r *Holiday) HolidayCustomInsert(item *model.HolidayCustom) error {
+	if item.Yearly == nil {
+		yearly := true
+		item.Yearly = &yearly
+	}
+	return r.db.holidayCustoms.insert(item)
+}
+
+func (r *Holiday) HolidayCustomUpdate(item *model.HolidayCustom) error {
+	return r.db.holidayCustoms.update(item)
+}
+
+func (r *Holiday) HolidayCustomDelete(item *model.HolidayCustom) error {
+	return r.db.holidayCustoms.softDelete(item.ID)
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.LocationRepository = (*Location)(nil)
+
+type Location struct {
+	db *Database
+}
+
+func NewLocation(db *Database) *Location {
+	return &Location{
+		db: db,
+	}
+}
+
+// withOfficeIPRanges sets the office ip ranges like Preload(clause.Associations).
+func (r *Location) withOfficeIPRanges(item model.Location) model.Location {
+	item.OfficeIPRanges = r.db.locationOfficeIPRanges.find(func(ipRange model.LocationOfficeIPRange) bool {
+		return ipRange.LocationID == item.ID
+	})
+	return item
+}
+
+func (r *Location) LocationFindAll() ([]model.Location, error) {
+	items := r.db.locations.find(nil)
+	for i := range items {
+		items[i] = r.withOfficeIPRanges(items[i])
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+func (r *Location) LocationFindById(id uint) (model.Location, error) {
+	item, ok := r.db.locations.get(id)
+	if !ok {
+		return model.Location{}, repository.ErrLocationNotFound
+	}
+	return r.withOfficeIPRanges(item), nil
+}
+
+func (r *Location) LocationFindDefault() (model.Location, error) {
+	item, ok := r.db.locations.first(func(item model.Location) bool {
+		return item.IsDefault
+	})
+	if !ok {
+		return model.Location{}, repository.ErrLocationNotFound
+	}
+	return r.withOfficeIPRanges(item), nil
+}
+
+// LocationInsert saves the location with its office ip ranges.
+func (r *Location) LocationInsert(item *model.Location) error {
+	if item.Country == "" {
+		item.Country = "DE"
+	}
+
+	err := r.db.locations.insert(item)
+	if err != nil {
+		return err
+	}
+	return r.insertOfficeIPRanges(item)
+}
+
+// LocationUpdate saves the location and replaces its office ip ranges.
+func (r *Location) LocationUpdate(item *model.Location) error {
+	r.db.locationOfficeIPRanges.deleteWhere(func(ipRange model.LocationOfficeIPRange) bool {
+		return ipRange.LocationID == item.ID
+	})
+
+	err := r.db.locations.update(item)
+	if err != nil {
+		return err
+	}
+
+	for i := range item.OfficeIPRanges {
+		item.OfficeIPRanges[i].ID = 0
+	}
+	return r.insertOfficeIPRanges(item)
+}
+
+func (r *Location) insertOfficeIPRanges(item *model.Location) error {
+	for i := range item.OfficeIPRanges {
+		item.OfficeIPRanges[i].LocationID = item.ID
+		err := r.db.locationOfficeIPRanges.insert(&item.OfficeIPRanges[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Location) LocationSetDefault(item *model.Location) error {
+	for _, location := range r.db.locations.find(nil) {
+		location.IsDefault = location.ID == item.ID
+		err := r.db.locations.update(&location)
+		if err != nil {
+			return err
+		}
+	}
+
+	item.IsDefault = true
+	return nil
+}
+
+func (r *Location) LocationDelete(item *model.Location) error {
+	r.db.locationOfficeIPRanges.deleteWhere(func(ipRange model.LocationOfficeIPRange) bool {
+		return ipRange.LocationID == item.ID
+	})
+	return r.db.locations.softDelete(item.ID)
+}
+
+func (r *Location) LocationIsAssigned(locationId uint) (bool, error) {
+	_, ok := r.db.userLocations.first(func(item model.UserLocation) bool {
+		return item.LocationID == locationId
+	})
+	return ok, nil
+}
+
+func (r *Location) UserLocationFindById(id uint) (model.UserLocation, error) {
+	item, ok := r.db.userLocations.get(id)
+	if !ok {
+		return model.UserLocation{}, repository.ErrUserLocationNotFound
+	}
+
+	item.Location, _ = r.db.locations.get(item.LocationID)
+	return item, nil
+}
+
+func (r *Location) UserLocationFindByUserId(userId uint) (model.UserLocations, error) {
+	items := r.db.userLocations.find(func(item model.UserLocation) bool {
+		return item.UserID == userId
+	})
+	for i := range items {
+		location, _ := r.db.locations.get(items[i].LocationID)
+		items[i].Location = r.withOfficeIPRanges(location)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ValidFrom.Before(items[j].ValidFrom)
+	})
+	return items, nil
+}
+
+func (r *Location) UserLocationInsert(item *model.UserLocation) error {
+	return r.db.userLocations.insert(item)
+}
+
+func (r *Location) UserLocationUpdate(item *model.UserLocation) error {
+	return r.db.userLocations.update(item)
+}
+
+func (r *Location) UserLocationDelete(item *model.UserLocation) error {
+	return r.db.userLocations.delete(item.ID)
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.MigrationRepository = (*Migration)(nil)
+
+type Migration struct {
+	db *Database
+}
+
+func NewMigration(db *Database) *Migration {
+	return &Migration{
+		db: db,
+	}
+}
+
+func (r *Migration) MigrationFindAll() ([]model.Migration, error) {
+	return r.db.migrations.find(nil), nil
+}
+
+func (r *Migration) MigrationFindById(id uint) (model.Migration, error) {
+	item, ok := r.db.migrations.get(id)
+	if !ok {
+		return model.Migration{}, repository.ErrMigrationNotFound
+	}
+	return item, nil
+}
+
+func (r *Migration) MigrationFindByTitle(title string) (model.Migration, error) {
+	item, ok := r.db.migrations.first(func(item model.Migration) bool {
+		return item.Title == title
+	})
+	if !ok {
+		return model.Migration{}, repository.ErrMigrationNotFound
+	}
+	return item, nil
+}
+
+func (r *Migration) MigrationInsert(item *model.Migration) error {
+	return r.db.migrations.insert(item)
+}
+
+func (r *Migration) MigrationUpdate(item *model.Migration) error {
+	return r.db.migrations.update(item)
+}
+
+func (r *Migration) MigrationDelete(item *model.Migration) error {
+	return r.db.migrations.softDelete(item.ID)
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.MonthClosingRepository = (*MonthClosing)(nil)
+
+type MonthClosing struct {
+	db *Database
+}
+
+func NewMonthClosing(db *Database) *MonthClosing {
+	return &MonthClosing{
+		db: db,
+	}
+}
+
+func (r *MonthClosing) MonthClosingFindAll() ([]model.MonthClosing, error) {
+	return sortMonthClosings(r.db.monthClosings.find(nil)), nil
+}
+
+func (r *MonthClosing) MonthClosingFindByUserId(userId uint) ([]model.MonthClosing, error) {
+	return sortMonthClosings(r.db.monthClosings.find(func(item model.MonthClosing) bool {
+		return item.UserID == nil || *item.UserID == userId
+	})), nil
+}
+
+func (r *MonthClosing) MonthClosingFindById(id uint) (model.MonthClosing, error) {
+	item, ok := r.db.monthClosings.get(id)
+	if !ok {
+		return model.MonthClosing{}, repository.ErrMonthClosingNotFound
+	}
+	return item, nil
+}
+
+func (r *MonthClosing) MonthClosingFindActive(userId *uint, year int, month int) ([]model.MonthClosing, error) {
+	return r.db.monthClosings.find(func(item model.MonthClosing) bool {
+		if item.Year != year || item.Month != month || item.ReopenedAt != nil {
+			return false
+		}
+		return item.UserID == nil || (userId != nil && *item.UserID == *userId)
+	}), nil
+}
+
+func (r *MonthClosing) IsMonthClosed(userId uint, year int, month int) (bool, error) {
+	closings, err := r.MonthClosingFindActive(&userId, year, month)
+	return len(closings) > 0, err
+}
+
+func (r *MonthClosing) IsPeriodClosed(userId uint, from time.Time, till time.Time) (bool, error) {
+	current := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(till.Year(), till.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !current.After(last) {
+		closed, err := r.IsMonthClosed(userId, current.Year(), int(current.Month()))
+		if err != nil || closed {
+			return closed, err
+		}
+		current = current.AddDate(0, 1, 0)
+	}
+	return false, nil
+}
+
+func (r *MonthClosing) MonthClosingInsert(item *model.MonthClosing) error {
+	return r.db.monthClosings.insert(item)
+}
+
+func (r *MonthClosing) MonthClosingUpdate(item *model.MonthClosing) error {
+	return r.db.monthClosings.update(item)
+}
+
+func sortMonthClosings(items []model.MonthClosing) []model.MonthClosing {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Year != items[j].Year {
+			return items[i].Year > items[j].Year
+		}
+		if items[i].Month != items[j].Month {
+			return items[i].Month > items[j].Month
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.OutboxRepository = (*Outbox)(nil)
+
+type Outbox struct {
+	db *Database
+}
+
+func NewOutbox(db *Database) *Outbox {
+	return &Outbox{
+		db: db,
+	}
+}
+
+func (r *Outbox) OutboxJobFindByStatus(status model.OutboxJobStatus) ([]model.OutboxJob, error) {
+	items := r.db.outboxJobs.find(func(item model.OutboxJob) bool {
+		return item.Status == status
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (r *Outbox) OutboxJobFindDue(now time.Time, limit int) ([]model.OutboxJob, error) {
+	items := r.db.outboxJobs.find(func(item model.OutboxJob) bool {
+		return item.Status == model.OUTBOX_JOB_STATUS_PENDING && !item.NextAttemptAt.After(now)
+	})
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *Outbox) OutboxJobFindById(id uint) (model.OutboxJob, error) {
+	item, ok := r.db.outboxJobs.get(id)
+	if !ok {
+		return model.OutboxJob{}, repository.ErrOutboxJobNotFound
+	}
+	return item, nil
+}
+
+func (r *Outbox) OutboxJobInsert(tx *gorm.DB, item *model.OutboxJob) error {
+	if item.Status == "" {
+		item.Status = model.OUTBOX_JOB_STATUS_PENDING
+	}
+	return r.db.outboxJobs.insert(item)
+}
+
+func (r *Outbox) OutboxJobUpdate(item *model.OutboxJob) error {
+	return r.db.outboxJobs.update(item)
+}
+
+func (r *Outbox) OutboxJobDeleteDeliveredBefore(before time.Time) error {
+	r.db.outboxJobs.deleteWhere(func(item model.OutboxJob) bool {
+		return item.Status == model.OUTBOX_JOB_STATUS_DELIVERED && item.DeliveredAt != nil && item.DeliveredAt.Before(before)
+	})
+	return nil
+}
+
+// Transaction calls fn without a transaction and rolls back all repositories
+// sharing the database if it fails.
+func (r *Outbox) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.transaction(func() error {
+		return fn(nil)
+	})
+}
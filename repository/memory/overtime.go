@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.OvertimeRepository = (*Overtime)(nil)
+
+type Overtime struct {
+	db *Database
+}
+
+func NewOvertime(db *Database) *Overtime {
+	return &Overtime{
+		db: db,
+	}
+}
+
+func (r *Overtime) OvertimeMonthQuotaFindAll() ([]model.OvertimeMonthQuota, error) {
+	return r.db.overtimeMonthQuotas.find(nil), nil
+}
+
+func (r *Overtime) OvertimeMonthQuotaFindById(id uint) (model.OvertimeMonthQuota, error) {
+	item, ok := r.db.overtimeMonthQuotas.get(id)
+	if !ok {
+		return model.OvertimeMonthQuota{}, repository.ErrOvertimeMonthQuotaNotFound
+	}
+	return item, nil
+}
+
+func (r *Overtime) OvertimeMonthQuotaInsert(item *model.OvertimeMonthQuota) error {
+	return r.db.overtimeMonthQuotas.insert(item)
+}
+
+func (r *Overtime) OvertimeMonthQuotaUpdate(item *model.OvertimeMonthQuota) error {
+	return r.db.overtimeMonthQuotas.update(item)
+}
+
+func (r *Overtime) OvertimeMonthQuotaDelete(item *model.OvertimeMonthQuota) error {
+	return r.db.overtimeMonthQuotas.softDelete(item.ID)
+}
+
+func (r *Overtime) OvertimeMonthQuotaFindByUserID(userID uint) ([]model.OvertimeMonthQuota, error) {
+	return r.db.overtimeMonthQuotas.find(func(item model.OvertimeMonthQuota) bool {
+		return item.UserID == userID
+	}), nil
+}
+
+func (r *Overtime) OvertimeMonthQuotaFindByUserIDAndYearAndMonth(userID uint, year int, month int) (model.OvertimeMonthQuota, error) {
+	item, ok := r.db.overtimeMonthQuotas.first(func(item model.OvertimeMonthQuota) bool {
+		return item.UserID == userID && item.Year == year && item.Month == month
+	})
+	if !ok {
+		return model.OvertimeMonthQuota{}, repository.ErrOvertimeMonthQuotaNotFound
+	}
+	return item, nil
+}
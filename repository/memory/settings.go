@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.SettingsRepository = (*Settings)(nil)
+
+type Settings struct {
+	db *Database
+}
+
+func NewSettings(db *Database) *Settings {
+	return &Settings{
+		db: db,
+	}
+}
+
+// SettingsFind creates the settings with their defaults on first access.
+func (r *Settings) SettingsFind() (model.Settings, error) {
+	item, ok := r.db.settings.first(nil)
+	if !ok {
+		checkinDetectionByIPAddress := false
+		item = model.Settings{
+			CheckinDetectionByIPAddress:             &checkinDetectionByIPAddress,
+			TimestampChangeReasonMinimumLength:      20,
+			TimestampMaxHoursBetweenCheckInCheckOut: 12,
+			TimestampAutoCheckoutFallback:           model.AUTO_CHECKOUT_FALLBACK_PLANNED_HOURS,
+			VacationCarryOverExpiryMonth:            3,
+			VacationCarryOverExpiryDay:              31,
+			AbsenceMorningFrom:                      model.DEFAULT_ABSENCE_MORNING_FROM,
+			AbsenceAfternoonFrom:                    model.DEFAULT_ABSENCE_AFTERNOON_FROM,
+			AbsenceAfternoonTill:                    model.DEFAULT_ABSENCE_AFTERNOON_TILL,
+		}
+		err := r.db.settings.insert(&item)
+		if err != nil {
+			return model.Settings{}, err
+		}
+	}
+
+	item.OfficeIPAddresses = r.db.settingsOfficeIPAddresses.find(func(officeIPAddress model.SettingsOfficeIPAddresses) bool {
+		return officeIPAddress.SettingsID == item.ID
+	})
+	item.BreakRules = r.db.settingsBreakRules.find(func(breakRule model.SettingsBreakRule) bool {
+		return breakRule.SettingsID == item.ID
+	})
+	return item, nil
+}
+
+func (r *Settings) SettingsUpdate(item *model.Settings) error {
+	return r.db.settings.update(item)
+}
+
+func (r *Settings) SettingsBreakRuleFindAll() ([]model.SettingsBreakRule, error) {
+	items := r.db.settingsBreakRules.find(nil)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ThresholdHours < items[j].ThresholdHours
+	})
+	return items, nil
+}
+
+func (r *Settings) SettingsBreakRuleFindById(id uint) (model.SettingsBreakRule, error) {
+	item, ok := r.db.settingsBreakRules.get(id)
+	if !ok {
+		return model.SettingsBreakRule{}, repository.ErrSettingsBreakRuleNotFound
+	}
+	return item, nil
+}
+
+func (r *Settings) SettingsBreakRuleInsert(item *model.SettingsBreakRule) error {
+	return r.db.settingsBreakRules.insert(item)
+}
+
+func (r *Settings) SettingsBreakRuleUpdate(item *model.SettingsBreakRule) error {
+	return r.db.settingsBreakRules.update(item)
+}
+
+func (r *Settings) SettingsBreakRuleDelete(item *model.SettingsBreakRule) error {
+	return r.db.settingsBreakRules.softDelete(item.ID)
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.TeamRepository = (*Team)(nil)
+
+type Team struct {
+	db *Database
+}
+
+func NewTeam(db *Database) *Team {
+	return &Team{
+		db: db,
+	}
+}
+
+// withAssociations sets the members and blackout periods like
+// Preload(clause.Associations), withMemberUsers additionally sets the users of
+// the members like Preload("Members.User").
+func (r *Team) withAssociations(item model.Team, withMemberUsers bool) model.Team {
+	item.Members = r.members(item.ID, withMemberUsers)
+	item.BlackoutPeriods = r.db.teamBlackoutPeriods.find(func(blackoutPeriod model.TeamBlackoutPeriod) bool {
+		return blackoutPeriod.TeamID == item.ID
+	})
+	return item
+}
+
+func (r *Team) members(teamId uint, withUsers bool) []model.TeamMember {
+	members := r.db.teamMembers.find(func(member model.TeamMember) bool {
+		return member.TeamID == teamId
+	})
+	if withUsers {
+		for i := range members {
+			members[i].User, _ = r.db.users.get(members[i].UserID)
+		}
+	}
+	return members
+}
+
+func (r *Team) TeamFindAll(withData bool) ([]model.Team, error) {
+	items := r.db.teams.find(nil)
+	if withData {
+		for i := range items {
+			items[i] = r.withAssociations(items[i], true)
+		}
+	}
+	return items, nil
+}
+
+func (r *Team) TeamsFindByUserId(userId uint) ([]model.Team, error) {
+	items := []model.Team{}
+	for _, item := range r.db.teams.find(nil) {
+		item = r.withAssociations(item, true)
+		if slices.ContainsFunc(item.Members, func(member model.TeamMember) bool {
+			return member.UserID == userId
+		}) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *Team) TeamFindById(id uint, withData bool) (model.Team, error) {
+	item, ok := r.db.teams.get(id)
+	if !ok {
+		return model.Team{}, repository.ErrTeamNotFound
+	}
+
+	if withData {
+		item = r.withAssociations(item, false)
+	}
+	return item, nil
+}
+
+// TeamInsert saves the team with its members and blackout periods.
+func (r *Team) TeamInsert(item *model.Team) error {
+	err := r.db.teams.insert(item)
+	if err != nil {
+		return err
+	}
+	err = r.insertMembers(item)
+	if err != nil {
+		return err
+	}
+
+	for i := range item.BlackoutPeriods {
+		item.BlackoutPeriods[i].TeamID = item.ID
+		err := r.db.teamBlackoutPeriods.insert(&item.BlackoutPeriods[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TeamUpdate saves the team and replaces its members, removed members are
+// deleted.
+func (r *Team) TeamUpdate(item *model.Team) error {
+	err := r.db.teams.update(item)
+	if err != nil {
+		return err
+	}
+
+	r.db.teamMembers.deleteWhere(func(member model.TeamMember) bool {
+		return member.TeamID == item.ID && !slices.ContainsFunc(item.Members, func(other model.TeamMember) bool {
+			return other.ID == member.ID
+		})
+	})
+	return r.insertMembers(item)
+}
+
+func (r *Team) insertMembers(item *model.Team) error {
+	for i := range item.Members {
+		if item.Members[i].ID != 0 {
+			continue
+		}
+
+		item.Members[i].TeamID = item.ID
+		err := r.db.teamMembers.insert(&item.Members[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Team) TeamDelete(item *model.Team) error {
+	return r.db.teams.delete(item.ID)
+}
+
+func (r *Team) TeamMemberFindAll() ([]model.TeamMember, error) {
+	return r.db.teamMembers.find(nil), nil
+}
+
+func (r *Team) TeamMemberFindById(id uint) (model.TeamMember, error) {
+	item, ok := r.db.teamMembers.get(id)
+	if !ok {
+		return model.TeamMember{}, repository.ErrTeamMemberNotFound
+	}
+	return item, nil
+}
+
+func (r *Team) TeamMemberInsert(item *model.TeamMember) error {
+	if item.Team.ID != 0 {
+		item.TeamID = item.Team.ID
+	}
+	if item.User.ID != 0 {
+		item.UserID = item.User.ID
+	}
+	return r.db.teamMembers.insert(item)
+}
+
+func (r *Team) TeamMemberUpdate(item *model.TeamMember) error {
+	return r.db.teamMembers.update(item)
+}
+
+func (r *Team) TeamMemberDelete(item *model.TeamMember) error {
+	return r.db.teamMembers.delete(item.ID)
+}
+
+func (r *Team) TeamMemberFindByTeamId(teamId uint, withData bool) ([]model.TeamMember, error) {
+	items := r.members(teamId, withData)
+	if withData {
+		for i := range items {
+			items[i].Team, _ = r.db.teams.get(items[i].TeamID)
+		}
+	}
+	return items, nil
+}
+
+func (r *Team) TeamBlackoutPeriodFindByTeamId(teamId uint) ([]model.TeamBlackoutPeriod, error) {
+	items := r.db.teamBlackoutPeriods.find(func(item model.TeamBlackoutPeriod) bool {
+		return item.TeamID == teamId
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].BlackoutFrom.Before(items[j].BlackoutFrom)
+	})
+	return items, nil
+}
+
+func (r *Team) TeamBlackoutPeriodFindById(id uint) (model.TeamBlackoutPeriod, error) {
+	item, ok := r.db.teamBlackoutPeriods.get(id)
+	if !ok {
+		return model.TeamBlackoutPeriod{}, repository.ErrTeamBlackoutPeriodNotFound
+	}
+	return item, nil
+}
+
+func (r *Team) TeamBlackoutPeriodInsert(item *model.TeamBlackoutPeriod) error {
+	return r.db.teamBlackoutPeriods.insert(item)
+}
+
+func (r *Team) TeamBlackoutPeriodDelete(item *model.TeamBlackoutPeriod) error {
+	return r.db.teamBlackoutPeriods.softDelete(item.ID)
+}
@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.TimestampRepository = (*Timestamp)(nil)
+
+type Timestamp struct {
+	db *Database
+}
+
+func NewTimestamp(db *Database) *Timestamp {
+	return &Timestamp{
+		db: db,
+	}
+}
+
+// withBreaks sets the breaks like Preload("Breaks").
+func (r *Timestamp) withBreaks(item model.Timestamp) model.Timestamp {
+	item.Breaks = r.db.timestampBreaks.find(func(timestampBreak model.TimestampBreak) bool {
+		return timestampBreak.TimestampID == item.ID
+	})
+	return item
+}
+
+// withAssociations sets the associations like Preload(clause.Associations).
+func (r *Timestamp) withAssociations(items []model.Timestamp) []model.Timestamp {
+	for i := range items {
+		items[i] = r.withBreaks(items[i])
+		items[i].User = optionalUser(r.db, &items[i].UserID)
+		items[i].Corrections = r.corrections(items[i].ID)
+	}
+	return items
+}
+
+func (r *Timestamp) corrections(timestampID uint) []model.TimestampCorrection {
+	return r.db.timestampCorrections.find(func(item model.TimestampCorrection) bool {
+		return item.TimestampID == timestampID
+	})
+}
+
+// withTimestamp sets the timestamp like Preload("Timestamp").
+func (r *Timestamp) withTimestamp(items []model.TimestampCorrection) []model.TimestampCorrection {
+	for i := range items {
+		items[i].Timestamp, _ = r.db.timestamps.get(items[i].TimestampID)
+	}
+	return items
+}
+
+func (r *Timestamp) FindAll() ([]model.Timestamp, error) {
+	return r.db.timestamps.find(nil), nil
+}
+
+func (r *Timestamp) FindByID(id uint) (model.Timestamp, error) {
+	item, ok := r.db.timestamps.get(id)
+	if !ok {
+		return model.Timestamp{}, repository.ErrTimestampNotFound
+	}
+	return item, nil
+}
+
+func (r *Timestamp) FindByUserID(userID uint) ([]model.Timestamp, error) {
+	return r.db.timestamps.find(func(item model.Timestamp) bool {
+		return item.UserID == userID
+	}), nil
+}
+
+func (r *Timestamp) FindLastByUserID(userID uint) (model.Timestamp, error) {
+	items, err := r.FindByUserID(userID)
+	if err != nil {
+		return model.Timestamp{}, err
+	}
+	if len(items) == 0 {
+		return model.Timestamp{}, repository.ErrTimestampNotFound
+	}
+
+	sortByComingTimestampDesc(items)
+	return r.withBreaks(items[0]), nil
+}
+
+func (r *Timestamp) FindSuspiciousTimestampsByUserID(userId uint, maxDurationHours int64) ([]model.Timestamp, error) {
+	minimumDate := time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return r.withAssociations(r.db.timestamps.find(func(item model.Timestamp) bool {
+		if item.UserID != userId {
+			return false
+		}
+
+		tooLong := maxDurationHours > 0 && item.OvertimeReason == nil &&
+			item.GoingTimestamp.Sub(item.ComingTimestamp).Hours() > float64(maxDurationHours)
+		return item.ComingTimestamp.Before(minimumDate) || item.GoingTimestamp.Before(minimumDate) ||
+			item.NeedsCorrection || tooLong
+	})), nil
+}
+
+func (r *Timestamp) FindOpenComingBefore(before time.Time) ([]model.Timestamp, error) {
+	items := r.db.timestamps.find(func(item model.Timestamp) bool {
+		return item.GoingTimestamp.IsZero() && item.ComingTimestamp.Before(before)
+	})
+	for i := range items {
+		items[i] = r.withBreaks(items[i])
+	}
+	return items, nil
+}
+
+func (r *Timestamp) FindByUserIDAndDate(userID uint, from, till time.Time) ([]model.Timestamp, error) {
+	items := r.withAssociations(r.db.timestamps.find(func(item model.Timestamp) bool {
+		return item.UserID == userID && isBetween(item.ComingTimestamp, from, till)
+	}))
+
+	sortByComingTimestampDesc(items)
+	return items, nil
+}
+
+func (r *Timestamp) CountByUserID(userID uint) (int64, error) {
+	items, err := r.FindByUserID(userID)
+	return int64(len(items)), err
+}
+
+// Insert saves the timestamp with its breaks.
+func (r *Timestamp) Insert(timestamp *model.Timestamp) error {
+	if timestamp.User != nil {
+		timestamp.UserID = timestamp.User.ID
+	}
+
+	err := r.db.timestamps.insert(timestamp)
+	if err != nil {
+		return err
+	}
+
+	for i := range timestamp.Breaks {
+		timestamp.Breaks[i].TimestampID = timestamp.ID
+		err := r.db.timestampBreaks.insert(&timestamp.Breaks[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Timestamp) Update(tx *gorm.DB, timestamp *model.Timestamp) error {
+	return r.db.timestamps.update(timestamp)
+}
+
+func (r *Timestamp) Delete(timestamp *model.Timestamp) error {
+	r.db.timestampCorrections.deleteWhere(func(item model.TimestampCorrection) bool {
+		return item.TimestampID == timestamp.ID
+	})
+	r.db.timestampBreaks.deleteWhere(func(item model.TimestampBreak) bool {
+		return item.TimestampID == timestamp.ID
+	})
+	return r.db.timestamps.delete(timestamp.ID)
+}
+
+func (r *Timestamp) TimestampCorrectionInsert(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error {
+	if timestampCorrection.Timestamp.ID != 0 {
+		timestampCorrection.TimestampID = timestampCorrection.Timestamp.ID
+	}
+	if timestampCorrection.Status == "" {
+		timestampCorrection.Status = model.TIMESTAMP_CORRECTION_STATUS_APPROVED
+	}
+	return r.db.timestampCorrections.insert(timestampCorrection)
+}
+
+func (r *Timestamp) TimestampCorrectionFindByTimestampID(timestampID uint) ([]model.TimestampCorrection, error) {
+	return r.corrections(timestampID), nil
+}
+
+func (r *Timestamp) TimestampBreakInsert(timestampBreak *model.TimestampBreak) error {
+	return r.db.timestampBreaks.insert(timestampBreak)
+}
+
+func (r *Timestamp) TimestampBreakUpdate(timestampBreak *model.TimestampBreak) error {
+	return r.db.timestampBreaks.update(timestampBreak)
+}
+
+func (r *Timestamp) TimestampCorrectionFindByID(id uint) (model.TimestampCorrection, error) {
+	item, ok := r.db.timestampCorrections.get(id)
+	if !ok {
+		return model.TimestampCorrection{}, repository.ErrTimestampCorrectionNotFound
+	}
+	return r.withTimestamp([]model.TimestampCorrection{item})[0], nil
+}
+
+func (r *Timestamp) TimestampCorrectionFindPendingByTimestampID(timestampID uint) ([]model.TimestampCorrection, error) {
+	return r.db.timestampCorrections.find(func(item model.TimestampCorrection) bool {
+		return item.TimestampID == timestampID && item.Status == model.TIMESTAMP_CORRECTION_STATUS_PENDING
+	}), nil
+}
+
+func (r *Timestamp) TimestampCorrectionFindPendingByUserIDs(userIDs []uint) ([]model.TimestampCorrection, error) {
+	items := r.withTimestamp(r.db.timestampCorrections.find(func(item model.TimestampCorrection) bool {
+		return item.Status == model.TIMESTAMP_CORRECTION_STATUS_PENDING
+	}))
+	items = slices.DeleteFunc(items, func(item model.TimestampCorrection) bool {
+		return !slices.Contains(userIDs, item.Timestamp.UserID)
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (r *Timestamp) TimestampCorrectionUpdate(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error {
+	return r.db.timestampCorrections.update(timestampCorrection)
+}
+
+func (r *Timestamp) FindYearMonthsWithTimestampsByUserId(userID uint) ([]model.TimestampYearMonthGrouped, error) {
+	items, err := r.FindYearMonthsWithTimestamps()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []model.TimestampYearMonthGrouped{}
+	for _, item := range items {
+		if item.UserID == userID {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func (r *Timestamp) FindYearMonthsWithTimestamps() ([]model.TimestampYearMonthGrouped, error) {
+	items := []model.TimestampYearMonthGrouped{}
+	seen := map[string]bool{}
+	for _, timestamp := range r.db.timestamps.find(nil) {
+		item := model.TimestampYearMonthGrouped{
+			Year:   timestamp.ComingTimestamp.Year(),
+			Month:  int(timestamp.ComingTimestamp.Month()),
+			UserID: timestamp.UserID,
+		}
+
+		key := fmt.Sprintf("%d-%d-%d", item.Year, item.Month, item.UserID)
+		if !seen[key] {
+			seen[key] = true
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func sortByComingTimestampDesc(items []model.Timestamp) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].ComingTimestamp.Equal(items[j].ComingTimestamp) {
+			return items[i].ID > items[j].ID
+		}
+		return items[i].ComingTimestamp.After(items[j].ComingTimestamp)
+	})
+}
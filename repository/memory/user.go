@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.UserRepository = (*User)(nil)
+
+type User struct {
+	db *Database
+}
+
+func NewUser(db *Database) *User {
+	return &User{
+		db: db,
+	}
+}
+
+// optionalUser returns the user of a nullable foreign key.
+func optionalUser(db *Database, id *uint) *model.User {
+	if id == nil {
+		return nil
+	}
+
+	user, ok := db.users.get(*id)
+	if !ok {
+		return nil
+	}
+	return &user
+}
+
+func (r *User) FindAll() ([]model.User, error) {
+	return r.db.users.find(nil), nil
+}
+
+func (r *User) FindByID(id uint) (model.User, error) {
+	item, ok := r.db.users.get(id)
+	if !ok {
+		return model.User{}, fmt.Errorf("no user with id %d found", id)
+	}
+	return item, nil
+}
+
+func (r *User) FindByUsername(username string) (model.User, error) {
+	item, ok := r.db.users.first(func(item model.User) bool {
+		return item.Username == username
+	})
+	if !ok {
+		return model.User{}, repository.ErrUserNotFound
+	}
+	return item, nil
+}
+
+func (r *User) FindUserByApikey(apikey string) (model.User, error) {
+	item, ok := r.db.userApikeys.first(func(item model.UserApikey) bool {
+		return item.Apikey == apikey
+	})
+	if !ok {
+		return model.User{}, repository.ErrUserNotFound
+	}
+
+	user, _ := r.db.users.get(item.UserID)
+	return user, nil
+}
+
+func (r *User) Insert(user *model.User) error {
+	return r.db.users.insert(user)
+}
+
+func (r *User) Update(user *model.User) error {
+	return r.db.users.update(user)
+}
+
+func (r *User) Delete(user *model.User) error {
+	return r.db.users.softDelete(user.ID)
+}
+
+func (r *User) Count() (int64, error) {
+	return int64(len(r.db.users.find(nil))), nil
+}
+
+func (r *User) UserApikeyFindAll() ([]model.UserApikey, error) {
+	return r.db.userApikeys.find(nil), nil
+}
+
+func (r *User) UserApikeyFindById(id uint) (model.UserApikey, error) {
+	item, ok := r.db.userApikeys.get(id)
+	if !ok {
+		return model.UserApikey{}, repository.ErrUserApikeyNotFound
+	}
+	return item, nil
+}
+
+func (r *User) UserApikeyInsert(push *model.UserApikey) error {
+	if push.User.ID != 0 {
+		push.UserID = push.User.ID
+	}
+	return r.db.userApikeys.insert(push)
+}
+
+func (r *User) UserApikeyUpdate(push *model.UserApikey) error {
+	return r.db.userApikeys.update(push)
+}
+
+func (r *User) UserApikeyDelete(push *model.UserApikey) error {
+	return r.db.userApikeys.softDelete(push.ID)
+}
+
+func (r *User) UserApikeyFindAllByUserID(userID uint) ([]model.UserApikey, error) {
+	return r.db.userApikeys.find(func(item model.UserApikey) bool {
+		return item.UserID == userID
+	}), nil
+}
+
+func (r *User) UserCalendarFeedFindAllByUserID(userID uint) ([]model.UserCalendarFeed, error) {
+	return r.db.userCalendarFeeds.find(func(item model.UserCalendarFeed) bool {
+		return item.UserID == userID
+	}), nil
+}
+
+func (r *User) UserCalendarFeedFindById(id uint) (model.UserCalendarFeed, error) {
+	item, ok := r.db.userCalendarFeeds.get(id)
+	if !ok {
+		return model.UserCalendarFeed{}, repository.ErrUserCalendarFeedNotFound
+	}
+	return item, nil
+}
+
+func (r *User) UserCalendarFeedFindByToken(token string) (model.UserCalendarFeed, error) {
+	item, ok := r.db.userCalendarFeeds.first(func(item model.UserCalendarFeed) bool {
+		return item.Token == token
+	})
+	if !ok {
+		return model.UserCalendarFeed{}, repository.ErrUserCalendarFeedNotFound
+	}
+
+	item.User, _ = r.db.users.get(item.UserID)
+	return item, nil
+}
+
+func (r *User) UserCalendarFeedInsert(item *model.UserCalendarFeed) error {
+	return r.db.userCalendarFeeds.insert(item)
+}
+
+func (r *User) UserCalendarFeedDelete(item *model.UserCalendarFeed) error {
+	return r.db.userCalendarFeeds.softDelete(item.ID)
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.VacationRepository = (*Vacation)(nil)
+
+type Vacation struct {
+	db *Database
+}
+
+func NewVacation(db *Database) *Vacation {
+	return &Vacation{
+		db: db,
+	}
+}
+
+func (r *Vacation) VacationAdjustmentFindByUserId(userId uint) ([]model.VacationAdjustment, error) {
+	items := r.db.vacationAdjustments.find(func(item model.VacationAdjustment) bool {
+		return item.UserID == userId
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Year != items[j].Year {
+			return items[i].Year < items[j].Year
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (r *Vacation) VacationAdjustmentFindById(id uint) (model.VacationAdjustment, error) {
+	item, ok := r.db.vacationAdjustments.get(id)
+	if !ok {
+		return model.VacationAdjustment{}, repository.ErrVacationAdjustmentNotFound
+	}
+	return item, nil
+}
+
+func (r *Vacation) VacationAdjustmentInsert(item *model.VacationAdjustment) error {
+	return r.db.vacationAdjustments.insert(item)
+}
+
+func (r *Vacation) VacationAdjustmentDelete(item *model.VacationAdjustment) error {
+	return r.db.vacationAdjustments.softDelete(item.ID)
+}
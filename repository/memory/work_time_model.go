@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+var _ repository.WorkTimeModelRepository = (*WorkTimeModel)(nil)
+
+type WorkTimeModel struct {
+	db *Database
+}
+
+func NewWorkTimeModel(db *Database) *WorkTimeModel {
+	return &WorkTimeModel{
+		db: db,
+	}
+}
+
+func (r *WorkTimeModel) WorkTimeModelFindAll() ([]model.WorkTimeModel, error) {
+	return r.db.workTimeModels.find(nil), nil
+}
+
+func (r *WorkTimeModel) WorkTimeModelFindById(id uint) (model.WorkTimeModel, error) {
+	item, ok := r.db.workTimeModels.get(id)
+	if !ok {
+		return model.WorkTimeModel{}, repository.ErrWorkTimeModelNotFound
+	}
+	return item, nil
+}
+
+func (r *WorkTimeModel) WorkTimeModelFindByName(name string) (model.WorkTimeModel, error) {
+	item, ok := r.db.workTimeModels.first(func(item model.WorkTimeModel) bool {
+		return item.Name == name
+	})
+	if !ok {
+		return model.WorkTimeModel{}, repository.ErrWorkTimeModelNotFound
+	}
+	return item, nil
+}
+
+func (r *WorkTimeModel) WorkTimeModelInsert(item *model.WorkTimeModel) error {
+	return r.db.workTimeModels.insert(item)
+}
+
+func (r *WorkTimeModel) WorkTimeModelUpdate(item *model.WorkTimeModel) error {
+	return r.db.workTimeModels.update(item)
+}
+
+func (r *WorkTimeModel) WorkTimeModelDelete(item *model.WorkTimeModel) error {
+	return r.db.workTimeModels.softDelete(item.ID)
+}
+
+func (r *WorkTimeModel) WorkTimeModelIsAssigned(workTimeModelId uint) (bool, error) {
+	_, ok := r.db.userWorkTimeModels.first(func(item model.UserWorkTimeModel) bool {
+		return item.WorkTimeModelID == workTimeModelId
+	})
+	return ok, nil
+}
+
+func (r *WorkTimeModel) UserWorkTimeModelFindById(id uint) (model.UserWorkTimeModel, error) {
+	item, ok := r.db.userWorkTimeModels.get(id)
+	if !ok {
+		return model.UserWorkTimeModel{}, repository.ErrUserWorkTimeModelNotFound
+	}
+
+	item.WorkTimeModel, _ = r.db.workTimeModels.get(item.WorkTimeModelID)
+	return item, nil
+}
+
+func (r *WorkTimeModel) UserWorkTimeModelFindByUserId(userId uint) (model.UserWorkTimeModels, error) {
+	items := r.db.userWorkTimeModels.find(func(item model.UserWorkTimeModel) bool {
+		return item.UserID == userId
+	})
+	for i := range items {
+		items[i].User = optionalUser(r.db, &items[i].UserID)
+		items[i].WorkTimeModel, _ = r.db.workTimeModels.get(items[i].WorkTimeModelID)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ValidFrom.Before(items[j].ValidFrom)
+	})
+	return items, nil
+}
+
+func (r *WorkTimeModel) UserWorkTimeModelInsert(item *model.UserWorkTimeModel) error {
+	return r.db.userWorkTimeModels.insert(item)
+}
+
+func (r *WorkTimeModel) UserWorkTimeModelUpdate(item *model.UserWorkTimeModel) error {
+	return r.db.userWorkTimeModels.update(item)
+}
+
+func (r *WorkTimeModel) UserWorkTimeModelDelete(item *model.UserWorkTimeModel) error {
+	return r.db.userWorkTimeModels.delete(item.ID)
+}
@@ -7,6 +7,17 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 )
 
+type MigrationRepository interface {
+	MigrationFindAll() ([]model.Migration, error)
+	MigrationFindById(id uint) (model.Migration, error)
+	MigrationFindByTitle(title string) (model.Migration, error)
+	MigrationInsert(item *model.Migration) error
+	MigrationUpdate(item *model.Migration) error
+	MigrationDelete(item *model.Migration) error
+}
+
+var _ MigrationRepository = (*Migration)(nil)
+
 type Migration struct {
 	env *core.Environment
 }
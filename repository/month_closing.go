@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+type MonthClosingRepository interface {
+	MonthClosingFindAll() ([]model.MonthClosing, error)
+	MonthClosingFindByUserId(userId uint) ([]model.MonthClosing, error)
+	MonthClosingFindById(id uint) (model.MonthClosing, error)
+	MonthClosingFindActive(userId *uint, year int, month int) ([]model.MonthClosing, error)
+	IsMonthClosed(userId uint, year int, month int) (bool, error)
+	IsPeriodClosed(userId uint, from time.Time, till time.Time) (bool, error)
+	MonthClosingInsert(item *model.MonthClosing) error
+	MonthClosingUpdate(item *model.MonthClosing) error
+}
+
+var _ MonthClosingRepository = (*MonthClosing)(nil)
+
+type MonthClosing struct {
+	env *core.Environment
+}
+
+func NewMonthClosing(env *core.Environment) *MonthClosing {
+	return &MonthClosing{
+		env: env,
+	}
+}
+
+func (r *MonthClosing) Migrate() error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	return db.AutoMigrate(&model.MonthClosing{})
+}
+
+var ErrMonthClosingNotFound = errors.New("MonthClosing not found")
+var ErrMonthClosed = errors.New("month is closed")
+
+func (r MonthClosing) MonthClosingFindAll() ([]model.MonthClosing, error) {
+	var items []model.MonthClosing
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("year DESC, month DESC, created_at DESC").Find(&items)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r MonthClosing) MonthClosingFindByUserId(userId uint) ([]model.MonthClosing, error) {
+	var items []model.MonthClosing
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("year DESC, month DESC, created_at DESC").Find(&items, "user_id = ? OR user_id IS NULL", userId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r MonthClosing) MonthClosingFindById(id uint) (model.MonthClosing, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.MonthClosing{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.MonthClosing
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.MonthClosing{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.MonthClosing{}, ErrMonthClosingNotFound
+	}
+	return item, result.Error
+}
+
+// MonthClosingFindActive returns the active closings of the month which are
+// either for the given user or for all users. Without userId only the
+// closings for all users are returned.
+func (r MonthClosing) MonthClosingFindActive(userId *uint, year int, month int) ([]model.MonthClosing, error) {
+	var items []model.MonthClosing
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	query := db.Where("year = ? AND month = ? AND reopened_at IS NULL", year, month)
+	if userId != nil {
+		query = query.Where("user_id = ? OR user_id IS NULL", *userId)
+	} else {
+		query = query.Where("user_id IS NULL")
+	}
+
+	result := query.Find(&items)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r MonthClosing) IsMonthClosed(userId uint, year int, month int) (bool, error) {
+	closings, err := r.MonthClosingFindActive(&userId, year, month)
+	if err != nil {
+		return false, err
+	}
+
+	return len(closings) > 0, nil
+}
+
+// IsPeriodClosed checks every month between from and till.
+func (r MonthClosing) IsPeriodClosed(userId uint, from time.Time, till time.Time) (bool, error) {
+	current := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(till.Year(), till.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for !current.After(last) {
+		closed, err := r.IsMonthClosed(userId, current.Year(), int(current.Month()))
+		if err != nil || closed {
+			return closed, err
+		}
+
+		current = current.AddDate(0, 1, 0)
+	}
+
+	return false, nil
+}
+
+func (r MonthClosing) MonthClosingInsert(item *model.MonthClosing) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r MonthClosing) MonthClosingUpdate(item *model.MonthClosing) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Updates(item)
+	return result.Error
+}
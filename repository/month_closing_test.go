@@ -0,0 +1,74 @@
+//go:build cgo
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+func TestMonthClosingIsPeriodClosed(t *testing.T) {
+	t.Setenv("DB_TYPE", database.DB_TYPE_SQLITE)
+	t.Setenv("DATABASE", filepath.Join(t.TempDir(), "beetimeclock.db"))
+
+	env := core.NewEnvironment()
+	env.DatabaseManager = database.NewDatabaseManager("beetc")
+
+	repo := NewMonthClosing(env)
+	err := repo.Migrate()
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	userId := uint(1)
+	otherUserId := uint(2)
+	reopenedAt := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+	closings := []model.MonthClosing{
+		{UserID: &userId, Year: 2025, Month: 3},
+		{Year: 2025, Month: 1},
+		{UserID: &otherUserId, Year: 2025, Month: 6},
+		{UserID: &userId, Year: 2025, Month: 5, ReopenedAt: &reopenedAt},
+	}
+	for _, closing := range closings {
+		err = repo.MonthClosingInsert(&closing)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	date := func(month int, day int) time.Time {
+		return time.Date(2025, time.Month(month), day, 12, 0, 0, 0, time.UTC)
+	}
+
+	testData := []struct {
+		Name string
+		From time.Time
+		Till time.Time
+		Want bool
+	}{
+		{Name: "closed for the user", From: date(3, 15), Till: date(3, 15), Want: true},
+		{Name: "closed for everyone", From: date(1, 31), Till: date(1, 31), Want: true},
+		{Name: "open month", From: date(2, 1), Till: date(2, 28), Want: false},
+		{Name: "ends in closed month", From: date(2, 20), Till: date(3, 1), Want: true},
+		{Name: "spans closed month", From: date(2, 20), Till: date(4, 10), Want: true},
+		{Name: "closed for another user", From: date(6, 1), Till: date(6, 30), Want: false},
+		{Name: "reopened", From: date(5, 1), Till: date(5, 31), Want: false},
+		{Name: "last day before closed month", From: date(4, 1), Till: date(4, 30), Want: false},
+	}
+
+	for _, test := range testData {
+		got, err := repo.IsPeriodClosed(userId, test.From, test.Till)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		if got != test.Want {
+			t.Errorf("%s: want %t, got %t", test.Name, test.Want, got)
+		}
+	}
+}
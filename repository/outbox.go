@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	OutboxJobFindByStatus(status model.OutboxJobStatus) ([]model.OutboxJob, error)
+	OutboxJobFindDue(now time.Time, limit int) ([]model.OutboxJob, error)
+	OutboxJobFindById(id uint) (model.OutboxJob, error)
+	OutboxJobInsert(tx *gorm.DB, item *model.OutboxJob) error
+	OutboxJobUpdate(item *model.OutboxJob) error
+	OutboxJobDeleteDeliveredBefore(before time.Time) error
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+var _ OutboxRepository = (*Outbox)(nil)
+
+type Outbox struct {
+	env *core.Environment
+}
+
+func NewOutbox(env *core.Environment) *Outbox {
+	return &Outbox{
+		env: env,
+	}
+}
+
+func (r *Outbox) Migrate() error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	return db.AutoMigrate(&model.OutboxJob{})
+}
+
+var ErrOutboxJobNotFound = errors.New("OutboxJob not found")
+
+func (r Outbox) OutboxJobFindByStatus(status model.OutboxJobStatus) ([]model.OutboxJob, error) {
+	var items []model.OutboxJob
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("created_at desc").Find(&items, "status = ?", status)
+	return items, result.Error
+}
+
+// OutboxJobFindDue returns the oldest pending jobs which are ready for their
+// next attempt.
+func (r Outbox) OutboxJobFindDue(now time.Time, limit int) ([]model.OutboxJob, error) {
+	var items []model.OutboxJob
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("id").Limit(limit).Find(&items, "status = ? and next_attempt_at <= ?", model.OUTBOX_JOB_STATUS_PENDING, now)
+	return items, result.Error
+}
+
+func (r Outbox) OutboxJobFindById(id uint) (model.OutboxJob, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.OutboxJob{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.OutboxJob
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.OutboxJob{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.OutboxJob{}, ErrOutboxJobNotFound
+	}
+	return item, result.Error
+}
+
+func (r Outbox) OutboxJobInsert(tx *gorm.DB, item *model.OutboxJob) error {
+	db, closeConnection, err := getConnection(r.env, tx)
+	if err != nil {
+		return err
+	}
+	defer closeConnection()
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r Outbox) OutboxJobUpdate(item *model.OutboxJob) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Select("*").Omit("created_at", clause.Associations).Updates(item)
+	return result.Error
+}
+
+// OutboxJobDeleteDeliveredBefore removes delivered jobs, failed jobs are kept
+// for inspection.
+func (r Outbox) OutboxJobDeleteDeliveredBefore(before time.Time) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Where("status = ? and delivered_at < ?", model.OUTBOX_JOB_STATUS_DELIVERED, before).Delete(&model.OutboxJob{})
+	return result.Error
+}
+
+// Transaction runs fn in a database transaction, the jobs inserted with the
+// transaction are only stored together with the changes they belong to.
+func (r Outbox) Transaction(fn func(tx *gorm.DB) error) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	return db.Transaction(fn)
+}
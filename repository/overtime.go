@@ -7,6 +7,18 @@ import (
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 )
 
+type OvertimeRepository interface {
+	OvertimeMonthQuotaFindAll() ([]model.OvertimeMonthQuota, error)
+	OvertimeMonthQuotaFindById(id uint) (model.OvertimeMonthQuota, error)
+	OvertimeMonthQuotaInsert(item *model.OvertimeMonthQuota) error
+	OvertimeMonthQuotaUpdate(item *model.OvertimeMonthQuota) error
+	OvertimeMonthQuotaDelete(item *model.OvertimeMonthQuota) error
+	OvertimeMonthQuotaFindByUserID(userID uint) ([]model.OvertimeMonthQuota, error)
+	OvertimeMonthQuotaFindByUserIDAndYearAndMonth(userID uint, year int, month int) (model.OvertimeMonthQuota, error)
+}
+
+var _ OvertimeRepository = (*Overtime)(nil)
+
 type Overtime struct {
 	env *core.Environment
 }
@@ -8,6 +8,18 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+type SettingsRepository interface {
+	SettingsFind() (model.Settings, error)
+	SettingsUpdate(item *model.Settings) error
+	SettingsBreakRuleFindAll() ([]model.SettingsBreakRule, error)
+	SettingsBreakRuleFindById(id uint) (model.SettingsBreakRule, error)
+	SettingsBreakRuleInsert(item *model.SettingsBreakRule) error
+	SettingsBreakRuleUpdate(item *model.SettingsBreakRule) error
+	SettingsBreakRuleDelete(item *model.SettingsBreakRule) error
+}
+
+var _ SettingsRepository = (*Settings)(nil)
+
 type Settings struct {
 	env *core.Environment
 }
@@ -35,11 +47,32 @@ func (r Settings) Migrate() error {
 		return err
 	}
 
-	_, err = r.SettingsFind()
+	err = db.AutoMigrate(&model.SettingsBreakRule{})
+	if err != nil {
+		return err
+	}
+
+	settings, err := r.SettingsFind()
 	if err != nil {
 		return err
 	}
 
+	var breakRuleCount int64
+	result := db.Unscoped().Model(&model.SettingsBreakRule{}).Count(&breakRuleCount)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if breakRuleCount == 0 {
+		for _, breakRule := range model.DefaultBreakRules() {
+			breakRule.SettingsID = settings.ID
+			err = r.SettingsBreakRuleInsert(&breakRule)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -70,3 +103,72 @@ func (r Settings) SettingsUpdate(item *model.Settings) error {
 	result := db.Updates(item)
 	return result.Error
 }
+
+var ErrSettingsBreakRuleNotFound = errors.New("SettingsBreakRule not found")
+
+func (r Settings) SettingsBreakRuleFindAll() ([]model.SettingsBreakRule, error) {
+	var items []model.SettingsBreakRule
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("threshold_hours").Find(&items)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r Settings) SettingsBreakRuleFindById(id uint) (model.SettingsBreakRule, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.SettingsBreakRule{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.SettingsBreakRule
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.SettingsBreakRule{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.SettingsBreakRule{}, ErrSettingsBreakRuleNotFound
+	}
+	return item, result.Error
+}
+
+func (r Settings) SettingsBreakRuleInsert(item *model.SettingsBreakRule) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r Settings) SettingsBreakRuleUpdate(item *model.SettingsBreakRule) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Select("*").Omit("created_at").Updates(item)
+	return result.Error
+}
+
+func (r Settings) SettingsBreakRuleDelete(item *model.SettingsBreakRule) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Delete(item)
+	return result.Error
+}
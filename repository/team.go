@@ -5,9 +5,31 @@ import (
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+type TeamRepository interface {
+	TeamFindAll(withData bool) ([]model.Team, error)
+	TeamsFindByUserId(userId uint) ([]model.Team, error)
+	TeamFindById(id uint, withData bool) (model.Team, error)
+	TeamInsert(item *model.Team) error
+	TeamUpdate(item *model.Team) error
+	TeamDelete(item *model.Team) error
+	TeamMemberFindAll() ([]model.TeamMember, error)
+	TeamMemberFindById(id uint) (model.TeamMember, error)
+	TeamMemberInsert(item *model.TeamMember) error
+	TeamMemberUpdate(item *model.TeamMember) error
+	TeamMemberDelete(item *model.TeamMember) error
+	TeamMemberFindByTeamId(teamId uint, withData bool) ([]model.TeamMember, error)
+	TeamBlackoutPeriodFindByTeamId(teamId uint) ([]model.TeamBlackoutPeriod, error)
+	TeamBlackoutPeriodFindById(id uint) (model.TeamBlackoutPeriod, error)
+	TeamBlackoutPeriodInsert(item *model.TeamBlackoutPeriod) error
+	TeamBlackoutPeriodDelete(item *model.TeamBlackoutPeriod) error
+}
+
+var _ TeamRepository = (*Team)(nil)
+
 type Team struct {
 	env *core.Environment
 }
@@ -35,6 +57,11 @@ func (r *Team) Migrate() error {
 		return err
 	}
 
+	err = db.AutoMigrate(&model.TeamBlackoutPeriod{})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -110,6 +137,8 @@ func (r Team) TeamInsert(item *model.Team) error {
 	return result.Error
 }
 
+// TeamUpdate saves all fields of the team, also zero values, and replaces its
+// members. Removed members are deleted.
 func (r Team) TeamUpdate(item *model.Team) error {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -117,8 +146,21 @@ func (r Team) TeamUpdate(item *model.Team) error {
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	result := db.Updates(item)
-	return result.Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Select("*").Omit("created_at", clause.Associations).Updates(item)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		err := tx.Model(item).Association("Members").Replace(item.Members)
+		if err != nil {
+			return err
+		}
+
+		// Replace only unsets the team of the removed members
+		result = tx.Unscoped().Where("team_id IS NULL").Delete(&model.TeamMember{})
+		return result.Error
+	})
 }
 
 func (r Team) TeamDelete(item *model.Team) error {
@@ -219,3 +261,61 @@ func (r Team) TeamMemberFindByTeamId(teamId uint, withData bool) ([]model.TeamMe
 	}
 	return items, result.Error
 }
+
+var ErrTeamBlackoutPeriodNotFound = errors.New("TeamBlackoutPeriod not found")
+
+func (r Team) TeamBlackoutPeriodFindByTeamId(teamId uint) ([]model.TeamBlackoutPeriod, error) {
+	var items []model.TeamBlackoutPeriod
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("blackout_from").Find(&items, "team_id = ?", teamId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r Team) TeamBlackoutPeriodFindById(id uint) (model.TeamBlackoutPeriod, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.TeamBlackoutPeriod{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.TeamBlackoutPeriod
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.TeamBlackoutPeriod{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.TeamBlackoutPeriod{}, ErrTeamBlackoutPeriodNotFound
+	}
+	return item, result.Error
+}
+
+func (r Team) TeamBlackoutPeriodInsert(item *model.TeamBlackoutPeriod) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r Team) TeamBlackoutPeriodDelete(item *model.TeamBlackoutPeriod) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Delete(item)
+	return result.Error
+}
@@ -0,0 +1,91 @@
+//go:build cgo
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+func TestTeamUpdate(t *testing.T) {
+	t.Setenv("DB_TYPE", database.DB_TYPE_SQLITE)
+	t.Setenv("DATABASE", filepath.Join(t.TempDir(), "beetimeclock.db"))
+
+	env := core.NewEnvironment()
+	env.DatabaseManager = database.NewDatabaseManager("beetc")
+
+	userRepo := NewUser(env)
+	repo := NewTeam(env)
+	for _, migrate := range []func() error{userRepo.Migrate, repo.Migrate} {
+		err := migrate()
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	users := []model.User{{Username: "first"}, {Username: "second"}, {Username: "third"}}
+	for i := range users {
+		err := userRepo.Insert(&users[i])
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	team := model.Team{
+		Teamname:              "team",
+		MinimumPresentMembers: 2,
+		Members: []model.TeamMember{
+			{UserID: users[0].ID, Level: model.TeamLevel_Lead},
+			{UserID: users[1].ID, Level: model.TeamLevel_Member},
+		},
+	}
+	err := repo.TeamInsert(&team)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	team, err = repo.TeamFindById(team.ID, true)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	team.Teamname = "renamed"
+	team.MinimumPresentMembers = 0
+	team.Members = []model.TeamMember{
+		team.Members[0],
+		{UserID: users[2].ID, Level: model.TeamLevel_Member},
+	}
+	err = repo.TeamUpdate(&team)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	got, err := repo.TeamFindById(team.ID, true)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if got.Teamname != "renamed" || got.MinimumPresentMembers != 0 {
+		t.Errorf("want renamed team without minimum, got %+v", got)
+	}
+
+	userIds := []uint{}
+	for _, member := range got.Members {
+		userIds = append(userIds, member.UserID)
+	}
+	if len(userIds) != 2 || userIds[0] != users[0].ID || userIds[1] != users[2].ID {
+		t.Errorf("want members %d and %d, got %v", users[0].ID, users[2].ID, userIds)
+	}
+
+	members, err := repo.TeamMemberFindAll()
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if len(members) != 2 {
+		t.Errorf("want the removed member deleted, got %d members", len(members))
+	}
+}
@@ -2,14 +2,42 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/database"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+type TimestampRepository interface {
+	FindAll() ([]model.Timestamp, error)
+	FindByID(id uint) (model.Timestamp, error)
+	FindByUserID(userID uint) ([]model.Timestamp, error)
+	FindLastByUserID(userID uint) (model.Timestamp, error)
+	FindSuspiciousTimestampsByUserID(userId uint, maxDurationHours int64) ([]model.Timestamp, error)
+	FindOpenComingBefore(before time.Time) ([]model.Timestamp, error)
+	FindByUserIDAndDate(userID uint, from, till time.Time) ([]model.Timestamp, error)
+	CountByUserID(userID uint) (int64, error)
+	Insert(timestamp *model.Timestamp) error
+	Update(tx *gorm.DB, timestamp *model.Timestamp) error
+	Delete(timestamp *model.Timestamp) error
+	TimestampCorrectionInsert(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error
+	TimestampCorrectionFindByTimestampID(timestampID uint) ([]model.TimestampCorrection, error)
+	TimestampBreakInsert(timestampBreak *model.TimestampBreak) error
+	TimestampBreakUpdate(timestampBreak *model.TimestampBreak) error
+	TimestampCorrectionFindByID(id uint) (model.TimestampCorrection, error)
+	TimestampCorrectionFindPendingByTimestampID(timestampID uint) ([]model.TimestampCorrection, error)
+	TimestampCorrectionFindPendingByUserIDs(userIDs []uint) ([]model.TimestampCorrection, error)
+	TimestampCorrectionUpdate(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error
+	FindYearMonthsWithTimestampsByUserId(userID uint) ([]model.TimestampYearMonthGrouped, error)
+	FindYearMonthsWithTimestamps() ([]model.TimestampYearMonthGrouped, error)
+}
+
+var _ TimestampRepository = (*Timestamp)(nil)
+
 type Timestamp struct {
 	env *core.Environment
 }
@@ -39,6 +67,11 @@ func (r *Timestamp) Migrate() error {
 		return err
 	}
 
+	err = db.AutoMigrate(&model.TimestampBreak{})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -94,7 +127,7 @@ func (r *Timestamp) FindLastByUserID(userID uint) (model.Timestamp, error) {
 
 	var item model.Timestamp
 
-	result := db.Order("coming_timestamp DESC").Last(&item, "user_id = ?", userID)
+	result := db.Preload("Breaks").Order("coming_timestamp DESC").Last(&item, "user_id = ?", userID)
 
 	if result.RowsAffected == 0 || errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return model.Timestamp{}, ErrTimestampNotFound
@@ -111,11 +144,12 @@ func (r *Timestamp) FindSuspiciousTimestampsByUserID(userId uint, maxDurationHou
 	}
 	defer r.env.DatabaseManager.CloseConnection(db)
 
-	conditionsQuery := db.Or("(date_part('year', coming_timestamp) < 1999 or date_part('year', going_timestamp) < 1999)").
-		Or("(needs_correction = true)")
+	minimumDate := time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	conditionsQuery := db.Or("(coming_timestamp < ? or going_timestamp < ?)", minimumDate, minimumDate).
+		Or("(needs_correction = ?)", true)
 
 	if maxDurationHours > 0 {
-		conditionsQuery = conditionsQuery.Or("EXTRACT(EPOCH FROM (going_timestamp - coming_timestamp)) / 3600 > ? and overtime_reason is null", maxDurationHours)
+		conditionsQuery = conditionsQuery.Or(database.HoursBetween(db, "coming_timestamp", "going_timestamp")+" > ? and overtime_reason is null", maxDurationHours)
 	}
 
 	result := db.Debug().Preload(clause.Associations).Where("user_id = ?", userId).Where(conditionsQuery)
@@ -128,6 +162,19 @@ func (r *Timestamp) FindSuspiciousTimestampsByUserID(userId uint, maxDurationHou
 	return items, result.Error
 }
 
+func (r *Timestamp) FindOpenComingBefore(before time.Time) ([]model.Timestamp, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var items []model.Timestamp
+	result := db.Preload("Breaks").Find(&items, "going_timestamp = ? AND coming_timestamp < ?", time.Time{}, before)
+
+	return items, result.Error
+}
+
 func (r *Timestamp) FindByUserIDAndDate(userID uint, from, till time.Time) ([]model.Timestamp, error) {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -164,12 +211,12 @@ func (r *Timestamp) Insert(timestamp *model.Timestamp) error {
 	return result.Error
 }
 
-func (r *Timestamp) Update(timestamp *model.Timestamp) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r *Timestamp) Update(tx *gorm.DB, timestamp *model.Timestamp) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Updates(&timestamp)
 	return result.Error
@@ -190,6 +237,12 @@ func (r *Timestamp) Delete(timestamp *model.Timestamp) error {
 			return err
 		}
 
+		if err := tx.Unscoped().
+			Where("timestamp_id = ?", timestamp.ID).
+			Delete(&model.TimestampBreak{}).Error; err != nil {
+			return err
+		}
+
 		if err := tx.Debug().Unscoped().Delete(&timestamp).Error; err != nil {
 			return err
 		}
@@ -197,12 +250,12 @@ func (r *Timestamp) Delete(timestamp *model.Timestamp) error {
 	})
 }
 
-func (r *Timestamp) TimestampCorrectionInsert(timestampCorrection *model.TimestampCorrection) error {
-	db, err := r.env.DatabaseManager.GetConnection()
+func (r *Timestamp) TimestampCorrectionInsert(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error {
+	db, closeConnection, err := getConnection(r.env, tx)
 	if err != nil {
 		return err
 	}
-	defer r.env.DatabaseManager.CloseConnection(db)
+	defer closeConnection()
 
 	result := db.Create(&timestampCorrection)
 	return result.Error
@@ -221,6 +274,91 @@ func (r *Timestamp) TimestampCorrectionFindByTimestampID(timestampID uint) ([]mo
 	return items, result.Error
 }
 
+func (r *Timestamp) TimestampBreakInsert(timestampBreak *model.TimestampBreak) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(timestampBreak)
+	return result.Error
+}
+
+func (r *Timestamp) TimestampBreakUpdate(timestampBreak *model.TimestampBreak) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Updates(timestampBreak)
+	return result.Error
+}
+
+var ErrTimestampCorrectionNotFound = errors.New("timestamp correction not found")
+
+func (r *Timestamp) TimestampCorrectionFindByID(id uint) (model.TimestampCorrection, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.TimestampCorrection{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.TimestampCorrection
+	result := db.Preload("Timestamp").Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.TimestampCorrection{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.TimestampCorrection{}, ErrTimestampCorrectionNotFound
+	}
+
+	return item, result.Error
+}
+
+func (r *Timestamp) TimestampCorrectionFindPendingByTimestampID(timestampID uint) ([]model.TimestampCorrection, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var items []model.TimestampCorrection
+	result := db.Find(&items, "timestamp_id = ? AND status = ?", timestampID, model.TIMESTAMP_CORRECTION_STATUS_PENDING)
+
+	return items, result.Error
+}
+
+func (r *Timestamp) TimestampCorrectionFindPendingByUserIDs(userIDs []uint) ([]model.TimestampCorrection, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var items []model.TimestampCorrection
+	result := db.Preload("Timestamp").
+		Where("timestamp_id IN (?)", db.Table("beetc_timestamp").Select("id").Where("user_id IN ?", userIDs)).
+		Where("status = ?", model.TIMESTAMP_CORRECTION_STATUS_PENDING).
+		Order("created_at").
+		Find(&items)
+
+	return items, result.Error
+}
+
+func (r *Timestamp) TimestampCorrectionUpdate(tx *gorm.DB, timestampCorrection *model.TimestampCorrection) error {
+	db, closeConnection, err := getConnection(r.env, tx)
+	if err != nil {
+		return err
+	}
+	defer closeConnection()
+
+	result := db.Omit("Timestamp", "SignedUser").Updates(timestampCorrection)
+	return result.Error
+}
+
 func (r *Timestamp) FindYearMonthsWithTimestampsByUserId(userID uint) ([]model.TimestampYearMonthGrouped, error) {
 	db, err := r.env.DatabaseManager.GetConnection()
 	if err != nil {
@@ -238,7 +376,7 @@ func (r *Timestamp) FindYearMonthsWithTimestampsByUserId(userID uint) ([]model.T
 	}
 
 	result = db.Model(&model.Timestamp{}).
-		Select("distinct extract(year from coming_timestamp) as year, extract(month from coming_timestamp) as month").
+		Select(fmt.Sprintf("distinct %s as year, %s as month", database.YearOf(db, "coming_timestamp"), database.MonthOf(db, "coming_timestamp"))).
 		Where("user_id = ?", userID).
 		Scan(&items)
 
@@ -262,7 +400,7 @@ func (r *Timestamp) FindYearMonthsWithTimestamps() ([]model.TimestampYearMonthGr
 	}
 
 	result = db.Model(&model.Timestamp{}).
-		Select("distinct extract(year from coming_timestamp) as year, extract(month from coming_timestamp) as month, user_id").
+		Select(fmt.Sprintf("distinct %s as year, %s as month, user_id", database.YearOf(db, "coming_timestamp"), database.MonthOf(db, "coming_timestamp"))).
 		Scan(&items)
 
 	return items, result.Error
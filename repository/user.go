@@ -9,12 +9,37 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+type UserRepository interface {
+	FindAll() ([]model.User, error)
+	FindByID(id uint) (model.User, error)
+	FindByUsername(username string) (model.User, error)
+	FindUserByApikey(apikey string) (model.User, error)
+	Insert(user *model.User) error
+	Update(user *model.User) error
+	Delete(user *model.User) error
+	Count() (int64, error)
+	UserApikeyFindAll() ([]model.UserApikey, error)
+	UserApikeyFindById(id uint) (model.UserApikey, error)
+	UserApikeyInsert(push *model.UserApikey) error
+	UserApikeyUpdate(push *model.UserApikey) error
+	UserApikeyDelete(push *model.UserApikey) error
+	UserApikeyFindAllByUserID(userID uint) ([]model.UserApikey, error)
+	UserCalendarFeedFindAllByUserID(userID uint) ([]model.UserCalendarFeed, error)
+	UserCalendarFeedFindById(id uint) (model.UserCalendarFeed, error)
+	UserCalendarFeedFindByToken(token string) (model.UserCalendarFeed, error)
+	UserCalendarFeedInsert(item *model.UserCalendarFeed) error
+	UserCalendarFeedDelete(item *model.UserCalendarFeed) error
+}
+
+var _ UserRepository = (*User)(nil)
+
 type User struct {
 	env *core.Environment
 }
 
 var ErrUserNotFound = errors.New("user not found")
 var ErrUserApikeyNotFound = errors.New("user apikey not found")
+var ErrUserCalendarFeedNotFound = errors.New("user calendar feed not found")
 
 func NewUser(env *core.Environment) *User {
 	return &User{
@@ -39,6 +64,11 @@ func (r *User) Migrate() error {
 		return err
 	}
 
+	err = db.AutoMigrate(&model.UserCalendarFeed{})
+	if err != nil {
+		return err
+	}
+
 	userCount, err := r.Count()
 	if err != nil {
 		return err
@@ -253,3 +283,75 @@ func (r *User) UserApikeyFindAllByUserID(userID uint) ([]model.UserApikey, error
 	}
 	return items, result.Error
 }
+
+func (r *User) UserCalendarFeedFindAllByUserID(userID uint) ([]model.UserCalendarFeed, error) {
+	var items []model.UserCalendarFeed
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Find(&items, "user_id = ?", userID)
+	return items, result.Error
+}
+
+func (r User) UserCalendarFeedFindById(id uint) (model.UserCalendarFeed, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.UserCalendarFeed{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.UserCalendarFeed
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.UserCalendarFeed{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.UserCalendarFeed{}, ErrUserCalendarFeedNotFound
+	}
+	return item, result.Error
+}
+
+func (r User) UserCalendarFeedFindByToken(token string) (model.UserCalendarFeed, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.UserCalendarFeed{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.UserCalendarFeed
+	result := db.Preload(clause.Associations).Find(&item, "token = ?", token)
+	if result.Error != nil {
+		return model.UserCalendarFeed{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.UserCalendarFeed{}, ErrUserCalendarFeedNotFound
+	}
+	return item, result.Error
+}
+
+func (r User) UserCalendarFeedInsert(item *model.UserCalendarFeed) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r User) UserCalendarFeedDelete(item *model.UserCalendarFeed) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Delete(item)
+	return result.Error
+}
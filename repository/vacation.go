@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+)
+
+type VacationRepository interface {
+	VacationAdjustmentFindByUserId(userId uint) ([]model.VacationAdjustment, error)
+	VacationAdjustmentFindById(id uint) (model.VacationAdjustment, error)
+	VacationAdjustmentInsert(item *model.VacationAdjustment) error
+	VacationAdjustmentDelete(item *model.VacationAdjustment) error
+}
+
+var _ VacationRepository = (*Vacation)(nil)
+
+type Vacation struct {
+	env *core.Environment
+}
+
+func NewVacation(env *core.Environment) *Vacation {
+	return &Vacation{
+		env: env,
+	}
+}
+
+func (r *Vacation) Migrate() error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	return db.AutoMigrate(&model.VacationAdjustment{})
+}
+
+var ErrVacationAdjustmentNotFound = errors.New("VacationAdjustment not found")
+
+func (r Vacation) VacationAdjustmentFindByUserId(userId uint) ([]model.VacationAdjustment, error) {
+	var items []model.VacationAdjustment
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Order("year, created_at").Find(&items, "user_id = ?", userId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r Vacation) VacationAdjustmentFindById(id uint) (model.VacationAdjustment, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.VacationAdjustment{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.VacationAdjustment
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.VacationAdjustment{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.VacationAdjustment{}, ErrVacationAdjustmentNotFound
+	}
+	return item, result.Error
+}
+
+func (r Vacation) VacationAdjustmentInsert(item *model.VacationAdjustment) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r Vacation) VacationAdjustmentDelete(item *model.VacationAdjustment) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Delete(item)
+	return result.Error
+}
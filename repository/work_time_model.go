@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"gorm.io/gorm/clause"
+)
+
+type WorkTimeModelRepository interface {
+	WorkTimeModelFindAll() ([]model.WorkTimeModel, error)
+	WorkTimeModelFindById(id uint) (model.WorkTimeModel, error)
+	WorkTimeModelFindByName(name string) (model.WorkTimeModel, error)
+	WorkTimeModelInsert(item *model.WorkTimeModel) error
+	WorkTimeModelUpdate(item *model.WorkTimeModel) error
+	WorkTimeModelDelete(item *model.WorkTimeModel) error
+	WorkTimeModelIsAssigned(workTimeModelId uint) (bool, error)
+	UserWorkTimeModelFindById(id uint) (model.UserWorkTimeModel, error)
+	UserWorkTimeModelFindByUserId(userId uint) (model.UserWorkTimeModels, error)
+	UserWorkTimeModelInsert(item *model.UserWorkTimeModel) error
+	UserWorkTimeModelUpdate(item *model.UserWorkTimeModel) error
+	UserWorkTimeModelDelete(item *model.UserWorkTimeModel) error
+}
+
+var _ WorkTimeModelRepository = (*WorkTimeModel)(nil)
+
+type WorkTimeModel struct {
+	env *core.Environment
+}
+
+func NewWorkTimeModel(env *core.Environment) *WorkTimeModel {
+	return &WorkTimeModel{
+		env: env,
+	}
+}
+
+func (r *WorkTimeModel) Migrate() error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	err = db.AutoMigrate(&model.WorkTimeModel{}, &model.UserWorkTimeModel{})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.WorkTimeModelFindByName(model.DEFAULT_WORK_TIME_MODEL_NAME)
+	if err != nil {
+		if err != ErrWorkTimeModelNotFound {
+			return err
+		}
+
+		defaultWorkTimeModel := model.DefaultWorkTimeModel()
+		err = r.WorkTimeModelInsert(&defaultWorkTimeModel)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var ErrWorkTimeModelNotFound = errors.New("WorkTimeModel not found")
+
+func (r WorkTimeModel) WorkTimeModelFindAll() ([]model.WorkTimeModel, error) {
+	var items []model.WorkTimeModel
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Find(&items)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelFindById(id uint) (model.WorkTimeModel, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.WorkTimeModel{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.WorkTimeModel
+	result := db.Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.WorkTimeModel{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.WorkTimeModel{}, ErrWorkTimeModelNotFound
+	}
+	return item, result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelFindByName(name string) (model.WorkTimeModel, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.WorkTimeModel{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.WorkTimeModel
+	result := db.Find(&item, "name = ?", name)
+	if result.Error != nil {
+		return model.WorkTimeModel{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.WorkTimeModel{}, ErrWorkTimeModelNotFound
+	}
+	return item, result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelInsert(item *model.WorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelUpdate(item *model.WorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Select("*").Omit("created_at").Updates(item)
+	return result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelDelete(item *model.WorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Delete(item)
+	return result.Error
+}
+
+func (r WorkTimeModel) WorkTimeModelIsAssigned(workTimeModelId uint) (bool, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return false, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var count int64
+	result := db.Model(&model.UserWorkTimeModel{}).Where("work_time_model_id = ?", workTimeModelId).Count(&count)
+	return count > 0, result.Error
+}
+
+var ErrUserWorkTimeModelNotFound = errors.New("UserWorkTimeModel not found")
+
+func (r WorkTimeModel) UserWorkTimeModelFindById(id uint) (model.UserWorkTimeModel, error) {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return model.UserWorkTimeModel{}, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	var item model.UserWorkTimeModel
+	result := db.Preload("WorkTimeModel").Find(&item, "id = ?", id)
+	if result.Error != nil {
+		return model.UserWorkTimeModel{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.UserWorkTimeModel{}, ErrUserWorkTimeModelNotFound
+	}
+	return item, result.Error
+}
+
+func (r WorkTimeModel) UserWorkTimeModelFindByUserId(userId uint) (model.UserWorkTimeModels, error) {
+	var items model.UserWorkTimeModels
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return items, err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Preload(clause.Associations).Order("valid_from").Find(&items, "user_id = ?", userId)
+	if result.Error != nil {
+		return items, result.Error
+	}
+	return items, result.Error
+}
+
+func (r WorkTimeModel) UserWorkTimeModelInsert(item *model.UserWorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Create(item)
+	return result.Error
+}
+
+func (r WorkTimeModel) UserWorkTimeModelUpdate(item *model.UserWorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Updates(item)
+	return result.Error
+}
+
+func (r WorkTimeModel) UserWorkTimeModelDelete(item *model.UserWorkTimeModel) error {
+	db, err := r.env.DatabaseManager.GetConnection()
+	if err != nil {
+		return err
+	}
+	defer r.env.DatabaseManager.CloseConnection(db)
+
+	result := db.Unscoped().Delete(item)
+	return result.Error
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+type AutoCheckout struct {
+	env           *core.Environment
+	user          repository.UserRepository
+	timestamp     repository.TimestampRepository
+	settings      repository.SettingsRepository
+	holiday       repository.HolidayRepository
+	workTimeModel repository.WorkTimeModelRepository
+	monthClosing  repository.MonthClosingRepository
+	outbox        *Outbox
+}
+
+func NewAutoCheckout(env *core.Environment, user repository.UserRepository, timestamp repository.TimestampRepository, settings repository.SettingsRepository, holiday repository.HolidayRepository, workTimeModel repository.WorkTimeModelRepository, monthClosing repository.MonthClosingRepository, outbox *Outbox) *AutoCheckout {
+	return &AutoCheckout{
+		env:           env,
+		user:          user,
+		timestamp:     timestamp,
+		settings:      settings,
+		holiday:       holiday,
+		workTimeModel: workTimeModel,
+		monthClosing:  monthClosing,
+		outbox:        outbox,
+	}
+}
+
+func (w *AutoCheckout) Run() {
+	w.checkoutOpenTimestampsLogged()
+
+	for range time.Tick(time.Minute * 15) {
+		w.checkoutOpenTimestampsLogged()
+	}
+}
+
+func (w *AutoCheckout) checkoutOpenTimestampsLogged() {
+	err := w.CheckoutOpenTimestamps()
+	if err != nil {
+		log.Printf("Auto Checkout: %s", err)
+	}
+}
+
+// CheckoutOpenTimestamps closes all timestamps which are open longer than
+// the configured maximum. They are marked for correction, so the user has
+// to enter the real checkout. Timestamps in closed months are left untouched
+// and a failing timestamp doesn't stop the others.
+func (w *AutoCheckout) CheckoutOpenTimestamps() error {
+	settings, err := w.settings.SettingsFind()
+	if err != nil {
+		return err
+	}
+
+	maxHours := time.Duration(settings.TimestampMaxHoursBetweenCheckInCheckOut) * time.Hour
+	timestamps, err := w.timestamp.FindOpenComingBefore(time.Now().Add(-maxHours))
+	if err != nil {
+		return err
+	}
+
+	for _, timestamp := range timestamps {
+		err = w.checkout(settings, timestamp)
+		if err != nil {
+			log.Printf("Auto Checkout: timestamp %d of user %d: %s", timestamp.ID, timestamp.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *AutoCheckout) checkout(settings model.Settings, timestamp model.Timestamp) error {
+	closed, err := w.monthClosing.IsMonthClosed(timestamp.UserID, timestamp.ComingTimestamp.Year(), int(timestamp.ComingTimestamp.Month()))
+	if err != nil {
+		return err
+	}
+
+	if closed {
+		log.Printf("Auto Checkout: timestamp %d of user %d is in a closed month, skipped", timestamp.ID, timestamp.UserID)
+		return nil
+	}
+
+	goingTimestamp, err := w.getFallbackGoingTimestamp(settings, timestamp)
+	if err != nil {
+		return err
+	}
+
+	openBreak := timestamp.GetOpenBreak()
+	if openBreak != nil {
+		openBreak.BreakEnd = goingTimestamp
+		if openBreak.BreakEnd.Before(openBreak.BreakStart) {
+			openBreak.BreakEnd = openBreak.BreakStart
+		}
+
+		err = w.timestamp.TimestampBreakUpdate(openBreak)
+		if err != nil {
+			return err
+		}
+	}
+
+	correctionReason := model.TIMESTAMP_CORRECTION_REASON_AUTO_CHECKOUT
+	timestamp.GoingTimestamp = goingTimestamp
+	timestamp.IsHomeofficeGoing = timestamp.IsHomeoffice
+	timestamp.NeedsCorrection = true
+	timestamp.CorrectionReason = &correctionReason
+
+	err = w.timestamp.Update(nil, &timestamp)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Auto Checkout: timestamp %d of user %d closed at %s", timestamp.ID, timestamp.UserID, goingTimestamp)
+
+	err = w.notifyUser(timestamp)
+	if err != nil {
+		log.Printf("Auto Checkout: notify failed: %s", err)
+	}
+
+	return nil
+}
+
+func (w *AutoCheckout) getFallbackGoingTimestamp(settings model.Settings, timestamp model.Timestamp) (time.Time, error) {
+	switch settings.TimestampAutoCheckoutFallback {
+	case model.AUTO_CHECKOUT_FALLBACK_CHECKIN:
+		return timestamp.ComingTimestamp, nil
+	case model.AUTO_CHECKOUT_FALLBACK_MAX_HOURS:
+		return timestamp.ComingTimestamp.Add(time.Duration(settings.TimestampMaxHoursBetweenCheckInCheckOut) * time.Hour), nil
+	}
+
+	day := helper.GetDayDate(timestamp.ComingTimestamp)
+	holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(timestamp.UserID, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	workTimeModels, err := w.workTimeModel.UserWorkTimeModelFindByUserId(timestamp.UserID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	plannedHours := workTimeModels.GetWorkingHoursForDay(timestamp.ComingTimestamp, holidays)
+
+	return timestamp.ComingTimestamp.Add(time.Duration(plannedHours * float64(time.Hour))), nil
+}
+
+// notifyUser mails the user, the timestamp is personal and not shared in the
+// Teams channel.
+func (w *AutoCheckout) notifyUser(timestamp model.Timestamp) error {
+	user, err := w.user.FindByID(timestamp.UserID)
+	if err != nil {
+		return err
+	}
+
+	return w.outbox.EnqueueUserNotification(nil, model.OutboxUserNotificationPayload{
+		Username: user.Username,
+		Subject:  "Automatischer Checkout",
+		Text: fmt.Sprintf("Hallo %s,\n\ndein Zeitstempel wurde automatisch beendet.\n\nKommen: %s\nGehen: %s\n\nBitte korrigiere deinen Zeitstempel.",
+			user.FirstName,
+			timestamp.ComingTimestamp.Format("02.01.2006 15:04"),
+			timestamp.GoingTimestamp.Format("02.01.2006 15:04"),
+		),
+	})
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+)
+
+func TestAutoCheckoutCheckoutOpenTimestamps(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	settingsRepo := memory.NewSettings(db)
+	holidayRepo := memory.NewHoliday(db)
+	workTimeModelRepo := memory.NewWorkTimeModel(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+	outbox := NewOutbox(nil, memory.NewOutbox(db), nil)
+
+	autoCheckout := NewAutoCheckout(nil, userRepo, timestampRepo, settingsRepo, holidayRepo, workTimeModelRepo, monthClosingRepo, outbox)
+
+	user := model.User{Username: "checkout"}
+	err := userRepo.Insert(&user)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	// monday of a closed month and a monday of an open month
+	closedComing := time.Date(2024, 3, 4, 8, 0, 0, 0, time.Local)
+	openComing := time.Date(2024, 4, 8, 8, 0, 0, 0, time.Local)
+
+	err = monthClosingRepo.MonthClosingInsert(&model.MonthClosing{UserID: &user.ID, Year: 2024, Month: 3})
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	closedTimestamp := model.Timestamp{UserID: user.ID, ComingTimestamp: closedComing}
+	openTimestamp := model.Timestamp{UserID: user.ID, ComingTimestamp: openComing}
+	for _, timestamp := range []*model.Timestamp{&closedTimestamp, &openTimestamp} {
+		err = timestampRepo.Insert(timestamp)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	err = autoCheckout.CheckoutOpenTimestamps()
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name      string
+		ID        uint
+		WantGoing time.Time
+	}{
+		{Name: "closed month", ID: closedTimestamp.ID},
+		{Name: "open month", ID: openTimestamp.ID, WantGoing: openComing.Add(8 * time.Hour)},
+	}
+
+	for _, test := range testData {
+		timestamp, err := timestampRepo.FindByID(test.ID)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		if !timestamp.GoingTimestamp.Equal(test.WantGoing) {
+			t.Errorf("%s: want going %s, got %s", test.Name, test.WantGoing, timestamp.GoingTimestamp)
+		}
+
+		if timestamp.NeedsCorrection != !test.WantGoing.IsZero() {
+			t.Errorf("%s: want needs correction %t, got %t", test.Name, !test.WantGoing.IsZero(), timestamp.NeedsCorrection)
+		}
+	}
+}
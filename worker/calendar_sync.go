@@ -0,0 +1,559 @@
+package worker
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/calendar"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+const (
+	CALENDAR_SYNC_INTERVAL    = 15 * time.Minute
+	CALENDAR_SYNC_PAST_DAYS   = 30
+	CALENDAR_SYNC_FUTURE_DAYS = 365
+)
+
+// CalendarSync reconciles the external events of absences and external works
+// with the calendars of the active providers. Changes are synchronised by
+// outbox jobs, the periodic run repairs everything the jobs missed within the
+// sync window.
+type CalendarSync struct {
+	env          *core.Environment
+	user         repository.UserRepository
+	absence      repository.AbsenceRepository
+	externalWork repository.ExternalWorkRepository
+	providers    []calendar.Provider
+}
+
+func NewCalendarSync(env *core.Environment, user repository.UserRepository, absence repository.AbsenceRepository, externalWork repository.ExternalWorkRepository, providers []calendar.Provider) *CalendarSync {
+	return &CalendarSync{
+		env:          env,
+		user:         user,
+		absence:      absence,
+		externalWork: externalWork,
+		providers:    providers,
+	}
+}
+
+func (w *CalendarSync) Run() {
+	w.syncLogged()
+
+	for range time.Tick(CALENDAR_SYNC_INTERVAL) {
+		w.syncLogged()
+	}
+}
+
+func (w *CalendarSync) syncLogged() {
+	err := w.Sync()
+	if err != nil {
+		log.Printf("Calendar Sync: %s", err)
+	}
+}
+
+func (w *CalendarSync) getProvider(name model.ExternalEventProvider) calendar.Provider {
+	for _, provider := range w.providers {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+// calendarEntries loads the entries of the sync window once per user, they
+// are indexed by their id.
+type calendarEntries struct {
+	provider calendar.Provider
+	from     time.Time
+	till     time.Time
+	entries  map[string]map[string]model.CalendarEntry
+	errors   map[string]error
+}
+
+func newCalendarEntries(provider calendar.Provider, from time.Time, till time.Time) *calendarEntries {
+	return &calendarEntries{
+		provider: provider,
+		from:     from,
+		till:     till,
+		entries:  map[string]map[string]model.CalendarEntry{},
+		errors:   map[string]error{},
+	}
+}
+
+func (c *calendarEntries) get(username string) (map[string]model.CalendarEntry, error) {
+	if entries, exists := c.entries[username]; exists {
+		return entries, c.errors[username]
+	}
+
+	entries := map[string]model.CalendarEntry{}
+	found, err := c.provider.FindEntries(username, c.from, c.till)
+	for _, entry := range found {
+		entries[entry.ID] = entry
+	}
+
+	c.entries[username] = entries
+	c.errors[username] = err
+	return entries, err
+}
+
+// isInWindow returns true if the range overlaps the sync window.
+func (c *calendarEntries) isInWindow(from time.Time, till time.Time) bool {
+	return !till.Before(c.from) && !from.After(c.till)
+}
+
+func (w *CalendarSync) Sync() error {
+	now := time.Now()
+	since := helper.GetDayDate(now).AddDate(0, 0, -CALENDAR_SYNC_PAST_DAYS)
+
+	for _, provider := range w.providers {
+		err := w.addMissingEvents(provider, since)
+		if err != nil {
+			return err
+		}
+
+		entries := newCalendarEntries(provider, since, now.AddDate(0, 0, CALENDAR_SYNC_FUTURE_DAYS))
+
+		err = w.syncAbsenceEvents(provider, entries, now)
+		if err != nil {
+			return err
+		}
+
+		err = w.syncExternalWorkEvents(provider, entries, now)
+		if err != nil {
+			return err
+		}
+
+		err = w.removeOrphanedEvents(provider, entries)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addMissingEvents stores pending events, they are created in the calendar by
+// the following synchronisation.
+func (w *CalendarSync) addMissingEvents(provider calendar.Provider, since time.Time) error {
+	absences, err := w.absence.FindWithoutExternalEvent(provider.Name(), since)
+	if err != nil {
+		return err
+	}
+
+	for _, absence := range absences {
+		err = w.absence.AbsenceExternalEventInsert(&model.AbsenceExternalEvent{
+			AbsenceID:             absence.ID,
+			ExternalEventProvider: provider.Name(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	externalWorks, err := w.externalWork.ExternalWorkFindWithoutExternalEvent(provider.Name(), since)
+	if err != nil {
+		return err
+	}
+
+	for _, externalWork := range externalWorks {
+		err = w.externalWork.ExternalWorkExternalEventInsert(&model.ExternalWorkExternalEvent{
+			ExternalWorkID:        externalWork.ID,
+			ExternalEventProvider: provider.Name(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *CalendarSync) syncAbsenceEvents(provider calendar.Provider, entries *calendarEntries, now time.Time) error {
+	externalEvents, err := w.absence.AbsenceExternalEventFindByProvider(provider.Name())
+	if err != nil {
+		return err
+	}
+
+	for _, externalEvent := range externalEvents {
+		absence := externalEvent.Absence
+		if !externalEvent.IsSyncDue(now) {
+			continue
+		}
+
+		var removed bool
+		changed := true
+		if isAbsenceRemoved(&absence) {
+			removed, err = w.syncAbsenceEvent(provider, &externalEvent)
+		} else if entries.isInWindow(absence.AbsenceFrom, absence.AbsenceTill) {
+			changed, err = w.reconcileEvent(entries, &externalEvent.ExternalEventSync, &externalEvent.ExternalEventID, &externalEvent.Update, absence.User.Username, absence.UpdatedAt, absence.GetCalendarEntry())
+		} else {
+			changed = false
+		}
+
+		if removed || !changed {
+			continue
+		}
+
+		if err != nil {
+			log.Printf("Calendar Sync: %s: absence %d: %s", provider.Name(), absence.ID, err)
+			externalEvent.SyncFailed(err, now)
+		} else {
+			externalEvent.SyncSucceeded(time.Now())
+		}
+
+		err = w.absence.AbsenceExternalEventUpdate(&externalEvent)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SyncAbsence synchronises the events of a single absence, it is called for
+// the outbox jobs of the absence.
+func (w *CalendarSync) SyncAbsence(absenceId uint) error {
+	for _, provider := range w.providers {
+		externalEvents, err := w.absence.AbsenceExternalEventFindByProviderAndAbsenceId(provider.Name(), absenceId)
+		if err != nil {
+			return err
+		}
+
+		if len(externalEvents) == 0 {
+			absence, err := w.absence.FindByID(absenceId)
+			if errors.Is(err, repository.ErrAbsenceNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if isAbsenceRemoved(&absence) {
+				continue
+			}
+
+			err = w.absence.AbsenceExternalEventInsert(&model.AbsenceExternalEvent{
+				AbsenceID:             absenceId,
+				ExternalEventProvider: provider.Name(),
+			})
+			if err != nil {
+				return err
+			}
+
+			externalEvents, err = w.absence.AbsenceExternalEventFindByProviderAndAbsenceId(provider.Name(), absenceId)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, externalEvent := range externalEvents {
+			removed, err := w.syncAbsenceEvent(provider, &externalEvent)
+			if err != nil {
+				return err
+			}
+
+			if removed {
+				continue
+			}
+
+			externalEvent.SyncSucceeded(time.Now())
+			err = w.absence.AbsenceExternalEventUpdate(&externalEvent)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncAbsenceEvent creates or updates the event of the absence, events of
+// removed absences are deleted together with their row.
+func (w *CalendarSync) syncAbsenceEvent(provider calendar.Provider, externalEvent *model.AbsenceExternalEvent) (bool, error) {
+	absence := externalEvent.Absence
+
+	if !isAbsenceRemoved(&absence) {
+		return false, w.syncEvent(provider, &externalEvent.ExternalEventID, &externalEvent.Update, absence.User.Username, absence.GetCalendarEntry())
+	}
+
+	// rows of absences which were deleted permanently don't know the user, their
+	// events are removed with the orphaned events
+	if externalEvent.ExternalEventID != "" && absence.User != nil {
+		err := provider.DeleteEntry(absence.User.Username, externalEvent.ExternalEventID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, w.absence.AbsenceExternalEventDelete(nil, externalEvent)
+}
+
+// isAbsenceRemoved returns true for deleted and declined absences, absences
+// which were deleted permanently are preloaded empty.
+func isAbsenceRemoved(absence *model.Absence) bool {
+	return absence.ID == 0 || absence.DeletedAt.Valid || (absence.SignedStatus != nil && *absence.SignedStatus == model.SIGNED_STATUS_DECLINED)
+}
+
+func (w *CalendarSync) syncExternalWorkEvents(provider calendar.Provider, entries *calendarEntries, now time.Time) error {
+	externalEvents, err := w.externalWork.ExternalWorkExternalEventFindByProvider(provider.Name())
+	if err != nil {
+		return err
+	}
+
+	for _, externalEvent := range externalEvents {
+		externalWork := externalEvent.ExternalWork
+		if !externalEvent.IsSyncDue(now) {
+			continue
+		}
+
+		var removed bool
+		changed := true
+		if externalWork.DeletedAt.Valid {
+			removed, err = w.syncExternalWorkEvent(provider, &externalEvent)
+		} else if entries.isInWindow(externalWork.From, externalWork.Till) {
+			changed, err = w.reconcileEvent(entries, &externalEvent.ExternalEventSync, &externalEvent.ExternalEventID, &externalEvent.Update, externalWork.User.Username, externalWork.UpdatedAt, externalWork.GetCalendarEntry())
+		} else {
+			changed = false
+		}
+
+		if removed || !changed {
+			continue
+		}
+
+		if err != nil {
+			log.Printf("Calendar Sync: %s: external work %d: %s", provider.Name(), externalWork.ID, err)
+			externalEvent.SyncFailed(err, now)
+		} else {
+			externalEvent.SyncSucceeded(time.Now())
+		}
+
+		err = w.externalWork.ExternalWorkExternalEventUpdate(&externalEvent)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SyncExternalWork synchronises the events of a single external work, it is
+// called for the outbox jobs of the external work.
+func (w *CalendarSync) SyncExternalWork(externalWorkId uint) error {
+	for _, provider := range w.providers {
+		externalEvents, err := w.externalWork.ExternalWorkExternalEventFindByProviderAndExternalWorkId(provider.Name(), externalWorkId)
+		if err != nil {
+			return err
+		}
+
+		if len(externalEvents) == 0 {
+			_, err := w.externalWork.ExternalWorkFindById(externalWorkId, false)
+			if err != nil {
+				if errors.Is(err, repository.ErrExternalWorkNotFound) {
+					continue
+				}
+				return err
+			}
+
+			err = w.externalWork.ExternalWorkExternalEventInsert(&model.ExternalWorkExternalEvent{
+				ExternalWorkID:        externalWorkId,
+				ExternalEventProvider: provider.Name(),
+			})
+			if err != nil {
+				return err
+			}
+
+			externalEvents, err = w.externalWork.ExternalWorkExternalEventFindByProviderAndExternalWorkId(provider.Name(), externalWorkId)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, externalEvent := range externalEvents {
+			removed, err := w.syncExternalWorkEvent(provider, &externalEvent)
+			if err != nil {
+				return err
+			}
+
+			if removed {
+				continue
+			}
+
+			externalEvent.SyncSucceeded(time.Now())
+			err = w.externalWork.ExternalWorkExternalEventUpdate(&externalEvent)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *CalendarSync) syncExternalWorkEvent(provider calendar.Provider, externalEvent *model.ExternalWorkExternalEvent) (bool, error) {
+	externalWork := externalEvent.ExternalWork
+
+	if !externalWork.DeletedAt.Valid {
+		return false, w.syncEvent(provider, &externalEvent.ExternalEventID, &externalEvent.Update, externalWork.User.Username, externalWork.GetCalendarEntry())
+	}
+
+	if externalEvent.ExternalEventID != "" {
+		err := provider.DeleteEntry(externalWork.User.Username, externalEvent.ExternalEventID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, w.externalWork.ExternalWorkExternalEventDelete(externalEvent)
+}
+
+// DeleteEvent removes an event whose row is already gone, e.g. of a deleted
+// absence. Events of inactive providers are skipped.
+func (w *CalendarSync) DeleteEvent(providerName model.ExternalEventProvider, username string, externalEventId string) error {
+	provider := w.getProvider(providerName)
+	if provider == nil {
+		return nil
+	}
+
+	return provider.DeleteEntry(username, externalEventId)
+}
+
+// syncEvent creates the event if it doesn't exist in the calendar anymore and
+// updates it if it differs from the expected entry or is marked for update.
+func (w *CalendarSync) syncEvent(provider calendar.Provider, externalEventId *string, update *bool, username string, expected model.CalendarEntry) error {
+	if *externalEventId != "" {
+		entry, err := provider.GetEntry(username, *externalEventId)
+		if err != nil && !errors.Is(err, model.ErrCalendarEntryNotFound) {
+			return err
+		}
+
+		if err == nil {
+			if *update || !entry.Equals(expected) {
+				err = provider.UpdateEntry(username, *externalEventId, expected)
+				if err != nil {
+					return err
+				}
+			}
+
+			*update = false
+			return nil
+		}
+	}
+
+	eventId, err := provider.CreateEntry(username, expected)
+	if err != nil {
+		return err
+	}
+
+	*externalEventId = eventId
+	*update = false
+	return nil
+}
+
+// reconcileEvent compares the event with the entry loaded for the sync window
+// and only writes it if it was removed or differs. Events which didn't change
+// in the calendar and in BeeTimeClock since the last synchronisation are
+// skipped, false is returned for them.
+func (w *CalendarSync) reconcileEvent(entries *calendarEntries, sync *model.ExternalEventSync, externalEventId *string, update *bool, username string, updatedAt time.Time, expected model.CalendarEntry) (bool, error) {
+	userEntries, err := entries.get(username)
+	if err != nil {
+		return true, err
+	}
+
+	entry, exists := userEntries[*externalEventId]
+	if exists && !*update && !sync.IsChangedSince(updatedAt, entry.Modified) {
+		return false, nil
+	}
+
+	if exists && !*update && entry.Equals(expected) {
+		return true, nil
+	}
+
+	if *externalEventId != "" {
+		// the entry may be missing in the window because it has no id property yet
+		err = entries.provider.UpdateEntry(username, *externalEventId, expected)
+		if err == nil {
+			*update = false
+			return true, nil
+		}
+
+		if !errors.Is(err, model.ErrCalendarEntryNotFound) {
+			return true, err
+		}
+	}
+
+	eventId, err := entries.provider.CreateEntry(username, expected)
+	if err != nil {
+		return true, err
+	}
+
+	*externalEventId = eventId
+	*update = false
+	return true, nil
+}
+
+// removeOrphanedEvents deletes events created by BeeTimeClock which are not
+// linked anymore, e.g. of deleted absences. The events are matched by their id
+// and their CALENDAR_ENTRY_ID_PROPERTY, events changed after the snapshot of
+// the linked events may belong to a running synchronisation and are kept.
+// Rows of absences which were deleted permanently don't link their events.
+func (w *CalendarSync) removeOrphanedEvents(provider calendar.Provider, entries *calendarEntries) error {
+	snapshot := time.Now()
+	knownIds := map[string]bool{}
+
+	absenceEvents, err := w.absence.AbsenceExternalEventFindByProvider(provider.Name())
+	if err != nil {
+		return err
+	}
+	for _, externalEvent := range absenceEvents {
+		if externalEvent.Absence.ID == 0 {
+			continue
+		}
+
+		knownIds[externalEvent.ExternalEventID] = true
+		knownIds[externalEvent.Absence.Identifier.String()] = true
+	}
+
+	externalWorkEvents, err := w.externalWork.ExternalWorkExternalEventFindByProvider(provider.Name())
+	if err != nil {
+		return err
+	}
+	for _, externalEvent := range externalWorkEvents {
+		knownIds[externalEvent.ExternalEventID] = true
+		knownIds[externalEvent.ExternalWork.Identifier.String()] = true
+	}
+
+	users, err := w.user.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		userEntries, err := entries.get(user.Username)
+		if err != nil {
+			log.Printf("Calendar Sync: %s: user %s: %s", provider.Name(), user.Username, err)
+			continue
+		}
+
+		for _, entry := range userEntries {
+			if !entry.IsCreatedByBeeTimeClock() || knownIds[entry.ID] || (entry.BeeTimeClockID != "" && knownIds[entry.BeeTimeClockID]) {
+				continue
+			}
+
+			if entry.Modified.IsZero() || entry.Modified.After(snapshot) {
+				continue
+			}
+
+			err = provider.DeleteEntry(user.Username, entry.ID)
+			if err != nil {
+				log.Printf("Calendar Sync: %s: user %s: %s", provider.Name(), user.Username, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package worker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/calendar"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+	"github.com/google/uuid"
+)
+
+// testProvider keeps the entries in memory and records the changed ids.
+type testProvider struct {
+	entries []model.CalendarEntry
+	created []string
+	got     []string
+	updated []string
+	deleted []string
+}
+
+var _ calendar.Provider = (*testProvider)(nil)
+
+func (p *testProvider) Name() model.ExternalEventProvider {
+	return model.EXTERNAL_EVENT_PROVIDER_CALDAV
+}
+
+func (p *testProvider) IsConnected() bool {
+	return true
+}
+
+func (p *testProvider) CreateEntry(username string, entry model.CalendarEntry) (string, error) {
+	entry.ID = uuid.New().String()
+	entry.BeeTimeClockID = entry.Identifier
+	entry.Modified = time.Now()
+	p.entries = append(p.entries, entry)
+	p.created = append(p.created, entry.Identifier)
+	return entry.ID, nil
+}
+
+func (p *testProvider) GetEntry(username string, externalEventId string) (model.CalendarEntry, error) {
+	p.got = append(p.got, externalEventId)
+	for _, entry := range p.entries {
+		if entry.ID == externalEventId {
+			return entry, nil
+		}
+	}
+
+	return model.CalendarEntry{}, model.ErrCalendarEntryNotFound
+}
+
+func (p *testProvider) UpdateEntry(username string, externalEventId string, entry model.CalendarEntry) error {
+	p.updated = append(p.updated, externalEventId)
+	return nil
+}
+
+func (p *testProvider) DeleteEntry(username string, externalEventId string) error {
+	p.deleted = append(p.deleted, externalEventId)
+	return nil
+}
+
+func (p *testProvider) FindEntries(username string, from time.Time, till time.Time) ([]model.CalendarEntry, error) {
+	return p.entries, nil
+}
+
+func TestCalendarSyncRemoveOrphanedEvents(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	absenceRepo := memory.NewAbsence(db)
+	externalWorkRepo := memory.NewExternalWork(db)
+	provider := &testProvider{}
+
+	calendarSync := NewCalendarSync(nil, userRepo, absenceRepo, externalWorkRepo, []calendar.Provider{provider})
+
+	user := model.User{Username: "calendar"}
+	err := userRepo.Insert(&user)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	linked := model.Absence{UserID: &user.ID, Identifier: uuid.New()}
+	creating := model.Absence{UserID: &user.ID, Identifier: uuid.New()}
+	for _, absence := range []*model.Absence{&linked, &creating} {
+		err = absenceRepo.Insert(nil, absence)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	// the event of creating is written to the calendar, but not yet stored, the
+	// absence of the last row was deleted permanently
+	externalEvents := []model.AbsenceExternalEvent{
+		{AbsenceID: linked.ID, ExternalEventID: "linked", ExternalEventProvider: provider.Name()},
+		{AbsenceID: creating.ID, ExternalEventProvider: provider.Name()},
+		{AbsenceID: creating.ID + 1, ExternalEventID: "deleted absence", ExternalEventProvider: provider.Name()},
+	}
+	for _, externalEvent := range externalEvents {
+		err = absenceRepo.AbsenceExternalEventInsert(&externalEvent)
+		if err != nil {
+			t.Fatalf("setup: want no error, got %s", err)
+		}
+	}
+
+	past := time.Now().Add(-time.Hour)
+	provider.entries = []model.CalendarEntry{
+		{ID: "linked", BeeTimeClockID: linked.Identifier.String(), Modified: past},
+		{ID: "creating", BeeTimeClockID: creating.Identifier.String(), Modified: past},
+		{ID: "orphaned", BeeTimeClockID: uuid.New().String(), Modified: past},
+		{ID: "created after snapshot", BeeTimeClockID: uuid.New().String(), Modified: time.Now().Add(time.Hour)},
+		{ID: "unknown modification", BeeTimeClockID: uuid.New().String()},
+		{ID: "legacy linked", Subject: model.CALENDAR_ENTRY_SUBJECT_PREFIX + "Urlaub", Modified: past},
+		{ID: "legacy orphaned", Subject: model.CALENDAR_ENTRY_SUBJECT_PREFIX + "Urlaub", Modified: past},
+		{ID: "deleted absence", Subject: model.CALENDAR_ENTRY_SUBJECT_PREFIX + "Urlaub", Modified: past},
+		{ID: "foreign", Subject: "Urlaub", Modified: past},
+	}
+	err = absenceRepo.AbsenceExternalEventInsert(&model.AbsenceExternalEvent{AbsenceID: linked.ID, ExternalEventID: "legacy linked", ExternalEventProvider: provider.Name()})
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	entries := newCalendarEntries(provider, past, time.Now())
+	err = calendarSync.syncAbsenceEvents(provider, entries, time.Now())
+	if err == nil {
+		err = calendarSync.removeOrphanedEvents(provider, entries)
+	}
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	wantDeleted := []string{"orphaned", "legacy orphaned", "deleted absence"}
+	for _, entry := range provider.entries {
+		want := slices.Contains(wantDeleted, entry.ID)
+		if got := slices.Contains(provider.deleted, entry.ID); got != want {
+			t.Errorf("%s: want deleted %t, got %t", entry.ID, want, got)
+		}
+	}
+
+	rows, err := absenceRepo.AbsenceExternalEventFindByAbsenceId(creating.ID + 1)
+	if err != nil || len(rows) != 0 {
+		t.Errorf("deleted absence: want the row removed, got %v (%v)", rows, err)
+	}
+}
+
+func TestCalendarSyncSync(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	absenceRepo := memory.NewAbsence(db)
+	externalWorkRepo := memory.NewExternalWork(db)
+	provider := &testProvider{}
+
+	calendarSync := NewCalendarSync(nil, userRepo, absenceRepo, externalWorkRepo, []calendar.Provider{provider})
+
+	user := model.User{Username: "calendar"}
+	err := userRepo.Insert(&user)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	today := time.Now()
+	newAbsence := func(days int) model.Absence {
+		from := today.AddDate(0, 0, days)
+		return model.Absence{UserID: &user.ID, Identifier: uuid.New(), AbsenceFrom: from, AbsenceTill: from}
+	}
+
+	testData := []struct {
+		Name        string
+		Absence     model.Absence
+		Synced      bool
+		Entry       bool
+		Modified    bool
+		WantCreated bool
+		WantUpdated bool
+	}{
+		{Name: "unchanged", Absence: newAbsence(1), Synced: true, Entry: true},
+		{Name: "changed in the calendar", Absence: newAbsence(2), Synced: true, Entry: true, Modified: true, WantUpdated: true},
+		{Name: "never synchronised", Absence: newAbsence(3), WantCreated: true},
+		{Name: "outside the window", Absence: newAbsence(-CALENDAR_SYNC_PAST_DAYS - 10), Synced: true},
+	}
+
+	for i := range testData {
+		test := &testData[i]
+		err = absenceRepo.Insert(nil, &test.Absence)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		externalEvent := model.AbsenceExternalEvent{AbsenceID: test.Absence.ID, ExternalEventProvider: provider.Name()}
+		if test.Synced {
+			externalEvent.ExternalEventID = test.Name
+			externalEvent.SyncSucceeded(time.Now())
+		}
+
+		err = absenceRepo.AbsenceExternalEventInsert(&externalEvent)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		if test.Entry {
+			entry := test.Absence.GetCalendarEntry()
+			entry.ID = test.Name
+			entry.BeeTimeClockID = entry.Identifier
+			entry.Modified = today.Add(-time.Hour)
+			if test.Modified {
+				entry.Subject = "moved by the user"
+				entry.Modified = time.Now().Add(time.Minute)
+			}
+			provider.entries = append(provider.entries, entry)
+		}
+	}
+
+	err = calendarSync.Sync()
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if len(provider.got) != 0 {
+		t.Errorf("want no single entry requests, got %v", provider.got)
+	}
+
+	for _, test := range testData {
+		if got := slices.Contains(provider.created, test.Absence.Identifier.String()); got != test.WantCreated {
+			t.Errorf("%s: want created %t, got %t", test.Name, test.WantCreated, got)
+		}
+		if got := slices.Contains(provider.updated, test.Name); got != test.WantUpdated {
+			t.Errorf("%s: want updated %t, got %t", test.Name, test.WantUpdated, got)
+		}
+	}
+}
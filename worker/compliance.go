@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+// COMPLIANCE_SUSPICIOUS_MONTHS is the number of months, including the current
+// one, whose violations are reported as suspicious timestamps.
+const COMPLIANCE_SUSPICIOUS_MONTHS = 2
+
+type Compliance struct {
+	env             *core.Environment
+	holiday         repository.HolidayRepository
+	timestampWorker *Timestamp
+}
+
+func NewCompliance(env *core.Environment, holiday repository.HolidayRepository, timestampWorker *Timestamp) *Compliance {
+	return &Compliance{
+		env:             env,
+		holiday:         holiday,
+		timestampWorker: timestampWorker,
+	}
+}
+
+// CheckMonth runs all compliance rules for the given month. The calculated
+// days of the previous months are loaded as history for the average and the
+// rest period at the start of the month.
+func (w *Compliance) CheckMonth(userID uint, year int, month int) ([]model.ComplianceViolation, error) {
+	firstOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+
+	input, err := w.getInput(userID, firstOfMonth, firstOfMonth, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return model.CheckCompliance(input), nil
+}
+
+// FindSuspiciousTimestamps runs all compliance rules for the recent months and
+// returns the timestamps of the violations.
+func (w *Compliance) FindSuspiciousTimestamps(userID uint, now time.Time) ([]model.TimestampSuspiciousResponse, error) {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	firstMonth := currentMonth.AddDate(0, -(COMPLIANCE_SUSPICIOUS_MONTHS - 1), 0)
+
+	input, err := w.getInput(userID, firstMonth, currentMonth, now)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []model.ComplianceViolation{}
+	for month := firstMonth; !month.After(currentMonth); month = month.AddDate(0, 1, 0) {
+		input.From = month
+		input.Till = month.AddDate(0, 1, -1)
+		violations = append(violations, model.CheckCompliance(input)...)
+	}
+
+	slices.SortStableFunc(violations, func(a, b model.ComplianceViolation) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return model.GetComplianceSuspiciousTimestamps(input.TimestampGroups, violations), nil
+}
+
+// getInput loads the calculated days from the months before firstMonth, which
+// are needed as history, till the end of lastMonth.
+func (w *Compliance) getInput(userID uint, firstMonth time.Time, lastMonth time.Time, now time.Time) (model.ComplianceInput, error) {
+	lastOfMonth := lastMonth.AddDate(0, 1, -1)
+	firstOfPeriod := firstMonth.AddDate(0, -(model.COMPLIANCE_AVERAGE_PERIOD_MONTH - 1), 0)
+
+	holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(userID, firstOfPeriod, lastOfMonth)
+	if err != nil {
+		return model.ComplianceInput{}, err
+	}
+
+	input := model.ComplianceInput{
+		From:            firstMonth,
+		Till:            lastOfMonth,
+		Now:             now,
+		TimestampGroups: []model.TimestampGroup{},
+		Holidays:        holidays,
+	}
+
+	for current := firstOfPeriod; !current.After(lastMonth); current = current.AddDate(0, 1, 0) {
+		calculated, err := w.timestampWorker.CalculateMonth(userID, current.Year(), int(current.Month()))
+		if err != nil {
+			return model.ComplianceInput{}, err
+		}
+
+		input.TimestampGroups = append(input.TimestampGroups, calculated.TimestampGroups...)
+	}
+
+	return input, nil
+}
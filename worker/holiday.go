@@ -0,0 +1,302 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+const (
+	HOLIDAY_SOURCE_BUILTIN = "builtin"
+	HOLIDAY_SOURCE_API     = "api"
+)
+
+// Holiday keeps the public and custom holidays of every location in sync and
+// recalculates the absences and overtime affected by changes.
+type Holiday struct {
+	env            *core.Environment
+	holiday        repository.HolidayRepository
+	location       repository.LocationRepository
+	absence        repository.AbsenceRepository
+	workTimeModel  repository.WorkTimeModelRepository
+	overtime       repository.OvertimeRepository
+	monthClosing   repository.MonthClosingRepository
+	overtimeWorker *Overtime
+}
+
+func NewHoliday(env *core.Environment, holiday repository.HolidayRepository, location repository.LocationRepository, absence repository.AbsenceRepository, workTimeModel repository.WorkTimeModelRepository, overtime repository.OvertimeRepository, monthClosing repository.MonthClosingRepository, overtimeWorker *Overtime) *Holiday {
+	return &Holiday{
+		env:            env,
+		holiday:        holiday,
+		location:       location,
+		absence:        absence,
+		workTimeModel:  workTimeModel,
+		overtime:       overtime,
+		monthClosing:   monthClosing,
+		overtimeWorker: overtimeWorker,
+	}
+}
+
+// Run syncs the current and the next year, so the holidays are known when
+// the vacation is planned.
+func (w *Holiday) Run() {
+	w.syncLogged()
+
+	for range time.Tick(time.Hour * 24) {
+		w.syncLogged()
+	}
+}
+
+func (w *Holiday) syncLogged() {
+	year := time.Now().Year()
+
+	result, err := w.Sync(year, year+1)
+	if err != nil {
+		log.Printf("Holiday Sync: %s", err)
+		return
+	}
+
+	if result.HasChanges() {
+		log.Printf("Holiday Sync: %d-%d: %d added, %d updated, %d removed", result.FromYear, result.TillYear,
+			len(result.Added), len(result.Updated), len(result.Removed))
+	}
+}
+
+// Sync brings the holidays of all locations in the year range up to date.
+func (w *Holiday) Sync(fromYear int, tillYear int) (model.HolidaySyncResult, error) {
+	locations, err := w.location.LocationFindAll()
+	if err != nil {
+		return model.HolidaySyncResult{}, err
+	}
+
+	return w.sync(locations, fromYear, tillYear)
+}
+
+func (w *Holiday) SyncLocation(location model.Location, fromYear int, tillYear int) (model.HolidaySyncResult, error) {
+	return w.sync([]model.Location{location}, fromYear, tillYear)
+}
+
+func (w *Holiday) sync(locations []model.Location, fromYear int, tillYear int) (model.HolidaySyncResult, error) {
+	result := model.NewHolidaySyncResult(fromYear, tillYear)
+
+	customHolidays, err := w.holiday.HolidayCustomFindAll()
+	if err != nil {
+		return result, err
+	}
+
+	for _, location := range locations {
+		for year := fromYear; year <= tillYear; year++ {
+			err = w.syncLocationYear(&result, location, customHolidays, year)
+			if err != nil {
+				return result, fmt.Errorf("location %s: %w", location.Name, err)
+			}
+		}
+	}
+
+	err = w.recalculate(&result)
+	return result, err
+}
+
+// syncLocationYear adds the missing holidays of the location, updates changed
+// ones and removes holidays which are neither public nor custom anymore. The
+// public holiday wins if a custom holiday is on the same day.
+func (w *Holiday) syncLocationYear(result *model.HolidaySyncResult, location model.Location, customHolidays []model.HolidayCustom, year int) error {
+	wanted := model.Holidays{}
+	if location.HasPublicHolidays() {
+		publicHolidays, err := w.getPublicHolidays(model.HolidayState(location.State), year)
+		if err != nil {
+			return err
+		}
+		wanted = append(wanted, publicHolidays...)
+	}
+	wanted = append(wanted, model.GetCustomHolidays(customHolidays, location.ID, year)...)
+
+	existing, err := w.holiday.HolidayFindByLocationIdAndYear(location.ID, year)
+	if err != nil {
+		return err
+	}
+
+	added, updated, removed := model.DiffHolidays(existing, wanted)
+
+	for _, item := range added {
+		item.LocationID = &location.ID
+		err = w.holiday.HolidayInsert(&item)
+		if err != nil {
+			return err
+		}
+		result.Added = append(result.Added, item)
+	}
+
+	for _, item := range updated {
+		err = w.holiday.HolidayUpdate(&item)
+		if err != nil {
+			return err
+		}
+		result.Updated = append(result.Updated, item)
+	}
+
+	for _, item := range removed {
+		err = w.holiday.HolidayDelete(&item)
+		if err != nil {
+			return err
+		}
+		result.Removed = append(result.Removed, item)
+	}
+
+	return nil
+}
+
+// recalculate updates the netto days of the absences and the overtime of the
+// months with changed holidays, closed months stay untouched. Absences are
+// skipped if any of their months is closed.
+func (w *Holiday) recalculate(result *model.HolidaySyncResult) error {
+	months := result.GetChangedMonths()
+	if len(months) == 0 {
+		return nil
+	}
+
+	from := months[0]
+	till := months[len(months)-1].AddDate(0, 1, 0).Add(-time.Second)
+
+	absences, err := w.absence.FindOverlapping(from, till)
+	if err != nil {
+		return err
+	}
+
+	workTimeModels := map[uint]model.UserWorkTimeModels{}
+	for _, absence := range absences {
+		if absence.UserID == nil {
+			continue
+		}
+		userId := *absence.UserID
+
+		closed, err := w.monthClosing.IsPeriodClosed(userId, absence.AbsenceFrom, absence.AbsenceTill)
+		if err != nil {
+			return err
+		}
+		if closed {
+			continue
+		}
+
+		holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(userId, absence.AbsenceFrom, absence.AbsenceTill)
+		if err != nil {
+			return err
+		}
+
+		if _, exists := workTimeModels[userId]; !exists {
+			workTimeModels[userId], err = w.workTimeModel.UserWorkTimeModelFindByUserId(userId)
+			if err != nil {
+				return err
+			}
+		}
+
+		currentNetto := absence.NettoDays
+		absence.CalculateNettoDays(holidays, workTimeModels[userId])
+		if currentNetto != nil && *currentNetto == *absence.NettoDays {
+			continue
+		}
+
+		err = w.absence.Update(nil, &absence)
+		if err != nil {
+			return err
+		}
+		result.RecalculatedAbsences++
+	}
+
+	quotas, err := w.overtime.OvertimeMonthQuotaFindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range quotas {
+		firstOfMonth := time.Date(quota.Year, time.Month(quota.Month), 1, 0, 0, 0, 0, time.UTC)
+		if !slices.ContainsFunc(months, firstOfMonth.Equal) {
+			continue
+		}
+
+		closed, err := w.monthClosing.IsMonthClosed(quota.UserID, quota.Year, quota.Month)
+		if err != nil {
+			return err
+		}
+		if closed {
+			continue
+		}
+
+		_, _, err = w.overtimeWorker.CalculateMonth(quota.UserID, quota.Year, quota.Month)
+		if err != nil {
+			return err
+		}
+		result.RecalculatedOvertimeMonths++
+	}
+
+	return nil
+}
+
+// getPublicHolidays calculates the public holidays of the state, with
+// HOLIDAY_SOURCE=api they are fetched from feiertage-api.de instead.
+func (w *Holiday) getPublicHolidays(state model.HolidayState, year int) (model.Holidays, error) {
+	if w.env.Holiday.Source == HOLIDAY_SOURCE_API {
+		holidays, err := fetchPublicHolidays(state, year)
+		if err == nil {
+			return holidays, nil
+		}
+		log.Printf("Holiday Import: api not available, using calculated holidays: %s", err)
+	}
+
+	return model.CalculateHolidays(year, state)
+}
+
+func fetchPublicHolidays(state model.HolidayState, year int) (model.Holidays, error) {
+	holidays := model.Holidays{}
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://feiertage-api.de/api/?jahr=%d&nur_land=%s", year, state), nil)
+	if err != nil {
+		return holidays, err
+	}
+
+	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return holidays, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return holidays, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	result := make(map[string]model.HolidayImport)
+
+	body, _ := io.ReadAll(response.Body)
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return holidays, err
+	}
+
+	for name, info := range result {
+		date, err := info.GetDate()
+		if err != nil {
+			return holidays, err
+		}
+
+		holidays = append(holidays, model.Holiday{
+			Name:   name,
+			Date:   date,
+			State:  string(state),
+			Source: model.HOLIDAY_SOURCE_IMPORTED,
+		})
+	}
+
+	return holidays, nil
+}
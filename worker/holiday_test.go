@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+)
+
+func TestHolidayRecalculateSkipsClosedMonths(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	absenceRepo := memory.NewAbsence(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+	holidayWorker := NewHoliday(nil, memory.NewHoliday(db), memory.NewLocation(db), absenceRepo, memory.NewWorkTimeModel(db), memory.NewOvertime(db), monthClosingRepo, nil)
+
+	user := model.User{Username: "holiday"}
+	err := userRepo.Insert(&user)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	// april is closed, the absence starting in march ends in april
+	err = monthClosingRepo.MonthClosingInsert(&model.MonthClosing{UserID: &user.ID, Year: 2025, Month: 4})
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	outdated := 99.0
+	testData := []struct {
+		Name          string
+		Absence       model.Absence
+		WantUnchanged bool
+	}{
+		{Name: "open months", Absence: model.Absence{AbsenceFrom: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)}},
+		{Name: "ends in closed month", Absence: model.Absence{AbsenceFrom: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), AbsenceTill: time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)}, WantUnchanged: true},
+	}
+
+	for i := range testData {
+		absence := &testData[i].Absence
+		absence.UserID = &user.ID
+		absence.NettoDays = &outdated
+		err = absenceRepo.Insert(nil, absence)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", testData[i].Name, err)
+		}
+	}
+
+	result := model.NewHolidaySyncResult(2025, 2025)
+	result.Added = model.Holidays{{Name: "Betriebsausflug", Date: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)}}
+
+	err = holidayWorker.recalculate(&result)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	for _, test := range testData {
+		absence, err := absenceRepo.FindByID(test.Absence.ID)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		if unchanged := *absence.NettoDays == outdated; unchanged != test.WantUnchanged {
+			t.Errorf("%s: want unchanged %t, got netto days %.1f", test.Name, test.WantUnchanged, *absence.NettoDays)
+		}
+	}
+
+	if result.RecalculatedAbsences != 1 {
+		t.Errorf("want 1 recalculated absence, got %d", result.RecalculatedAbsences)
+	}
+}
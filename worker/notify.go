@@ -7,12 +7,11 @@ import (
 
 	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/helper"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
 	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
-	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
-	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
 )
 
-func NotifyAbsenceWeek(env *core.Environment, absenceRepo *repository.Absence) error {
+func NotifyAbsenceWeek(env *core.Environment, absenceRepo repository.AbsenceRepository, outbox *Outbox) error {
 	if !env.Notification.Enabled {
 		return nil
 	}
@@ -22,15 +21,14 @@ func NotifyAbsenceWeek(env *core.Environment, absenceRepo *repository.Absence) e
 	weekStart := helper.WeekStart(year, week)
 	weekEnd := weekStart.AddDate(0, 0, 5)
 
-	absences, err := absenceRepo.FindByQuery(true, "absence_till >= ? and absence_till <= ?",
-		weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	absences, err := absenceRepo.FindByAbsenceTillBetween(weekStart, weekEnd)
 
 	if err != nil {
 		return err
 	}
 
 	grouped := make(map[time.Time][]string)
-	var facts []adaptivecard.Fact
+	var facts []model.OutboxTeamsNotificationFact
 
 	for _, absence := range absences {
 		for i := 0; i < 5; i++ {
@@ -49,35 +47,14 @@ func NotifyAbsenceWeek(env *core.Environment, absenceRepo *repository.Absence) e
 	log.Printf("grouped: %#v", grouped)
 
 	for weekday, names := range grouped {
-		facts = append(facts, adaptivecard.Fact{
+		facts = append(facts, model.OutboxTeamsNotificationFact{
 			Title: weekday.Weekday().String(),
 			Value: strings.Join(names, ","),
 		})
 	}
 
-	title := adaptivecard.NewTitleTextBlock("BTC: Abwesenheiten", false)
-	factSet := adaptivecard.NewFactSet()
-	factSet.AddFact(facts...)
-
-	titleContainer := adaptivecard.NewContainer()
-	titleContainer.AddElement(false, title)
-
-	absenceContainer := adaptivecard.NewContainer()
-	absenceContainer.AddElement(false, adaptivecard.Element(factSet))
-
-	card := adaptivecard.NewCard()
-	card.AddContainer(false, titleContainer)
-	card.AddContainer(false, absenceContainer)
-
-	msg := adaptivecard.NewMessage()
-	msg.Attach(card)
-
-	log.Println(msg.PrettyPrint())
-
-	mstClient := goteamsnotify.NewTeamsClient()
-	if err := mstClient.Send(env.Notification.WebhookUrl, msg); err != nil {
-		return err
-	}
-
-	return nil
+	return outbox.EnqueueTeamsNotification(nil, model.OutboxTeamsNotificationPayload{
+		Title: "Abwesenheiten",
+		Facts: facts,
+	})
 }
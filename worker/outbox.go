@@ -0,0 +1,331 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/microsoft"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
+	"gorm.io/gorm"
+)
+
+const (
+	OUTBOX_DISPATCH_INTERVAL  = 30 * time.Second
+	OUTBOX_DISPATCH_BATCH     = 50
+	OUTBOX_DELIVERED_RETAIN   = 30 * 24 * time.Hour
+	OUTBOX_WEBHOOK_TIMEOUT    = 10 * time.Second
+	OUTBOX_TEAMS_TITLE_PREFIX = "BTC: "
+)
+
+var ErrOutboxJobTypeUnknown = errors.New("unknown outbox job type")
+
+type OutboxWebhookRequest struct {
+	Event     string
+	Data      json.RawMessage
+	Timestamp time.Time
+}
+
+type OutboxJobHandler func(job *model.OutboxJob) error
+
+// Outbox delivers the side effects of requests in the background. Failed jobs
+// are retried with a backoff and dead lettered after too many attempts.
+type Outbox struct {
+	env      *core.Environment
+	outbox   repository.OutboxRepository
+	handlers map[model.OutboxJobType]OutboxJobHandler
+	trigger  chan bool
+}
+
+func NewOutbox(env *core.Environment, outbox repository.OutboxRepository, calendarSync *CalendarSync) *Outbox {
+	w := &Outbox{
+		env:      env,
+		outbox:   outbox,
+		handlers: map[model.OutboxJobType]OutboxJobHandler{},
+		trigger:  make(chan bool, 1),
+	}
+
+	w.handlers[model.OUTBOX_JOB_TYPE_CALENDAR_ABSENCE] = func(job *model.OutboxJob) error {
+		var payload model.OutboxCalendarAbsencePayload
+		err := job.DecodePayload(&payload)
+		if err != nil {
+			return err
+		}
+		return calendarSync.SyncAbsence(payload.AbsenceID)
+	}
+	w.handlers[model.OUTBOX_JOB_TYPE_CALENDAR_EXTERNAL_WORK] = func(job *model.OutboxJob) error {
+		var payload model.OutboxCalendarExternalWorkPayload
+		err := job.DecodePayload(&payload)
+		if err != nil {
+			return err
+		}
+		return calendarSync.SyncExternalWork(payload.ExternalWorkID)
+	}
+	w.handlers[model.OUTBOX_JOB_TYPE_CALENDAR_DELETE] = func(job *model.OutboxJob) error {
+		var payload model.OutboxCalendarDeletePayload
+		err := job.DecodePayload(&payload)
+		if err != nil {
+			return err
+		}
+		// jobs enqueued before the providers were introduced have no provider
+		if payload.Provider == "" {
+			payload.Provider = model.EXTERNAL_EVENT_PROVIDER_MICROSOFT
+		}
+		return calendarSync.DeleteEvent(payload.Provider, payload.Username, payload.ExternalEventID)
+	}
+	w.handlers[model.OUTBOX_JOB_TYPE_TEAMS_NOTIFICATION] = w.sendTeamsNotification
+	w.handlers[model.OUTBOX_JOB_TYPE_USER_NOTIFICATION] = w.sendUserNotification
+	w.handlers[model.OUTBOX_JOB_TYPE_WEBHOOK] = w.sendWebhook
+
+	return w
+}
+
+func (w *Outbox) Run() {
+	w.dispatchLogged()
+
+	ticker := time.NewTicker(OUTBOX_DISPATCH_INTERVAL)
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.trigger:
+		}
+
+		w.dispatchLogged()
+	}
+}
+
+func (w *Outbox) dispatchLogged() {
+	err := w.Dispatch()
+	if err != nil {
+		log.Printf("Outbox: %s", err)
+	}
+}
+
+// Transaction runs fn in a database transaction and wakes up the dispatcher
+// after the commit. Jobs enqueued with the tx are only stored if the changes
+// they belong to are stored.
+func (w *Outbox) Transaction(fn func(tx *gorm.DB) error) error {
+	err := w.outbox.Transaction(fn)
+	if err != nil {
+		return err
+	}
+
+	w.wakeUp()
+	return nil
+}
+
+// Enqueue stores the job in the transaction tx, without one it is stored
+// right away and the dispatcher is woken up.
+func (w *Outbox) Enqueue(tx *gorm.DB, jobType model.OutboxJobType, payload any) error {
+	job, err := model.NewOutboxJob(jobType, payload)
+	if err != nil {
+		return err
+	}
+
+	err = w.outbox.OutboxJobInsert(tx, &job)
+	if err != nil {
+		return err
+	}
+
+	if tx == nil {
+		w.wakeUp()
+	}
+	return nil
+}
+
+// EnqueueTeamsNotification is a no-op when no Teams webhook is configured.
+func (w *Outbox) EnqueueTeamsNotification(tx *gorm.DB, payload model.OutboxTeamsNotificationPayload) error {
+	if !w.env.Notification.Enabled {
+		return nil
+	}
+
+	return w.Enqueue(tx, model.OUTBOX_JOB_TYPE_TEAMS_NOTIFICATION, payload)
+}
+
+// EnqueueUserNotification mails the user through Microsoft Graph, it is a
+// no-op when Microsoft is not connected.
+func (w *Outbox) EnqueueUserNotification(tx *gorm.DB, payload model.OutboxUserNotificationPayload) error {
+	if !microsoft.IsMicrosoftConnected() {
+		return nil
+	}
+
+	return w.Enqueue(tx, model.OUTBOX_JOB_TYPE_USER_NOTIFICATION, payload)
+}
+
+// EnqueueWebhook posts the event to the configured event webhook, it is a
+// no-op when none is configured.
+func (w *Outbox) EnqueueWebhook(tx *gorm.DB, event string, data any) error {
+	if w.env.Notification.EventWebhookUrl == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return w.Enqueue(tx, model.OUTBOX_JOB_TYPE_WEBHOOK, model.OutboxWebhookPayload{
+		Url:   w.env.Notification.EventWebhookUrl,
+		Event: event,
+		Data:  body,
+	})
+}
+
+func (w *Outbox) Replay(job *model.OutboxJob) error {
+	job.Replay(time.Now())
+
+	err := w.outbox.OutboxJobUpdate(job)
+	if err != nil {
+		return err
+	}
+
+	w.wakeUp()
+	return nil
+}
+
+func (w *Outbox) wakeUp() {
+	select {
+	case w.trigger <- true:
+	default:
+	}
+}
+
+func (w *Outbox) Dispatch() error {
+	now := time.Now()
+
+	jobs, err := w.outbox.OutboxJobFindDue(now, OUTBOX_DISPATCH_BATCH)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		err = w.deliver(&job)
+		if errors.Is(err, ErrOutboxJobTypeUnknown) {
+			job.DeadLetter(err)
+			log.Printf("Outbox: job %d (%s) is dead: %s", job.ID, job.Type, err)
+		} else if err != nil {
+			job.Failed(err, time.Now())
+			if job.IsDead() {
+				log.Printf("Outbox: job %d (%s) is dead: %s", job.ID, job.Type, err)
+			}
+		} else {
+			job.Delivered(time.Now())
+		}
+
+		err = w.outbox.OutboxJobUpdate(&job)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.outbox.OutboxJobDeleteDeliveredBefore(now.Add(-OUTBOX_DELIVERED_RETAIN))
+}
+
+func (w *Outbox) deliver(job *model.OutboxJob) error {
+	handler, exists := w.handlers[job.Type]
+	if !exists {
+		return ErrOutboxJobTypeUnknown
+	}
+
+	return handler(job)
+}
+
+func (w *Outbox) sendTeamsNotification(job *model.OutboxJob) error {
+	var payload model.OutboxTeamsNotificationPayload
+	err := job.DecodePayload(&payload)
+	if err != nil {
+		return err
+	}
+
+	if !w.env.Notification.Enabled {
+		return errors.New("teams notification is not configured")
+	}
+
+	title := adaptivecard.NewTitleTextBlock(OUTBOX_TEAMS_TITLE_PREFIX+payload.Title, false)
+	titleContainer := adaptivecard.NewContainer()
+	titleContainer.AddElement(false, title)
+
+	factSet := adaptivecard.NewFactSet()
+	for _, fact := range payload.Facts {
+		factSet.AddFact(adaptivecard.Fact{
+			Title: fact.Title,
+			Value: fact.Value,
+		})
+	}
+
+	contentContainer := adaptivecard.NewContainer()
+	if len(payload.Facts) > 0 {
+		contentContainer.AddElement(false, adaptivecard.Element(factSet))
+	}
+	if payload.Text != "" {
+		contentContainer.AddElement(false, adaptivecard.NewTextBlock(payload.Text, true))
+	}
+
+	card := adaptivecard.NewCard()
+	card.AddContainer(false, titleContainer)
+	card.AddContainer(false, contentContainer)
+
+	msg := adaptivecard.NewMessage()
+	msg.Attach(card)
+
+	mstClient := goteamsnotify.NewTeamsClient()
+	return mstClient.Send(w.env.Notification.WebhookUrl, msg)
+}
+
+func (w *Outbox) sendUserNotification(job *model.OutboxJob) error {
+	var payload model.OutboxUserNotificationPayload
+	err := job.DecodePayload(&payload)
+	if err != nil {
+		return err
+	}
+
+	if !microsoft.IsMicrosoftConnected() {
+		return errors.New("microsoft is not connected")
+	}
+
+	return microsoft.SendMail(payload.Username, OUTBOX_TEAMS_TITLE_PREFIX+payload.Subject, payload.Text)
+}
+
+func (w *Outbox) sendWebhook(job *model.OutboxJob) error {
+	var payload model.OutboxWebhookPayload
+	err := job.DecodePayload(&payload)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(OutboxWebhookRequest{
+		Event:     payload.Event,
+		Data:      payload.Data,
+		Timestamp: job.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, payload.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	client := &http.Client{Timeout: OUTBOX_WEBHOOK_TIMEOUT}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
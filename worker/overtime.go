@@ -11,16 +11,18 @@ import (
 
 type Overtime struct {
 	env             *core.Environment
-	holiday         *repository.Holiday
-	timestamp       *repository.Timestamp
-	externalWork    *repository.ExternalWork
-	overtime        *repository.Overtime
-	user            *repository.User
-	absence         *repository.Absence
+	holiday         repository.HolidayRepository
+	timestamp       repository.TimestampRepository
+	externalWork    repository.ExternalWorkRepository
+	overtime        repository.OvertimeRepository
+	user            repository.UserRepository
+	absence         repository.AbsenceRepository
+	workTimeModel   repository.WorkTimeModelRepository
+	monthClosing    repository.MonthClosingRepository
 	timestampWorker *Timestamp
 }
 
-func NewOvertime(env *core.Environment, user *repository.User, externalWork *repository.ExternalWork, timestamp *repository.Timestamp, holiday *repository.Holiday, overtime *repository.Overtime, timestampWorker *Timestamp, absence *repository.Absence) *Overtime {
+func NewOvertime(env *core.Environment, user repository.UserRepository, externalWork repository.ExternalWorkRepository, timestamp repository.TimestampRepository, holiday repository.HolidayRepository, overtime repository.OvertimeRepository, timestampWorker *Timestamp, absence repository.AbsenceRepository, workTimeModel repository.WorkTimeModelRepository, monthClosing repository.MonthClosingRepository) *Overtime {
 	return &Overtime{
 		env:             env,
 		holiday:         holiday,
@@ -30,10 +32,29 @@ func NewOvertime(env *core.Environment, user *repository.User, externalWork *rep
 		overtime:        overtime,
 		timestampWorker: timestampWorker,
 		absence:         absence,
+		workTimeModel:   workTimeModel,
+		monthClosing:    monthClosing,
 	}
 }
 
 func (w *Overtime) CalculateMonth(userID uint, year int, month int) (model.OvertimeMonthQuota, bool, error) {
+	closed, err := w.monthClosing.IsMonthClosed(userID, year, month)
+	if err != nil {
+		return model.OvertimeMonthQuota{}, false, err
+	}
+
+	// the quota of a closed month is frozen
+	if closed {
+		quota, err := w.overtime.OvertimeMonthQuotaFindByUserIDAndYearAndMonth(userID, year, month)
+		if err == nil {
+			return quota, false, nil
+		}
+
+		if err != repository.ErrOvertimeMonthQuotaNotFound {
+			return model.OvertimeMonthQuota{}, false, err
+		}
+	}
+
 	hours := 0.0
 	result := model.OvertimeMonthQuota{
 		UserID:  userID,
@@ -45,7 +66,7 @@ func (w *Overtime) CalculateMonth(userID uint, year int, month int) (model.Overt
 
 	firstOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Now().Location())
 	lastOfMonth := firstOfMonth.AddDate(0, 1, 0).Add(-1 * time.Second)
-	holidays, err := w.holiday.HolidayFindByDateRange(firstOfMonth, lastOfMonth)
+	holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(userID, firstOfMonth, lastOfMonth)
 	if err != nil {
 		return model.OvertimeMonthQuota{}, false, err
 	}
@@ -63,8 +84,13 @@ func (w *Overtime) CalculateMonth(userID uint, year int, month int) (model.Overt
 		return model.OvertimeMonthQuota{}, false, err
 	}
 
+	workTimeModels, err := w.workTimeModel.UserWorkTimeModelFindByUserId(userID)
+	if err != nil {
+		return model.OvertimeMonthQuota{}, false, err
+	}
+
 	for _, externalWork := range externalWorks {
-		calculated := externalWork.Calculate(holidays)
+		calculated := externalWork.Calculate(holidays, workTimeModels)
 		result.InsertSummary("external_work", &externalWork.ID, calculated.TotalOvertimeHours, 1.0)
 	}
 
@@ -73,13 +99,12 @@ func (w *Overtime) CalculateMonth(userID uint, year int, month int) (model.Overt
 		return model.OvertimeMonthQuota{}, false, err
 	}
 	for _, absence := range absences {
-		switch absence.AbsenceReason.OvertimeImpact {
-		case model.ABESENCE_REASON_OVERTIME_IMPACT_DURATION:
-			duration := absence.AbsenceTill.Sub(absence.AbsenceFrom).Hours()
-
-			result.InsertSummary("absence", &absence.ID, duration, 1.0)
-			break
+		if absence.AbsenceReason.OvertimeImpact == model.ABESENCE_REASON_OVERTIME_IMPACT_NONE || absence.AbsenceReason.OvertimeImpact == "" {
+			continue
 		}
+
+		impact := absence.CalculateOvertimeImpact(firstOfMonth, lastOfMonth, holidays, workTimeModels)
+		result.InsertSummary("absence", &absence.ID, impact, 1.0)
 	}
 
 	result.Calculate()
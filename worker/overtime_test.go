@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository/memory"
+)
+
+func TestOvertimeCalculateMonth(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	holidayRepo := memory.NewHoliday(db)
+	absenceRepo := memory.NewAbsence(db)
+	externalWorkRepo := memory.NewExternalWork(db)
+	workTimeModelRepo := memory.NewWorkTimeModel(db)
+	settingsRepo := memory.NewSettings(db)
+	teamRepo := memory.NewTeam(db)
+	overtimeRepo := memory.NewOvertime(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+
+	timestampWorker := NewTimestamp(nil, userRepo, externalWorkRepo, timestampRepo, holidayRepo, absenceRepo, workTimeModelRepo, settingsRepo, teamRepo)
+	overtimeWorker := NewOvertime(nil, userRepo, externalWorkRepo, timestampRepo, holidayRepo, overtimeRepo, timestampWorker, absenceRepo, workTimeModelRepo, monthClosingRepo)
+
+	user := model.User{Username: "worker"}
+	workTimeModel := model.DefaultWorkTimeModel()
+	settings, err := settingsRepo.SettingsFind()
+	if err == nil {
+		err = userRepo.Insert(&user)
+	}
+	if err == nil {
+		err = workTimeModelRepo.WorkTimeModelInsert(&workTimeModel)
+	}
+	if err == nil {
+		err = workTimeModelRepo.UserWorkTimeModelInsert(&model.UserWorkTimeModel{
+			UserID:          user.ID,
+			WorkTimeModelID: workTimeModel.ID,
+			ValidFrom:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+		})
+	}
+	for _, breakRule := range model.DefaultBreakRules() {
+		if err == nil {
+			breakRule.SettingsID = settings.ID
+			err = settingsRepo.SettingsBreakRuleInsert(&breakRule)
+		}
+	}
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	testData := []struct {
+		Name      string
+		Day       int
+		Hours     int
+		Close     bool
+		WantHours float64
+		WantNew   bool
+	}{
+		// ten hours on monday with 45 minutes break deducted
+		{Name: "first calculation", Day: 4, Hours: 10, WantHours: 1.25, WantNew: true},
+		// seven hours on tuesday with 30 minutes break deducted
+		{Name: "recalculation", Day: 5, Hours: 7, WantHours: -0.25},
+		{Name: "closed month", Day: 6, Hours: 10, Close: true, WantHours: -0.25},
+	}
+
+	for _, test := range testData {
+		coming := time.Date(2024, 3, test.Day, 8, 0, 0, 0, time.Local)
+		err := timestampRepo.Insert(&model.Timestamp{
+			UserID:          user.ID,
+			ComingTimestamp: coming,
+			GoingTimestamp:  coming.Add(time.Duration(test.Hours) * time.Hour),
+		})
+		if err == nil && test.Close {
+			err = monthClosingRepo.MonthClosingInsert(&model.MonthClosing{Year: 2024, Month: 3})
+		}
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		quota, isNew, err := overtimeWorker.CalculateMonth(user.ID, 2024, 3)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+		if quota.Hours == nil || *quota.Hours != test.WantHours {
+			t.Errorf("%s: want %.2f hours, got %v", test.Name, test.WantHours, quota.Hours)
+		}
+		if isNew != test.WantNew {
+			t.Errorf("%s: want new %t, got %t", test.Name, test.WantNew, isNew)
+		}
+
+		quotas, err := overtimeRepo.OvertimeMonthQuotaFindByUserID(user.ID)
+		if err != nil || len(quotas) != 1 || *quotas[0].Hours != test.WantHours {
+			t.Errorf("%s: want one stored quota with %.2f hours, got %v (%v)", test.Name, test.WantHours, quotas, err)
+		}
+	}
+}
+
+func TestOvertimeCalculateMonthPartialDayAbsence(t *testing.T) {
+	db := memory.NewDatabase()
+	userRepo := memory.NewUser(db)
+	timestampRepo := memory.NewTimestamp(db)
+	holidayRepo := memory.NewHoliday(db)
+	absenceRepo := memory.NewAbsence(db)
+	externalWorkRepo := memory.NewExternalWork(db)
+	workTimeModelRepo := memory.NewWorkTimeModel(db)
+	settingsRepo := memory.NewSettings(db)
+	teamRepo := memory.NewTeam(db)
+	overtimeRepo := memory.NewOvertime(db)
+	monthClosingRepo := memory.NewMonthClosing(db)
+
+	timestampWorker := NewTimestamp(nil, userRepo, externalWorkRepo, timestampRepo, holidayRepo, absenceRepo, workTimeModelRepo, settingsRepo, teamRepo)
+	overtimeWorker := NewOvertime(nil, userRepo, externalWorkRepo, timestampRepo, holidayRepo, overtimeRepo, timestampWorker, absenceRepo, workTimeModelRepo, monthClosingRepo)
+
+	reason := model.AbsenceReason{Description: "Dienstreise", OvertimeImpact: model.ABESENCE_REASON_OVERTIME_IMPACT_DURATION}
+	err := absenceRepo.InsertAbsenceReason(&reason)
+	if err != nil {
+		t.Fatalf("setup: want no error, got %s", err)
+	}
+
+	accepted := model.SIGNED_STATUS_ACCEPTED
+	declined := model.SIGNED_STATUS_DECLINED
+
+	testData := []struct {
+		Name         string
+		SignedStatus *model.AbsenceSignedStatus
+		WantHours    float64
+	}{
+		// four hours worked on monday, the morning off covers the other four
+		{Name: "accepted", SignedStatus: &accepted, WantHours: 0},
+		{Name: "pending", WantHours: -4},
+		{Name: "declined", SignedStatus: &declined, WantHours: -4},
+	}
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	for _, test := range testData {
+		user := model.User{Username: test.Name}
+		err := userRepo.Insert(&user)
+		if err == nil {
+			coming := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+			err = timestampRepo.Insert(&model.Timestamp{
+				UserID:          user.ID,
+				ComingTimestamp: coming,
+				GoingTimestamp:  coming.Add(4 * time.Hour),
+			})
+		}
+		if err == nil {
+			err = absenceRepo.Insert(nil, &model.Absence{
+				UserID:        &user.ID,
+				AbsenceFrom:   monday,
+				AbsenceTill:   monday,
+				AbsenceReason: reason,
+				DayPart:       model.ABSENCE_DAY_PART_MORNING,
+				SignedStatus:  test.SignedStatus,
+			})
+		}
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+
+		quota, _, err := overtimeWorker.CalculateMonth(user.ID, 2024, 3)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %s", test.Name, err)
+		}
+		if quota.Hours == nil || *quota.Hours != test.WantHours {
+			t.Errorf("%s: want %.2f hours, got %v", test.Name, test.WantHours, quota.Hours)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"math"
 	"slices"
 	"time"
 
@@ -10,22 +11,28 @@ import (
 )
 
 type Timestamp struct {
-	env          *core.Environment
-	holiday      *repository.Holiday
-	timestamp    *repository.Timestamp
-	externalWork *repository.ExternalWork
-	absence      *repository.Absence
-	user         *repository.User
+	env           *core.Environment
+	holiday       repository.HolidayRepository
+	timestamp     repository.TimestampRepository
+	externalWork  repository.ExternalWorkRepository
+	absence       repository.AbsenceRepository
+	user          repository.UserRepository
+	workTimeModel repository.WorkTimeModelRepository
+	settings      repository.SettingsRepository
+	team          repository.TeamRepository
 }
 
-func NewTimestamp(env *core.Environment, user *repository.User, externalWork *repository.ExternalWork, timestamp *repository.Timestamp, holiday *repository.Holiday, absence *repository.Absence) *Timestamp {
+func NewTimestamp(env *core.Environment, user repository.UserRepository, externalWork repository.ExternalWorkRepository, timestamp repository.TimestampRepository, holiday repository.HolidayRepository, absence repository.AbsenceRepository, workTimeModel repository.WorkTimeModelRepository, settings repository.SettingsRepository, team repository.TeamRepository) *Timestamp {
 	return &Timestamp{
-		env:          env,
-		holiday:      holiday,
-		timestamp:    timestamp,
-		externalWork: externalWork,
-		user:         user,
-		absence:      absence,
+		env:           env,
+		holiday:       holiday,
+		timestamp:     timestamp,
+		externalWork:  externalWork,
+		user:          user,
+		absence:       absence,
+		workTimeModel: workTimeModel,
+		settings:      settings,
+		team:          team,
 	}
 }
 
@@ -47,11 +54,25 @@ func (w *Timestamp) CalculateMonth(userID uint, year int, month int) (model.Time
 		return result, err
 	}
 
-	holidays, err := w.holiday.HolidayFindByDateRange(firstOfMonth, lastOfMonth)
+	holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(userID, firstOfMonth, lastOfMonth)
+	if err != nil {
+		return result, err
+	}
+	workTimeModels, err := w.workTimeModel.UserWorkTimeModelFindByUserId(userID)
+	if err != nil {
+		return result, err
+	}
+	neededHours := model.GetNeededHoursForMonth(holidays, workTimeModels, year, month)
+
+	absences, err := w.absence.AbsenceFindByUserIDAndBetweenDates(userID, firstOfMonth, lastOfMonth)
+	if err != nil {
+		return result, err
+	}
+
+	breakRules, err := w.GetBreakRulesForUser(userID)
 	if err != nil {
 		return result, err
 	}
-	neededHours := model.GetNeededHoursForMonth(holidays, year, month)
 
 	grouped := make(map[time.Time]model.TimestampGroup)
 
@@ -72,20 +93,24 @@ func (w *Timestamp) CalculateMonth(userID uint, year int, month int) (model.Time
 		}
 		group.Timestamps = append(grouped[timestamp_date].Timestamps, timestamp)
 
-		workingHours, subtractedHours := timestamp.CalculateWorkingHours()
-		group.WorkingHours += workingHours
-		group.SubtractedHours += subtractedHours
-
-		workTimeModel := model.DefaultWorkTimeModel()
-
-		neededHours := workTimeModel.GetWorkingHoursForDay(timestamp_date, holidays)
-
-		group.OvertimeHours = group.WorkingHours - neededHours
-
 		grouped[timestamp_date] = group
 	}
 
 	for _, value := range grouped {
+		value.Calculate(breakRules)
+		// accepted partial day absences lower the planned hours of the day,
+		// their duration isn't credited again as overtime impact
+		plannedHours := workTimeModels.GetWorkingHoursForDay(value.Date, holidays)
+		for _, absence := range absences {
+			if absence.IsFullDay() || absence.SignedStatus == nil || *absence.SignedStatus != model.SIGNED_STATUS_ACCEPTED {
+				continue
+			}
+
+			plannedHours -= absence.GetAbsentHoursForDay(value.Date, holidays, workTimeModels)
+		}
+
+		value.OvertimeHours = value.WorkingHours - math.Max(plannedHours, 0)
+
 		result.TimestampGroups = append(result.TimestampGroups, value)
 		result.OvertimeHours += value.OvertimeHours
 	}
@@ -114,6 +139,20 @@ func (w *Timestamp) CalculateMonth(userID uint, year int, month int) (model.Time
 	return result, nil
 }
 
+func (w *Timestamp) GetBreakRulesForUser(userID uint) (model.BreakRules, error) {
+	settings, err := w.settings.SettingsFind()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := w.team.TeamsFindByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings.GetBreakRulesForTeams(teams), nil
+}
+
 func (w *Timestamp) MissingDays(userID uint) ([]time.Time, error) {
 	user, err := w.user.FindByID(userID)
 	if err != nil {
@@ -140,7 +179,7 @@ func (w *Timestamp) MissingDays(userID uint) ([]time.Time, error) {
 }
 
 func (w *Timestamp) MissingDaysInMonth(userID uint, year int, month int) ([]time.Time, error) {
-	holidays, err := w.holiday.HolidayFindByYear(year)
+	holidays, err := w.holiday.HolidayFindByUserIdAndYear(userID, year)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +202,11 @@ func (w *Timestamp) MissingDaysInMonth(userID uint, year int, month int) ([]time
 		return nil, err
 	}
 
+	workTimeModels, err := w.workTimeModel.UserWorkTimeModelFindByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	missingDays := []time.Time{}
 	currentDay := firstOfMonth.AddDate(0, 0, -1)
 	for currentDay.Before(lastOfMonth) {
@@ -171,16 +215,13 @@ func (w *Timestamp) MissingDaysInMonth(userID uint, year int, month int) ([]time
 			break
 		}
 
-		if currentDay.Weekday() == time.Sunday || currentDay.Weekday() == time.Saturday {
-			continue
-		}
-
-		if holidays.Contains(currentDay) {
+		if !workTimeModels.IsWorkingDay(currentDay, holidays) {
 			continue
 		}
 
+		// partial day absences still need a timestamp for the remaining hours
 		if slices.ContainsFunc(absences, func(n model.Absence) bool {
-			return n.IsDateInAbsence(currentDay)
+			return n.IsFullDay() && n.IsDateInAbsence(currentDay)
 		}) {
 			continue
 		}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/BeeTimeClock/BeeTimeClock-Server/core"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/model"
+	"github.com/BeeTimeClock/BeeTimeClock-Server/repository"
+)
+
+type Vacation struct {
+	env           *core.Environment
+	absence       repository.AbsenceRepository
+	vacation      repository.VacationRepository
+	settings      repository.SettingsRepository
+	holiday       repository.HolidayRepository
+	workTimeModel repository.WorkTimeModelRepository
+}
+
+func NewVacation(env *core.Environment, absence repository.AbsenceRepository, vacation repository.VacationRepository, settings repository.SettingsRepository, holiday repository.HolidayRepository, workTimeModel repository.WorkTimeModelRepository) *Vacation {
+	return &Vacation{
+		env:           env,
+		absence:       absence,
+		vacation:      vacation,
+		settings:      settings,
+		holiday:       holiday,
+		workTimeModel: workTimeModel,
+	}
+}
+
+// CalculateLedger returns the vacation account of the user till the given year.
+func (w *Vacation) CalculateLedger(user model.User, tillYear int) (model.VacationLedger, error) {
+	absences, err := w.absence.FindVacationByUserID(user.ID)
+	if err != nil {
+		return model.VacationLedger{}, err
+	}
+
+	return w.calculateLedger(user, absences, tillYear)
+}
+
+// GetRemainingForAbsence returns the vacation days left for the not yet stored
+// absence. Every day is booked on its own date, so days after the carry over
+// expiry or in the next year only use the days valid then.
+func (w *Vacation) GetRemainingForAbsence(user model.User, absence model.Absence) (float64, error) {
+	if absence.NettoDays == nil {
+		return 0, nil
+	}
+
+	absences, err := w.absence.FindVacationByUserID(user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// the new absence is identified by the id 0 in the bookings
+	absence.ID = 0
+	absences = append(absences, absence)
+
+	ledger, err := w.calculateLedger(user, absences, absence.AbsenceTill.Year())
+	if err != nil {
+		return 0, err
+	}
+
+	return *absence.NettoDays - ledger.GetOverdrawnDays(0), nil
+}
+
+func (w *Vacation) calculateLedger(user model.User, absences []model.Absence, tillYear int) (model.VacationLedger, error) {
+	settings, err := w.settings.SettingsFind()
+	if err != nil {
+		return model.VacationLedger{}, err
+	}
+
+	fromYear := tillYear
+	vacationAbsences := []model.Absence{}
+	for _, absence := range absences {
+		if absence.DeductsVacation() {
+			vacationAbsences = append(vacationAbsences, absence)
+			fromYear = min(fromYear, absence.AbsenceFrom.Year())
+		}
+	}
+
+	adjustments, err := w.vacation.VacationAdjustmentFindByUserId(user.ID)
+	if err != nil {
+		return model.VacationLedger{}, err
+	}
+
+	holidays, err := w.holiday.HolidayFindByUserIdAndDateRange(user.ID,
+		time.Date(fromYear, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(tillYear, time.December, 31, 23, 59, 59, 0, time.UTC))
+	if err != nil {
+		return model.VacationLedger{}, err
+	}
+
+	workTimeModels, err := w.workTimeModel.UserWorkTimeModelFindByUserId(user.ID)
+	if err != nil {
+		return model.VacationLedger{}, err
+	}
+
+	input := model.VacationLedgerInput{
+		User:                 user,
+		Absences:             vacationAbsences,
+		Adjustments:          adjustments,
+		Holidays:             holidays,
+		WorkTimeModels:       workTimeModels,
+		CarryOverExpiryMonth: settings.VacationCarryOverExpiryMonth,
+		CarryOverExpiryDay:   settings.VacationCarryOverExpiryDay,
+		Now:                  time.Now(),
+	}
+
+	return model.CalculateVacationLedger(input, tillYear), nil
+}